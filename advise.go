@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// AdviseArgs defines the arguments of the advise subcommand
+type AdviseArgs struct {
+	Top      int `arg:"--top" default:"10" help:"Number of frequently corrected terms to show"`
+	MinCount int `arg:"--min-count" default:"2" help:"Only suggest terms corrected at least this many times"`
+}
+
+// correctedTerm is a term humans repeatedly corrected the transcripts to
+type correctedTerm struct {
+	Term  string
+	From  []string
+	Count int
+}
+
+// loadCorrections reads the corrections log, keeping only the latest record per file
+func loadCorrections(path string) ([]CorrectionRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	latest := map[string]int{}
+	var records []CorrectionRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record CorrectionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid record on line %d of %s: %w", line, path, err)
+		}
+		if i, ok := latest[record.File]; ok {
+			records[i] = record
+		} else {
+			latest[record.File] = len(records)
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// frequentCorrections counts how often each term was written in by a human,
+// most frequent first, keeping the terms corrected at least minCount times
+func frequentCorrections(records []CorrectionRecord, minCount int) []correctedTerm {
+	byTerm := map[string]*correctedTerm{}
+	var terms []*correctedTerm
+	for _, record := range records {
+		for _, r := range record.Replacements {
+			key := strings.ToLower(r.To)
+			term, ok := byTerm[key]
+			if !ok {
+				term = &correctedTerm{Term: r.To}
+				byTerm[key] = term
+				terms = append(terms, term)
+			}
+			term.Count++
+			if !containsFold(term.From, r.From) {
+				term.From = append(term.From, r.From)
+			}
+		}
+	}
+
+	var frequent []correctedTerm
+	for _, term := range terms {
+		if term.Count >= minCount {
+			frequent = append(frequent, *term)
+		}
+	}
+	sort.SliceStable(frequent, func(i, j int) bool { return frequent[i].Count > frequent[j].Count })
+	return frequent
+}
+
+// containsFold reports whether values contains s, ignoring case
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestPrompt joins the corrected terms into a prompt that fits the prompt limit
+func suggestPrompt(terms []correctedTerm) string {
+	prompt := ""
+	for _, term := range terms {
+		candidate := term.Term
+		if prompt != "" {
+			candidate = prompt + ", " + term.Term
+		}
+		if estimateTokens(candidate) > maxPromptTokens {
+			break
+		}
+		prompt = candidate
+	}
+	return prompt
+}
+
+// runAdvise reports the recorded correction rates and suggests prompt terms
+// for the words humans correct most often
+func runAdvise(argv []string) {
+	var args AdviseArgs
+	parser := parseSubcommand("advise", &args, argv)
+	if args.Top < 1 {
+		parser.Fail(tr("--top must be at least 1"))
+	}
+
+	logPath, err := correctionsLogPath()
+	if err != nil {
+		uiPrintf(tr("❌ Error reading corrections log: %v\n"), err)
+		os.Exit(1)
+	}
+	records, err := loadCorrections(logPath)
+	if os.IsNotExist(err) || (err == nil && len(records) == 0) {
+		uiPrint(tr("No corrections recorded yet. Import corrected transcripts with: pindar import-corrections <edited> --original <original> --record\n"))
+		return
+	}
+	if err != nil {
+		uiPrintf(tr("❌ Error reading corrections log: %v\n"), err)
+		os.Exit(1)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return correctionRate(records[i].Edits, records[i].Words) > correctionRate(records[j].Edits, records[j].Words)
+	})
+	uiPrintln(tr("\n  Correction rates:"))
+	totalWords, totalEdits := 0, 0
+	for _, record := range records {
+		totalWords += record.Words
+		totalEdits += record.Edits
+		uiPrintf(tr("   %5.1f%%  %s (%d of %d words)\n"), correctionRate(record.Edits, record.Words), record.File, record.Edits, record.Words)
+	}
+	uiPrintf(tr("   %5.1f%%  overall, %d files\n"), correctionRate(totalEdits, totalWords), len(records))
+
+	terms := frequentCorrections(records, args.MinCount)
+	if len(terms) == 0 {
+		uiPrintf(tr("\n No term was corrected %d or more times.\n"), args.MinCount)
+		return
+	}
+	terms = terms[:min(args.Top, len(terms))]
+	uiPrintln(tr("\n  Frequently corrected:"))
+	for _, term := range terms {
+		uiPrintf(tr("   %s (%d×, transcribed as %s)\n"), term.Term, term.Count, strings.Join(term.From, ", "))
+	}
+	uiPrintf(tr("\n💡 Add these terms to the prompt so the model spells them right:\n   --prompt %q\n"), suggestPrompt(terms))
+}
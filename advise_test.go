@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadCorrectionsKeepsLatestPerFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corrections.jsonl")
+	log := `{"file":"/a.srt","words":100,"edits":10}
+{"file":"/b.srt","words":50,"edits":1}
+
+{"file":"/a.srt","words":100,"edits":4}
+`
+	if err := os.WriteFile(path, []byte(log), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := loadCorrections(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 2 || records[0].File != "/a.srt" || records[0].Edits != 4 {
+		t.Errorf("Expected the latest record of /a.srt first, got %+v", records)
+	}
+}
+
+func TestFrequentCorrections(t *testing.T) {
+	records := []CorrectionRecord{
+		{Replacements: []Replacement{{From: "shiva", To: "Siobhán"}, {From: "cube control", To: "kubectl"}}},
+		{Replacements: []Replacement{{From: "Shiva", To: "Siobhán"}, {From: "she von", To: "siobhán"}}},
+		{Replacements: []Replacement{{From: "cube control", To: "kubectl"}, {From: "there", To: "their"}}},
+	}
+
+	expected := []correctedTerm{
+		{Term: "Siobhán", From: []string{"shiva", "she von"}, Count: 3},
+		{Term: "kubectl", From: []string{"cube control"}, Count: 2},
+	}
+	result := frequentCorrections(records, 2)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+	if prompt := suggestPrompt(result); prompt != "Siobhán, kubectl" {
+		t.Errorf("Expected %q, got %q", "Siobhán, kubectl", prompt)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// ankiMerge joins transcript segments into the sentences that become cards
+var ankiMerge = MergeOptions{Sentences: true, MaxDuration: 20}
+
+// ankiPadding is added before and after each snippet so no word is clipped
+const ankiPadding = 0.25
+
+// ankiCard is a flashcard: an audio snippet on the front, its text on the back
+type ankiCard struct {
+	Audio       string
+	Text        string
+	Translation string
+}
+
+// ankiField escapes text for a field of an Anki import file with HTML enabled
+func ankiField(text string) string {
+	return html.EscapeString(strings.Join(strings.Fields(text), " "))
+}
+
+// renderAnkiNotes produces a tab-separated Anki import file. The header lines
+// tell Anki the separator, note type and deck, so no import settings are needed.
+func renderAnkiNotes(deck string, cards []ankiCard) string {
+	var b strings.Builder
+	b.WriteString("#separator:tab\n#html:true\n#notetype:Basic\n")
+	fmt.Fprintf(&b, "#deck:%s\n", strings.Join(strings.Fields(deck), " "))
+	b.WriteString("#columns:Front\tBack\n")
+	for _, card := range cards {
+		back := ankiField(card.Text)
+		if card.Translation != "" {
+			back += "<br><br><i>" + ankiField(card.Translation) + "</i>"
+		}
+		fmt.Fprintf(&b, "[sound:%s]\t%s\n", card.Audio, back)
+	}
+	return b.String()
+}
+
+// ankiMediaPrefix returns the start of the snippet names. Anki keeps all media
+// of a collection in one folder, so the names include the recording's name.
+func ankiMediaPrefix(args Args, originalFile string) string {
+	return strings.TrimSuffix(uploadFileName(outputStem(args, originalFile)+".m4a"), ".m4a")
+}
+
+// saveAnkiDeck cuts an audio snippet for every sentence and writes an Anki
+// import file pairing them with their text and optional translation
+func saveAnkiDeck(ctx context.Context, client openai.Client, args Args, originalFile string, track int, transcript *Transcript) error {
+	var sentences []Segment
+	for _, segment := range mergeSegments(transcript.Segments, ankiMerge) {
+		if strings.TrimSpace(segment.Text) != "" {
+			sentences = append(sentences, segment)
+		}
+	}
+	if len(sentences) == 0 {
+		return errors.New(tr("the transcript has no sentences to make cards of"))
+	}
+
+	var translations []string
+	if args.AnkiTranslate != "" {
+		uiPrintf(tr(" Translating %d sentences into %s with %s...\n"), len(sentences), languageName(args.AnkiTranslate), args.AnalysisModel)
+		var err error
+		if translations, err = translateSegments(ctx, client, args.AnalysisModel, args.AnkiTranslate, sentences); err != nil {
+			return err
+		}
+	}
+
+	mediaDir := sidecarFileName(args, originalFile, ".anki-media")
+	if err := os.MkdirAll(mediaDir, 0755); err != nil {
+		return fmt.Errorf("failed to create media directory: %w", err)
+	}
+
+	uiPrintf(tr(" Cutting %d audio snippets for Anki...\n"), len(sentences))
+	prefix := ankiMediaPrefix(args, originalFile)
+	cards := make([]ankiCard, len(sentences))
+	for i, sentence := range sentences {
+		cards[i] = ankiCard{Audio: fmt.Sprintf("%s_%04d.m4a", prefix, i+1), Text: sentence.Text}
+		if translations != nil {
+			cards[i].Translation = translations[i]
+		}
+		start := max(0, sentence.Start-ankiPadding)
+		if err := extractAudioSlice(ctx, originalFile, filepath.Join(mediaDir, cards[i].Audio), start, sentence.End+ankiPadding, track); err != nil {
+			return fmt.Errorf("sentence %d: %w", i+1, err)
+		}
+	}
+
+	notesFile := sidecarFileName(args, originalFile, ".anki.txt")
+	if err := os.WriteFile(notesFile, []byte(renderAnkiNotes(fileStem(originalFile), cards)), 0644); err != nil {
+		return fmt.Errorf("failed to write Anki notes: %w", err)
+	}
+	uiPrintf(tr("💾 Anki deck saved to: %s\n"), notesFile)
+	uiPrintf(tr("   Copy the files in %s into Anki's collection.media folder, then import the deck with File > Import\n"), mediaDir)
+	return nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestRenderAnkiNotes(t *testing.T) {
+	cards := []ankiCard{
+		{Audio: "talk_0001.m4a", Text: " Hello <there>,\tfriend."},
+		{Audio: "talk_0002.m4a", Text: "Wie geht's?", Translation: "How are\nyou?"},
+	}
+	expected := "#separator:tab\n#html:true\n#notetype:Basic\n#deck:My talk\n#columns:Front\tBack\n" +
+		"[sound:talk_0001.m4a]\tHello &lt;there&gt;, friend.\n" +
+		"[sound:talk_0002.m4a]\tWie geht&#39;s?<br><br><i>How are you?</i>\n"
+	if result := renderAnkiNotes("My\ttalk", cards); result != expected {
+		t.Errorf("Expected\n%q\ngot\n%q", expected, result)
+	}
+}
+
+func TestAnkiMediaPrefix(t *testing.T) {
+	prefix := ankiMediaPrefix(Args{}, "/audio/Talk #1 (draft).mp3")
+	if prefix != "Talk_1_draft" {
+		t.Errorf("Expected an ASCII-safe prefix, got %q", prefix)
+	}
+	if prefix := ankiMediaPrefix(Args{OutputName: "lesson 1"}, "/audio/x.mp3"); prefix != "lesson_1" {
+		t.Errorf("Expected the output name as prefix, got %q", prefix)
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/openai/openai-go"
+	"golang.org/x/term"
+)
+
+// mappingPassphraseEnv can hold the passphrase for the encrypted name mapping
+const mappingPassphraseEnv = "PINDAR_MAPPING_PASSPHRASE"
+
+// mappingKDFIterations is the PBKDF2-SHA256 work factor for the mapping key
+const mappingKDFIterations = 600000
+
+// NameMapping maps placeholders like [PERSON_1] to the original names
+type NameMapping map[string]string
+
+// encryptedMapping is the on-disk format of an encrypted name mapping
+type encryptedMapping struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// DeanonymizeArgs defines the arguments of the deanonymize subcommand
+type DeanonymizeArgs struct {
+	File    string `arg:"positional,required" help:"Anonymized transcript"`
+	Mapping string `arg:"--mapping,required" help:"Encrypted mapping file written by --anonymize"`
+	Output  string `arg:"--output,-o" help:"Write the restored transcript to this file instead of stdout"`
+}
+
+// detectPersonNames asks the analysis model for the names of people mentioned in text
+func detectPersonNames(ctx context.Context, client openai.Client, model, text string) ([]string, error) {
+	var answer struct {
+		Names []string `json:"names"`
+	}
+	instructions := `You find the names of people in transcripts. Return JSON of the form {"names": [...]} ` +
+		`listing every distinct spelling of a person's name exactly as it appears in the text, including ` +
+		`first names and surnames used on their own. Do not include organizations, places or products.`
+	if err := chatJSON(ctx, client, model, instructions, text, &answer); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range answer.Names {
+		if name = strings.TrimSpace(name); name != "" && strings.Contains(text, name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// assignPlaceholders numbers the names in order of their first appearance in text.
+// It returns the replacements for anonymizing and the mapping to reverse them.
+func assignPlaceholders(text string, names []string) (map[string]string, NameMapping) {
+	sorted := append([]string(nil), names...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.Index(text, sorted[i]) < strings.Index(text, sorted[j])
+	})
+
+	replacements := map[string]string{}
+	mapping := NameMapping{}
+	for _, name := range sorted {
+		if _, ok := replacements[name]; ok {
+			continue
+		}
+		placeholder := fmt.Sprintf("[PERSON_%d]", len(mapping)+1)
+		replacements[name] = placeholder
+		mapping[placeholder] = name
+	}
+	return replacements, mapping
+}
+
+// replaceWords replaces whole-word occurrences of the keys in text with their values,
+// preferring longer keys so "Anna Schmidt" wins over "Anna"
+func replaceWords(text string, replacements map[string]string) string {
+	if len(replacements) == 0 {
+		return text
+	}
+
+	keys := make([]string, 0, len(replacements))
+	for key := range replacements {
+		keys = append(keys, regexp.QuoteMeta(key))
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+	pattern := regexp.MustCompile(strings.Join(keys, "|"))
+
+	var b strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringIndex(text, -1) {
+		start, end := match[0], match[1]
+		if !isWordBoundary(text, start, end) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(replacements[text[start:end]])
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// isWordBoundary reports whether text[start:end] is not part of a longer word
+func isWordBoundary(text string, start, end int) bool {
+	isWordRune := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	if start > 0 {
+		if r, _ := utf8.DecodeLastRuneInString(text[:start]); isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(text) {
+		if r, _ := utf8.DecodeRuneInString(text[end:]); isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// anonymizeTranscript replaces names in the transcript text and all segments,
+// and rebuilds the words from them so no timestamp keeps a name
+func anonymizeTranscript(transcript *Transcript, replacements map[string]string) {
+	transcript.Text = replaceWords(transcript.Text, replacements)
+	for i := range transcript.Segments {
+		setSegmentText(&transcript.Segments[i], replaceWords(transcript.Segments[i].Text, replacements))
+	}
+	syncWordsWithText(transcript)
+}
+
+// deanonymizeText restores the original names in an anonymized text
+func deanonymizeText(text string, mapping NameMapping) string {
+	return replaceWords(text, mapping)
+}
+
+// encryptMapping seals the mapping with AES-256-GCM using a key derived from passphrase
+func encryptMapping(mapping NameMapping, passphrase string) ([]byte, error) {
+	plaintext, err := json.Marshal(mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal mapping: %w", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	gcm, err := mappingCipher(passphrase, salt, mappingKDFIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return json.MarshalIndent(encryptedMapping{
+		Version:    1,
+		KDF:        "pbkdf2-sha256",
+		Iterations: mappingKDFIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+}
+
+// decryptMapping opens a mapping sealed by encryptMapping
+func decryptMapping(data []byte, passphrase string) (NameMapping, error) {
+	var sealed encryptedMapping
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping file: %w", err)
+	}
+	if sealed.Version != 1 || sealed.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("unsupported mapping file version %d", sealed.Version)
+	}
+
+	gcm, err := mappingCipher(passphrase, sealed.Salt, sealed.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != gcm.NonceSize() {
+		return nil, errors.New("invalid mapping file nonce")
+	}
+	plaintext, err := gcm.Open(nil, sealed.Nonce, sealed.Ciphertext, nil)
+	if err != nil {
+		return nil, errors.New(tr("wrong passphrase or corrupted mapping file"))
+	}
+
+	var mapping NameMapping
+	if err := json.Unmarshal(plaintext, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping: %w", err)
+	}
+	return mapping, nil
+}
+
+// mappingCipher derives the AES-GCM cipher for a passphrase and salt
+func mappingCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// readPassphrase reads the mapping passphrase from the environment or the terminal.
+// With confirm set the user has to enter it twice.
+func readPassphrase(confirm bool) (string, error) {
+	return askPassphrase(mappingPassphraseEnv, tr("Passphrase for the name mapping: "), confirm)
+}
+
+// askPassphrase reads a passphrase from the environment variable env, or asks
+// for it on the terminal with prompt
+func askPassphrase(env, prompt string, confirm bool) (string, error) {
+	if passphrase := os.Getenv(env); passphrase != "" {
+		return passphrase, nil
+	}
+	if !term.IsTerminal(int(syscall.Stdin)) {
+		return "", fmt.Errorf(tr("no terminal to ask for the passphrase, set %s"), env)
+	}
+
+	uiPrint(prompt)
+	passphrase, err := term.ReadPassword(int(syscall.Stdin))
+	uiPrintln("")
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if len(passphrase) == 0 {
+		return "", errors.New(tr("passphrase cannot be empty"))
+	}
+
+	if confirm {
+		uiPrint(tr("Repeat passphrase: "))
+		repeated, err := term.ReadPassword(int(syscall.Stdin))
+		uiPrintln("")
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if string(repeated) != string(passphrase) {
+			return "", errors.New(tr("passphrases do not match"))
+		}
+	}
+	return string(passphrase), nil
+}
+
+// runDeanonymize restores the names in a transcript anonymized with --anonymize
+func runDeanonymize(argv []string) {
+	var args DeanonymizeArgs
+	parseSubcommand("deanonymize", &args, argv)
+
+	content, err := os.ReadFile(args.File)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading transcript: %v\n"), err)
+		os.Exit(1)
+	}
+	sealed, err := os.ReadFile(args.Mapping)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading mapping file: %v\n"), err)
+		os.Exit(1)
+	}
+
+	passphrase, err := readPassphrase(false)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading passphrase: %v\n"), err)
+		os.Exit(1)
+	}
+	mapping, err := decryptMapping(sealed, passphrase)
+	if err != nil {
+		uiPrintf(tr("❌ Error decrypting mapping file: %v\n"), err)
+		os.Exit(1)
+	}
+
+	restored := deanonymizeText(string(content), mapping)
+	if args.Output == "" {
+		fmt.Print(restored)
+		return
+	}
+	if err := os.WriteFile(args.Output, []byte(restored), 0600); err != nil {
+		uiPrintf(tr("❌ Error writing output file: %v\n"), err)
+		os.Exit(1)
+	}
+	uiPrintf(tr("💾 Restored transcript saved to: %s\n"), args.Output)
+}
+
+// anonymize replaces the person names in the transcript and its chapters with
+// placeholders and saves the encrypted mapping next to the output
+func anonymize(ctx context.Context, client openai.Client, args Args, originalFile, passphrase string, transcript *Transcript, chapterTranscripts []*Transcript) error {
+	uiPrintf(tr(" Detecting person names with %s...\n"), args.AnalysisModel)
+	names, err := detectPersonNames(ctx, client, args.AnalysisModel, transcript.Text)
+	if err != nil {
+		return fmt.Errorf("failed to detect names: %w", err)
+	}
+
+	replacements, mapping := assignPlaceholders(transcript.Text, names)
+	anonymizeTranscript(transcript, replacements)
+	for _, chapterTranscript := range chapterTranscripts {
+		anonymizeTranscript(chapterTranscript, replacements)
+	}
+
+	sealed, err := encryptMapping(mapping, passphrase)
+	if err != nil {
+		return err
+	}
+	mappingFile := sidecarFileName(args, originalFile, ".mapping.enc")
+	if err := os.WriteFile(mappingFile, sealed, 0600); err != nil {
+		return fmt.Errorf("failed to write mapping file: %w", err)
+	}
+	uiPrintf(tr("🔒 Replaced %d names with placeholders, mapping saved to: %s\n"), len(mapping), mappingFile)
+	return nil
+}
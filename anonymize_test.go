@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAssignPlaceholders(t *testing.T) {
+	text := "Bob met Anna Schmidt. Later Anna called Bob."
+	replacements, mapping := assignPlaceholders(text, []string{"Anna Schmidt", "Anna", "Bob"})
+
+	expected := map[string]string{"Bob": "[PERSON_1]", "Anna Schmidt": "[PERSON_2]", "Anna": "[PERSON_3]"}
+	for name, placeholder := range expected {
+		if replacements[name] != placeholder {
+			t.Errorf("Expected %q for %q, got %q", placeholder, name, replacements[name])
+		}
+		if mapping[placeholder] != name {
+			t.Errorf("Expected %q for %q, got %q", name, placeholder, mapping[placeholder])
+		}
+	}
+}
+
+func TestReplaceWords(t *testing.T) {
+	replacements := map[string]string{"Anna Schmidt": "[PERSON_1]", "Anna": "[PERSON_2]", "Jürgen": "[PERSON_3]"}
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"Longest name wins", "Anna Schmidt and Anna.", "[PERSON_1] and [PERSON_2]."},
+		{"Part of a longer word", "Annabelle and Hannah", "Annabelle and Hannah"},
+		{"Non-ASCII boundaries", "Jürgens Hund, nicht Jürgen.", "Jürgens Hund, nicht [PERSON_3]."},
+		{"Possessive", "Anna's notes", "[PERSON_2]'s notes"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := replaceWords(tc.text, replacements)
+			if result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestAnonymizeRoundTrip(t *testing.T) {
+	transcript := &Transcript{
+		Text:     "Anna Schmidt thanked Bob.",
+		Segments: []Segment{{Text: " Anna Schmidt thanked Bob."}},
+	}
+	replacements, mapping := assignPlaceholders(transcript.Text, []string{"Anna Schmidt", "Bob"})
+	anonymizeTranscript(transcript, replacements)
+
+	if transcript.Text != "[PERSON_1] thanked [PERSON_2]." {
+		t.Errorf("Unexpected anonymized text %q", transcript.Text)
+	}
+	if transcript.Segments[0].Text != " [PERSON_1] thanked [PERSON_2]." {
+		t.Errorf("Unexpected anonymized segment %q", transcript.Segments[0].Text)
+	}
+	if restored := deanonymizeText(transcript.Text, mapping); restored != "Anna Schmidt thanked Bob." {
+		t.Errorf("Unexpected restored text %q", restored)
+	}
+}
+
+func TestAnonymizeTranscriptWords(t *testing.T) {
+	transcript := &Transcript{
+		Text:     "Anna Schmidt thanked Bob.",
+		Segments: []Segment{{Start: 0, End: 3, Text: " Anna Schmidt thanked Bob."}},
+		Words: []Word{
+			{Word: "Anna", Start: 0.1, End: 0.5},
+			{Word: "Schmidt", Start: 0.6, End: 1.0},
+			{Word: "thanked", Start: 1.2, End: 1.8},
+			{Word: "Bob", Start: 2.0, End: 2.4},
+		},
+		Logprobs: []TokenLogprob{{Token: " Anna", Logprob: -0.1}, {Token: " Schmidt", Logprob: -0.2}},
+	}
+	replacements, _ := assignPlaceholders(transcript.Text, []string{"Anna Schmidt", "Bob"})
+	anonymizeTranscript(transcript, replacements)
+
+	expected := []Word{
+		{Word: "[PERSON_1]", Start: 0.1, End: 1.0},
+		{Word: "thanked", Start: 1.2, End: 1.8},
+		{Word: "[PERSON_2].", Start: 2.0, End: 2.4},
+	}
+	if !reflect.DeepEqual(transcript.Words, expected) {
+		t.Errorf("Expected words %v, got %v", expected, transcript.Words)
+	}
+	for _, logprob := range transcript.Logprobs {
+		if logprob.Token != "" || logprob.Logprob == 0 {
+			t.Errorf("Expected the token text to be removed and its probability kept, got %+v", logprob)
+		}
+	}
+}
+
+func TestAnonymizeVerboseJSON(t *testing.T) {
+	// 20294 and 14051 stand for the tokens of " Anna" and " Schmidt"
+	transcript := &Transcript{
+		Text: "Anna Schmidt called. The weather is fine.",
+		Segments: []Segment{
+			{ID: 0, Start: 0, End: 2, Text: " Anna Schmidt called.", Tokens: []int{20294, 14051, 1444, 13}},
+			{ID: 1, Start: 2, End: 4, Text: " The weather is fine.", Tokens: []int{440, 5503, 307, 2489, 13}},
+		},
+		Words:    []Word{{Word: "Anna", Start: 0, End: 0.4}, {Word: "Schmidt", Start: 0.5, End: 1}, {Word: "called", Start: 1, End: 2}},
+		Logprobs: []TokenLogprob{{Token: " Anna", Logprob: -0.1}, {Token: " Schmidt", Logprob: -0.3}},
+	}
+	replacements, _ := assignPlaceholders(transcript.Text, []string{"Anna Schmidt"})
+	anonymizeTranscript(transcript, replacements)
+
+	output, err := renderTranscript(transcript, "verbose_json", MergeOptions{}, htmlPage{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(output, "Anna") || strings.Contains(output, "Schmidt") {
+		t.Errorf("Expected no name in the result, got %s", output)
+	}
+	var result struct {
+		Segments []Segment `json:"segments"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Segments) != 2 || result.Segments[0].Tokens != nil {
+		t.Errorf("Expected the tokens of the anonymized segment to be removed, got %+v", result.Segments)
+	}
+	if len(result.Segments) == 2 && len(result.Segments[1].Tokens) != 5 {
+		t.Errorf("Expected the tokens of an unchanged segment to be kept, got %v", result.Segments[1].Tokens)
+	}
+}
+
+func TestEncryptMapping(t *testing.T) {
+	mapping := NameMapping{"[PERSON_1]": "Anna Schmidt"}
+	sealed, err := encryptMapping(mapping, "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	opened, err := decryptMapping(sealed, "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opened["[PERSON_1]"] != "Anna Schmidt" {
+		t.Errorf("Expected %q, got %q", "Anna Schmidt", opened["[PERSON_1]"])
+	}
+
+	if _, err := decryptMapping(sealed, "wrong horse"); err == nil {
+		t.Error("Expected an error for a wrong passphrase")
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// jobReportEnv names the file a pindar process run by --manifest or --session
+// reports the transcribed audio to
+const jobReportEnv = "PINDAR_JOB_REPORT"
+
+// pricePerMinute is the list price in USD of a minute of audio per model,
+// used to estimate what a batch run cost
+var pricePerMinute = map[string]float64{
+	"whisper-1":              0.006,
+	"gpt-4o-transcribe":      0.006,
+	"gpt-4o-mini-transcribe": 0.003,
+}
+
+// jobReport is what a pindar process of a batch run reports about the audio it transcribed
+type jobReport struct {
+	Provider     string  `json:"provider"`
+	Model        string  `json:"model"`
+	AudioSeconds float64 `json:"audio_seconds"`
+}
+
+// audioSeconds returns the duration of the transcribed audio, probed if the
+// transcript doesn't have it
+func audioSeconds(transcript *Transcript, originalFile string) float64 {
+	if transcript.Duration > 0 {
+		return transcript.Duration
+	}
+	seconds, _ := probeDuration(originalFile)
+	return seconds
+}
+
+// writeJobReport writes the report of a transcribed file if pindar runs as
+// part of a batch
+func writeJobReport(args Args, seconds float64) error {
+	path := os.Getenv(jobReportEnv)
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(jobReport{Provider: args.Provider, Model: args.Model, AudioSeconds: seconds})
+	if err != nil {
+		return fmt.Errorf("failed to marshal job report: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// batchFailure is a file a batch run failed to transcribe
+type batchFailure struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+// batchSummary sums up a --manifest or --session run, printed at the end and
+// written as JSON with --summary
+type batchSummary struct {
+	Files        int     `json:"files"`
+	Transcribed  int     `json:"transcribed"`
+	AudioMinutes float64 `json:"audio_minutes"`
+	// EstimatedCost is in USD at list prices; files transcribed with models
+	// of unknown price and the fake provider aren't included
+	EstimatedCost  float64        `json:"estimated_cost_usd"`
+	AverageSeconds float64        `json:"average_seconds"`
+	Failures       []batchFailure `json:"failures"`
+
+	elapsed time.Duration
+}
+
+// addTranscribed counts a transcribed file with its report and how long it took
+func (s *batchSummary) addTranscribed(report jobReport, elapsed time.Duration) {
+	s.Files++
+	s.Transcribed++
+	s.AudioMinutes += report.AudioSeconds / 60
+	if report.Provider != providerFake {
+		s.EstimatedCost += report.AudioSeconds / 60 * pricePerMinute[report.Model]
+	}
+	s.elapsed += elapsed
+	s.AverageSeconds = s.elapsed.Seconds() / float64(s.Transcribed)
+}
+
+// addFailure counts a file that failed to transcribe
+func (s *batchSummary) addFailure(file, reason string) {
+	s.Files++
+	s.Failures = append(s.Failures, batchFailure{File: file, Reason: reason})
+}
+
+// print prints the summary with a line per failure
+func (s *batchSummary) print() {
+	uiPrintf(tr("\nSummary of %d files:\n"), s.Files)
+	uiPrintf(tr("   Transcribed:    %d, %d failed\n"), s.Transcribed, len(s.Failures))
+	uiPrintf(tr("   Audio:          %.1f minutes\n"), s.AudioMinutes)
+	uiPrintf(tr("   Estimated cost: $%.2f\n"), s.EstimatedCost)
+	if s.Transcribed > 0 {
+		uiPrintf(tr("   Average time:   %s per file\n"), time.Duration(s.AverageSeconds*float64(time.Second)).Round(time.Second))
+	}
+	for _, failure := range s.Failures {
+		uiPrintf("   ❌ %s: %s\n", failure.File, failure.Reason)
+	}
+}
+
+// write saves the summary as JSON
+func (s *batchSummary) write(path string) error {
+	if s.Failures == nil {
+		s.Failures = []batchFailure{}
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal summary: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// finishBatch prints the summary of a batch run, writes it to path if given,
+// and exits with status 1 if any file failed
+func finishBatch(summary *batchSummary, path string) {
+	summary.print()
+	if path != "" {
+		if err := summary.write(path); err != nil {
+			uiPrintf(tr("❌ Error writing summary: %v\n"), err)
+			os.Exit(1)
+		}
+		uiPrintf(tr("💾 Summary saved to: %s\n"), path)
+	}
+	if len(summary.Failures) > 0 {
+		os.Exit(1)
+	}
+}
+
+// errorLineWriter remembers the last error message written through it, as
+// the reason a pindar process of a batch run failed
+type errorLineWriter struct {
+	partial []byte
+	last    string
+}
+
+func (w *errorLineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		if message, ok := errorMessage(string(w.partial[:i])); ok {
+			w.last = message
+		}
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// errorMessage returns the message of an error line as printed in any output
+// mode, without the icon or label and a CI timestamp. A job's options may
+// turn on --ci for it alone, so the timestamp is removed in any mode.
+func errorMessage(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if stamp, rest, ok := strings.Cut(line, " "); ok {
+		if _, err := time.Parse(time.RFC3339, stamp); err == nil {
+			line = rest
+		}
+	}
+	for _, prefix := range []string{"❌", tr("Error:")} {
+		if message, ok := strings.CutPrefix(line, prefix); ok {
+			return strings.TrimSpace(message), true
+		}
+	}
+	return "", false
+}
+
+// runBatchJob runs a pindar process of a batch run and returns its report
+// and how long it took. The error of a failed process is the last error it
+// printed.
+func runBatchJob(cmd *exec.Cmd, tmpDir string) (jobReport, time.Duration, error) {
+	reportFile := filepath.Join(tmpDir, "job_report.json")
+	os.Remove(reportFile)
+	lastError := &errorLineWriter{}
+	cmd.Stdout = io.MultiWriter(cmd.Stdout, lastError)
+	cmd.Env = append(cmd.Env, jobReportEnv+"="+reportFile)
+
+	start := time.Now()
+	if err := cmd.Run(); err != nil {
+		if lastError.last != "" {
+			return jobReport{}, 0, errors.New(lastError.last)
+		}
+		return jobReport{}, 0, err
+	}
+	elapsed := time.Since(start)
+
+	var report jobReport
+	if data, err := os.ReadFile(reportFile); err == nil {
+		json.Unmarshal(data, &report)
+	}
+	return report, elapsed, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBatchSummary(t *testing.T) {
+	var summary batchSummary
+	summary.addTranscribed(jobReport{Provider: "openai", Model: "whisper-1", AudioSeconds: 600}, 30*time.Second)
+	summary.addTranscribed(jobReport{Provider: "openai", Model: "gpt-4o-mini-transcribe", AudioSeconds: 1200}, 50*time.Second)
+	summary.addTranscribed(jobReport{Provider: providerFake, Model: "whisper-1", AudioSeconds: 60}, 10*time.Second)
+	summary.addFailure("b.mp3", "Transcription failed: 401 Unauthorized")
+
+	if summary.Files != 4 || summary.Transcribed != 3 || len(summary.Failures) != 1 {
+		t.Errorf("Expected 4 files with 3 transcribed, got %+v", summary)
+	}
+	if math.Abs(summary.AudioMinutes-31) > 1e-9 {
+		t.Errorf("Expected 31 minutes of audio, got %v", summary.AudioMinutes)
+	}
+	// 10 minutes at $0.006 and 20 at $0.003; the fake provider is free
+	if math.Abs(summary.EstimatedCost-0.12) > 1e-9 {
+		t.Errorf("Expected $0.12, got %v", summary.EstimatedCost)
+	}
+	if summary.AverageSeconds != 30 {
+		t.Errorf("Expected 30 seconds per file, got %v", summary.AverageSeconds)
+	}
+
+	path := filepath.Join(t.TempDir(), "summary.json")
+	if err := summary.write(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	var written map[string]any
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if written["files"] != 4.0 || written["failures"].([]any)[0].(map[string]any)["reason"] != "Transcription failed: 401 Unauthorized" {
+		t.Errorf("Unexpected summary: %s", data)
+	}
+}
+
+func TestErrorLineWriter(t *testing.T) {
+	var w errorLineWriter
+	w.Write([]byte("📝 Transcribing...\n❌ Error converting"))
+	w.Write([]byte(" file: ffmpeg failed\n⚠️  Not an error\n❌ Trans"))
+	if w.last != "Error converting file: ffmpeg failed" {
+		t.Errorf("Expected the last complete error line, got %q", w.last)
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	for _, line := range []string{"❌ Upload failed", "Error: Upload failed", "2026-10-15T09:30:00Z Error: Upload failed"} {
+		if message, ok := errorMessage(line); !ok || message != "Upload failed" {
+			t.Errorf("Expected the message of %q, got %q", line, message)
+		}
+	}
+	if _, ok := errorMessage("✅ Transcription completed successfully!"); ok {
+		t.Error("Expected no error message")
+	}
+}
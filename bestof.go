@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"math"
+
+	"github.com/openai/openai-go"
+)
+
+// bestOfTemperatureStep is the temperature increase between best-of runs
+const bestOfTemperatureStep = 0.2
+
+// bestOfTemperatures returns the sampling temperatures for n runs starting at base
+func bestOfTemperatures(base float64, n int) []float64 {
+	temperatures := make([]float64, n)
+	for i := range temperatures {
+		temperatures[i] = math.Min(1.0, base+float64(i)*bestOfTemperatureStep)
+	}
+	return temperatures
+}
+
+// transcriptScore rates a transcript by the model's confidence: the duration
+// weighted average log probability of its segments, or the mean token log
+// probability for models that report logprobs instead of segments. Higher is
+// better; ok is false when the response carries no confidence information.
+func transcriptScore(transcript *Transcript) (score float64, ok bool) {
+	if len(transcript.Segments) > 0 {
+		var weighted, total float64
+		for _, s := range transcript.Segments {
+			duration := s.End - s.Start
+			weighted += s.AvgLogprob * duration
+			total += duration
+		}
+		if total > 0 {
+			return weighted / total, true
+		}
+	}
+
+	if len(transcript.Logprobs) > 0 {
+		var sum float64
+		for _, l := range transcript.Logprobs {
+			sum += l.Logprob
+		}
+		return sum / float64(len(transcript.Logprobs)), true
+	}
+
+	return 0, false
+}
+
+// transcribeBestOf transcribes the file args.BestOf times at increasing
+// temperatures and returns the most confident result
+func transcribeBestOf(ctx context.Context, client openai.Client, args Args, path, uploadName string) (*Transcript, error) {
+	if args.BestOf <= 1 {
+		return transcribeFile(ctx, client, args, path, uploadName)
+	}
+
+	var best *Transcript
+	bestScore := math.Inf(-1)
+	for i, temperature := range bestOfTemperatures(args.Temperature, args.BestOf) {
+		runArgs := args
+		runArgs.Temperature = temperature
+
+		transcript, err := transcribeFile(ctx, client, runArgs, path, uploadName)
+		if err != nil {
+			return nil, err
+		}
+
+		score, ok := transcriptScore(transcript)
+		if !ok {
+			uiPrintf(tr("⚠️  Run %d/%d returned no confidence information, keeping the best result so far\n"), i+1, args.BestOf)
+			if best == nil {
+				best = transcript
+			}
+			break
+		}
+
+		uiPrintf(tr("   Run %d/%d (temperature %.1f): avg logprob %.3f\n"), i+1, args.BestOf, temperature, score)
+		if score > bestScore {
+			best, bestScore = transcript, score
+		}
+	}
+
+	return best, nil
+}
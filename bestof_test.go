@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestBestOfTemperatures(t *testing.T) {
+	result := bestOfTemperatures(0.5, 4)
+	expected := []float64{0.5, 0.7, 0.9, 1.0}
+
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d temperatures, got %d", len(expected), len(result))
+	}
+	for i := range expected {
+		if diff := result[i] - expected[i]; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("Temperature %d: expected %v, got %v", i, expected[i], result[i])
+		}
+	}
+}
+
+func TestTranscriptScore(t *testing.T) {
+	tests := []struct {
+		name       string
+		transcript *Transcript
+		expected   float64
+		expectOK   bool
+	}{
+		{
+			name: "Segments weighted by duration",
+			transcript: &Transcript{Segments: []Segment{
+				{Start: 0, End: 3, AvgLogprob: -0.2},
+				{Start: 3, End: 4, AvgLogprob: -0.6},
+			}},
+			expected: -0.3,
+			expectOK: true,
+		},
+		{
+			name: "Token logprobs",
+			transcript: &Transcript{Logprobs: []TokenLogprob{
+				{Token: "Hello", Logprob: -0.1},
+				{Token: " world", Logprob: -0.3},
+			}},
+			expected: -0.2,
+			expectOK: true,
+		},
+		{
+			name:       "No confidence information",
+			transcript: &Transcript{Text: "Hello world"},
+			expectOK:   false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			score, ok := transcriptScore(tc.transcript)
+			if ok != tc.expectOK {
+				t.Fatalf("Expected ok=%v, got %v", tc.expectOK, ok)
+			}
+			if diff := score - tc.expected; ok && (diff > 1e-9 || diff < -1e-9) {
+				t.Errorf("Expected score %v, got %v", tc.expected, score)
+			}
+		})
+	}
+}
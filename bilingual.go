@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// markdownCell escapes text for a cell of a Markdown table
+func markdownCell(text string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(text), " "), "|", `\|`)
+}
+
+// renderBilingual formats segments and their translations as a Markdown table
+// with one row per segment, so both languages can be read side by side
+func renderBilingual(title, from, to string, segments []Segment, translations []string) string {
+	original := "Original"
+	if from != "" {
+		original += " (" + from + ")"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Bilingual transcript: %s\n\n", title)
+	fmt.Fprintf(&b, "| Time | %s | Translation (%s) |\n|---|---|---|\n", original, to)
+	for i, segment := range segments {
+		text, translation := markdownCell(segment.Text), markdownCell(translations[i])
+		if text == "" {
+			continue
+		}
+		// Table cells can't set their direction, but marks keep the punctuation in place
+		if isRTL(from) {
+			text = markRTL(text)
+		}
+		if isRTL(to) {
+			translation = markRTL(translation)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", formatTimestamp(segment.Start), text, translation)
+	}
+	return b.String()
+}
+
+// saveBilingual translates the transcript segment by segment and writes both
+// languages to a Markdown sidecar file
+func saveBilingual(ctx context.Context, client openai.Client, args Args, originalFile string, transcript *Transcript, merge MergeOptions) error {
+	segments := mergeSegments(transcript.Segments, merge)
+	uiPrintf(tr(" Translating %d segments into %s with %s...\n"), len(segments), languageName(args.Bilingual), args.AnalysisModel)
+	translations, err := translateSegments(ctx, client, args.AnalysisModel, args.Bilingual, segments)
+	if err != nil {
+		return err
+	}
+
+	from, _ := normalizeLanguage(transcript.Language)
+	bilingualFile := sidecarFileName(args, originalFile, ".bilingual.md")
+	content := renderBilingual(filepath.Base(originalFile), from, args.Bilingual, segments, translations)
+	if err := os.WriteFile(bilingualFile, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write bilingual transcript: %w", err)
+	}
+	uiPrintf(tr("💾 Bilingual transcript saved to: %s\n"), bilingualFile)
+	return nil
+}
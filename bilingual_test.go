@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderBilingual(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, Text: " Hola, ¿qué tal?"},
+		{Start: 2, Text: " "},
+		{Start: 65, Text: " Uno | dos\ntres"},
+	}
+	translations := []string{"Hi, how are you?", "", "One | two three"}
+
+	expected := "# Bilingual transcript: talk.mp3\n\n" +
+		"| Time | Original (es) | Translation (en) |\n|---|---|---|\n" +
+		"| 00:00:00 | Hola, ¿qué tal? | Hi, how are you? |\n" +
+		"| 00:01:05 | Uno \\| dos tres | One \\| two three |\n"
+	if result := renderBilingual("talk.mp3", "es", "en", segments, translations); result != expected {
+		t.Errorf("Expected\n%q\ngot\n%q", expected, result)
+	}
+
+	if result := renderBilingual("talk.mp3", "", "en", segments[:1], translations[:1]); !strings.Contains(result, "| Time | Original | Translation (en) |\n") {
+		t.Errorf("Expected an unlabeled original column without a detected language, got %q", result)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// formatCaptionTime renders seconds as HH:MM:SS followed by sep and milliseconds
+func formatCaptionTime(seconds float64, sep string) string {
+	ms := int(math.Round(max(seconds, 0) * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// labeledText returns the text of a segment after the name of its speaker,
+// like "Agent: Hello", for formats without a field for the speaker
+func labeledText(segment Segment) string {
+	text := strings.TrimSpace(segment.Text)
+	if segment.Speaker == "" {
+		return text
+	}
+	return segment.Speaker + ": " + text
+}
+
+// renderSRT produces SubRip subtitles with one cue per segment
+func renderSRT(segments []Segment) string {
+	var b strings.Builder
+	for i, segment := range segments {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1,
+			formatCaptionTime(segment.Start, ","), formatCaptionTime(segment.End, ","), labeledText(segment))
+	}
+	return b.String()
+}
+
+// vttVoiceEscaper escapes a speaker's name for the annotation of a voice span,
+// which ends at the first ">"
+var vttVoiceEscaper = strings.NewReplacer("&", "&amp;", ">", "&gt;", "\n", " ")
+
+// renderVTT produces WebVTT subtitles with one cue per segment. The speaker
+// of a segment is named in a voice span, which players can show or style.
+func renderVTT(segments []Segment) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, segment := range segments {
+		// "-->" would end the cue timing line early
+		text := strings.ReplaceAll(strings.TrimSpace(segment.Text), "-->", "->")
+		if segment.Speaker != "" {
+			text = "<v " + vttVoiceEscaper.Replace(segment.Speaker) + ">" + text
+		}
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n",
+			formatCaptionTime(segment.Start, "."), formatCaptionTime(segment.End, "."), text)
+	}
+	return b.String()
+}
+
+// renderAudacityLabels produces a tab-separated label track that Audacity and
+// Reaper can import, with times in seconds
+func renderAudacityLabels(segments []Segment) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		text := strings.Join(strings.Fields(labeledText(segment)), " ")
+		fmt.Fprintf(&b, "%.6f\t%.6f\t%s\n", segment.Start, segment.End, text)
+	}
+	return b.String()
+}
+
+// renderTTML produces a Timed Text Markup Language document with one paragraph
+// per segment. language is the transcript's language as reported by the API.
+// Speakers are declared as agents in the head, and each paragraph refers to
+// the agent speaking it.
+func renderTTML(segments []Segment, language string) string {
+	lang, err := normalizeLanguage(language)
+	if err != nil {
+		lang = ""
+	}
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	// Right-to-left languages set the direction of every paragraph, which tts:direction applies to
+	styling, direction := "", ""
+	if rtlLanguages[lang] {
+		styling, direction = ` xmlns:tts="http://www.w3.org/ns/ttml#styling"`, ` tts:direction="rtl"`
+	}
+	speakers := segmentSpeakers(segments)
+	agents := map[string]string{}
+	if len(speakers) > 0 {
+		styling += ` xmlns:ttm="http://www.w3.org/ns/ttml#metadata"`
+	}
+	fmt.Fprintf(&b, `<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttp="http://www.w3.org/ns/ttml#parameter"%s ttp:timeBase="media" xml:lang="%s">`+"\n", styling, lang)
+	if len(speakers) > 0 {
+		b.WriteString("  <head>\n    <metadata>\n")
+		for i, speaker := range speakers {
+			agents[speaker] = fmt.Sprintf("speaker%d", i+1)
+			fmt.Fprintf(&b, `      <ttm:agent xml:id="%s" type="person"><ttm:name type="full">`, agents[speaker])
+			xml.EscapeText(&b, []byte(speaker))
+			b.WriteString("</ttm:name></ttm:agent>\n")
+		}
+		b.WriteString("    </metadata>\n  </head>\n")
+	}
+	b.WriteString("  <body>\n    <div>\n")
+	for _, segment := range segments {
+		agent := ""
+		if segment.Speaker != "" {
+			agent = ` ttm:agent="` + agents[segment.Speaker] + `"`
+		}
+		fmt.Fprintf(&b, `      <p begin="%s" end="%s"%s%s>`, formatCaptionTime(segment.Start, "."), formatCaptionTime(segment.End, "."), agent, direction)
+		xml.EscapeText(&b, []byte(strings.TrimSpace(segment.Text)))
+		b.WriteString("</p>\n")
+	}
+	b.WriteString("    </div>\n  </body>\n</tt>\n")
+	return b.String()
+}
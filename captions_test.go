@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+)
+
+func captionTestSegments() []Segment {
+	return []Segment{
+		{Start: 0.5, End: 2.25, Text: " Hello there."},
+		{Start: 3661.1, End: 3663, Text: " Fish & chips --> <lunch>"},
+	}
+}
+
+func TestRenderSRT(t *testing.T) {
+	expected := "1\n00:00:00,500 --> 00:00:02,250\nHello there.\n\n" +
+		"2\n01:01:01,100 --> 01:01:03,000\nFish & chips --> <lunch>\n\n"
+	result := renderSRT(captionTestSegments())
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestRenderVTT(t *testing.T) {
+	expected := "WEBVTT\n\n00:00:00.500 --> 00:00:02.250\nHello there.\n\n" +
+		"01:01:01.100 --> 01:01:03.000\nFish & chips -> <lunch>\n\n"
+	result := renderVTT(captionTestSegments())
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestRenderTTML(t *testing.T) {
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttp="http://www.w3.org/ns/ttml#parameter" ttp:timeBase="media" xml:lang="en">
+  <body>
+    <div>
+      <p begin="00:00:00.500" end="00:00:02.250">Hello there.</p>
+      <p begin="01:01:01.100" end="01:01:03.000">Fish &amp; chips --&gt; &lt;lunch&gt;</p>
+    </div>
+  </body>
+</tt>
+`
+	result := renderTTML(captionTestSegments(), "english")
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestRenderAudacityLabels(t *testing.T) {
+	segments := []Segment{{Start: 0.5, End: 2.25, Text: " Hello\tthere.\n"}}
+	expected := "0.500000\t2.250000\tHello there.\n"
+	result := renderAudacityLabels(segments)
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/openai/openai-go/option"
+)
+
+// cassette is a recording of API interactions, replayed in order
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+// interaction is one recorded request and its response. Request bodies (the
+// audio) and headers (the API key) are not recorded.
+type interaction struct {
+	Request struct {
+		Method string `json:"method"`
+		Path   string `json:"path"`
+	} `json:"request"`
+	Response struct {
+		Status      int    `json:"status"`
+		ContentType string `json:"content_type,omitempty"`
+		Body        string `json:"body"`
+	} `json:"response"`
+}
+
+// cassetteRecorder records the interactions passing through it to a file
+type cassetteRecorder struct {
+	mu       sync.Mutex
+	path     string
+	secret   string
+	cassette cassette
+}
+
+// middleware sends the request and records it with its response. The cassette
+// is saved after every interaction so a failed run still leaves a recording.
+func (r *cassetteRecorder) middleware(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	res, err := next(req)
+	if err != nil {
+		return res, err
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for cassette: %w", err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	var recorded interaction
+	recorded.Request.Method = req.Method
+	recorded.Request.Path = req.URL.Path
+	recorded.Response.Status = res.StatusCode
+	recorded.Response.ContentType = res.Header.Get("Content-Type")
+	recorded.Response.Body = redactSecret(string(body), r.secret)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, recorded)
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// save writes the cassette to its file
+func (r *cassetteRecorder) save() error {
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	if err := os.WriteFile(r.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// redactSecret replaces every occurrence of secret in text
+func redactSecret(text, secret string) string {
+	if secret == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, secret, "REDACTED")
+}
+
+// cassettePlayer answers requests with the interactions of a cassette, in order
+type cassettePlayer struct {
+	mu           sync.Mutex
+	path         string
+	interactions []interaction
+	played       int
+}
+
+// loadCassette reads a cassette written by --record-cassette
+func loadCassette(path string) (*cassettePlayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &cassettePlayer{path: path, interactions: c.Interactions}, nil
+}
+
+// middleware replays the next recorded interaction instead of sending the request
+func (p *cassettePlayer) middleware(req *http.Request, _ option.MiddlewareNext) (*http.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.played >= len(p.interactions) {
+		return nil, fmt.Errorf("cassette %s has no more interactions for %s %s", p.path, req.Method, req.URL.Path)
+	}
+	recorded := p.interactions[p.played]
+	if recorded.Request.Method != req.Method || recorded.Request.Path != req.URL.Path {
+		return nil, fmt.Errorf("cassette %s expected %s %s as request %d, got %s %s", p.path,
+			recorded.Request.Method, recorded.Request.Path, p.played+1, req.Method, req.URL.Path)
+	}
+	p.played++
+
+	header := http.Header{}
+	if recorded.Response.ContentType != "" {
+		header.Set("Content-Type", recorded.Response.ContentType)
+	}
+	return &http.Response{
+		StatusCode: recorded.Response.Status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(recorded.Response.Body)),
+		Request:    req,
+	}, nil
+}
+
+// cassetteOptions returns the client options for --record-cassette and --replay-cassette
+func cassetteOptions(record, replay, apiKey string) ([]option.RequestOption, error) {
+	switch {
+	case record != "" && replay != "":
+		return nil, errors.New(tr("--record-cassette and --replay-cassette can't be combined"))
+	case record != "":
+		recorder := &cassetteRecorder{path: record, secret: apiKey}
+		return []option.RequestOption{option.WithMiddleware(recorder.middleware)}, nil
+	case replay != "":
+		player, err := loadCassette(replay)
+		if err != nil {
+			return nil, err
+		}
+		return []option.RequestOption{option.WithMiddleware(player.middleware)}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassetteRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	audio := filepath.Join(dir, "audio.mp3")
+	if err := os.WriteFile(audio, []byte("not really audio"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "cassette.json")
+	args := Args{Model: "whisper-1", Format: "verbose_json", BestOf: 1}
+
+	record, err := cassetteOptions(path, "", "fake")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client, err := newClient(providerFake, "", record...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	recorded, err := transcribeFile(context.Background(), client, args, audio, "audio.mp3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	replay, err := cassetteOptions("", path, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client, err = newClient(providerOpenAI, "", replay...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	replayed, err := transcribeFile(context.Background(), client, args, audio, "audio.mp3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if replayed.Text != recorded.Text || len(replayed.Segments) != len(recorded.Segments) {
+		t.Errorf("Expected the replayed transcript to match the recording, got %q", replayed.Text)
+	}
+
+	if _, err := transcribeFile(context.Background(), client, args, audio, "audio.mp3"); err == nil || !strings.Contains(err.Error(), "no more interactions") {
+		t.Errorf("Expected an exhausted cassette error, got %v", err)
+	}
+}
+
+func TestCassetteReplayMismatch(t *testing.T) {
+	player := &cassettePlayer{path: "test.json", interactions: make([]interaction, 1)}
+	player.interactions[0].Request.Method = "POST"
+	player.interactions[0].Request.Path = "/v1/chat/completions"
+
+	req := httptest.NewRequest("POST", "https://api.openai.com/v1/audio/transcriptions", nil)
+	if _, err := player.middleware(req, nil); err == nil || !strings.Contains(err.Error(), "expected POST /v1/chat/completions") {
+		t.Errorf("Expected a mismatch error, got %v", err)
+	}
+}
+
+func TestCassetteFlagsExclusive(t *testing.T) {
+	if _, err := cassetteOptions("a.json", "b.json", ""); err == nil {
+		t.Error("Expected an error when recording and replaying at once")
+	}
+}
+
+func TestRedactSecret(t *testing.T) {
+	if got := redactSecret("key sk-123 and sk-123", "sk-123"); got != "key REDACTED and REDACTED" {
+		t.Errorf("Expected the secret to be redacted, got %q", got)
+	}
+	if got := redactSecret("nothing", ""); got != "nothing" {
+		t.Errorf("Expected the text unchanged without a secret, got %q", got)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// chapter is a named section of an audiobook or video, with offsets in seconds
+type chapter struct {
+	Title string
+	Start float64
+	End   float64
+}
+
+// isAutoMode reports whether mode is one of auto, always and never, the modes
+// of --chapters and --telephony
+func isAutoMode(mode string) bool {
+	return mode == "auto" || mode == "always" || mode == "never"
+}
+
+// shouldSplitChapters decides from the --chapters mode whether to look for chapters
+func shouldSplitChapters(mode, path string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	default:
+		ext := getFileExtension(path)
+		return ext == "m4b" || ext == "m4a"
+	}
+}
+
+// probeChapters reads the chapter list of a media file with ffprobe
+func probeChapters(path string) ([]chapter, error) {
+	cmd, err := ffprobeCommand(path, "-v", "error", "-show_chapters", "-of", "json")
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+	return parseChapters(output)
+}
+
+// parseChapters converts ffprobe's -show_chapters JSON output into chapters
+func parseChapters(data []byte) ([]chapter, error) {
+	var probe struct {
+		Chapters []struct {
+			StartTime string `json:"start_time"`
+			EndTime   string `json:"end_time"`
+			Tags      struct {
+				Title string `json:"title"`
+			} `json:"tags"`
+		} `json:"chapters"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	chapters := make([]chapter, 0, len(probe.Chapters))
+	for i, c := range probe.Chapters {
+		start, err := strconv.ParseFloat(c.StartTime, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time for chapter %d: %w", i+1, err)
+		}
+		end, err := strconv.ParseFloat(c.EndTime, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end time for chapter %d: %w", i+1, err)
+		}
+
+		title := strings.TrimSpace(c.Tags.Title)
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", i+1)
+		}
+		chapters = append(chapters, chapter{Title: title, Start: start, End: end})
+	}
+	return chapters, nil
+}
+
+// transcribeChapters extracts and transcribes every chapter of the input separately
+func transcribeChapters(ctx context.Context, client openai.Client, args Args, path string, track int, chapters []chapter) ([]*Transcript, error) {
+	tmpDir, err := os.MkdirTemp("", "pindar_chapters")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	transcripts := make([]*Transcript, len(chapters))
+	for i, c := range chapters {
+		uiPrintf(" [%d/%d] %s (%s)\n", i+1, len(chapters), c.Title, formatTimestamp(c.Start))
+
+		slicePath := filepath.Join(tmpDir, fmt.Sprintf("chapter_%03d.mp4", i+1))
+		if err := extractAudioSlice(ctx, path, slicePath, c.Start, c.End, track); err != nil {
+			return nil, fmt.Errorf("chapter %d: %w", i+1, err)
+		}
+
+		transcript, err := transcribe(ctx, client, args, slicePath, filepath.Base(slicePath))
+		os.Remove(slicePath)
+		if err != nil {
+			return nil, fmt.Errorf("chapter %d: %w", i+1, err)
+		}
+		transcript.Text = strings.TrimSpace(transcript.Text)
+		transcripts[i] = transcript
+	}
+	return transcripts, nil
+}
+
+// combineChapterTranscripts joins chapter transcripts into one, adding a heading
+// with the chapter offset to the text and shifting segments to the chapter start
+func combineChapterTranscripts(chapters []chapter, transcripts []*Transcript) *Transcript {
+	combined := &Transcript{}
+
+	var b strings.Builder
+	for i, c := range chapters {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "## %s [%s]\n\n%s", c.Title, formatTimestamp(c.Start), transcripts[i].Text)
+
+		if combined.Language == "" {
+			combined.Language = transcripts[i].Language
+		}
+		combined.Task = transcripts[i].Task
+		for _, segment := range transcripts[i].Segments {
+			segment.ID = len(combined.Segments)
+			segment.Start += c.Start
+			segment.End += c.Start
+			combined.Segments = append(combined.Segments, segment)
+		}
+		for _, word := range transcripts[i].Words {
+			word.Start += c.Start
+			word.End += c.Start
+			combined.Words = append(combined.Words, word)
+		}
+		combined.Duration = c.End
+	}
+	combined.Text = b.String()
+
+	return combined
+}
+
+// chapterOutputFileName derives the per-chapter file name from the combined output file
+func chapterOutputFileName(outputFile string, number int) string {
+	ext := filepath.Ext(outputFile)
+	suffix := fmt.Sprintf("_chapter%02d%s", number, ext)
+	return filepath.Join(filepath.Dir(outputFile), fitFileName(strings.TrimSuffix(filepath.Base(outputFile), ext), suffix))
+}
+
+// formatTimestamp renders seconds as HH:MM:SS
+func formatTimestamp(seconds float64) string {
+	total := int(seconds)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, (total%3600)/60, total%60)
+}
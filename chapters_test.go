@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseChapters(t *testing.T) {
+	data := []byte(`{
+		"chapters": [
+			{"id": 0, "start_time": "0.000000", "end_time": "754.250000", "tags": {"title": "Prologue"}},
+			{"id": 1, "start_time": "754.250000", "end_time": "1820.500000", "tags": {}}
+		]
+	}`)
+
+	chapters, err := parseChapters(data)
+	if err != nil {
+		t.Fatalf("parseChapters() failed: %v", err)
+	}
+
+	if len(chapters) != 2 {
+		t.Fatalf("Expected 2 chapters, got %d", len(chapters))
+	}
+	if chapters[0].Title != "Prologue" || chapters[0].End != 754.25 {
+		t.Errorf("Unexpected first chapter: %+v", chapters[0])
+	}
+	if chapters[1].Title != "Chapter 2" {
+		t.Errorf("Expected fallback title 'Chapter 2', got %q", chapters[1].Title)
+	}
+}
+
+func TestCombineChapterTranscripts(t *testing.T) {
+	chapters := []chapter{
+		{Title: "Prologue", Start: 0, End: 754.25},
+		{Title: "The Journey", Start: 754.25, End: 3820.5},
+	}
+	transcripts := []*Transcript{
+		{Text: "It was a dark night.", Segments: []Segment{{Start: 0, End: 2.5, Text: " It was a dark night."}}},
+		{Text: "We set off at dawn.", Segments: []Segment{{Start: 1, End: 3, Text: " We set off at dawn."}}},
+	}
+
+	expected := "## Prologue [00:00:00]\n\nIt was a dark night.\n\n## The Journey [00:12:34]\n\nWe set off at dawn."
+	result := combineChapterTranscripts(chapters, transcripts)
+	if result.Text != expected {
+		t.Errorf("Expected %q, got %q", expected, result.Text)
+	}
+
+	if len(result.Segments) != 2 {
+		t.Fatalf("Expected 2 segments, got %d", len(result.Segments))
+	}
+	if result.Segments[1].ID != 1 || result.Segments[1].Start != 755.25 || result.Segments[1].End != 757.25 {
+		t.Errorf("Second segment not shifted to chapter offset: %+v", result.Segments[1])
+	}
+	if result.Duration != 3820.5 {
+		t.Errorf("Expected duration 3820.5, got %v", result.Duration)
+	}
+}
+
+func TestChapterOutputFileName(t *testing.T) {
+	result := chapterOutputFileName("out/book.txt", 3)
+	if result != "out/book_chapter03.txt" {
+		t.Errorf("Expected out/book_chapter03.txt, got %s", result)
+	}
+}
+
+func TestShouldSplitChapters(t *testing.T) {
+	tests := []struct {
+		mode     string
+		path     string
+		expected bool
+	}{
+		{"auto", "book.m4b", true},
+		{"auto", "book.M4A", true},
+		{"auto", "movie.mkv", false},
+		{"always", "movie.mkv", true},
+		{"never", "book.m4b", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.mode+"/"+tc.path, func(t *testing.T) {
+			if result := shouldSplitChapters(tc.mode, tc.path); result != tc.expected {
+				t.Errorf("shouldSplitChapters(%s, %s) = %v, expected %v", tc.mode, tc.path, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestIsAutoMode(t *testing.T) {
+	for _, mode := range []string{"auto", "always", "never"} {
+		if !isAutoMode(mode) {
+			t.Errorf("Expected %q to be a mode", mode)
+		}
+	}
+	for _, mode := range []string{"", "yes", "Always"} {
+		if isAutoMode(mode) {
+			t.Errorf("Expected %q not to be a mode", mode)
+		}
+	}
+}
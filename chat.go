@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
+	"github.com/openai/openai-go/shared"
+)
+
+// analysisBatchSize is the number of segments sent per request when every segment
+// gets an answer, keeping the answer well within the analysis model's output limit
+const analysisBatchSize = 100
+
+// chatJSON sends instructions and input to a chat model and decodes its JSON answer into out
+func chatJSON(ctx context.Context, client openai.Client, model, instructions, input string, out any) error {
+	completion, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model: model,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(instructions),
+			openai.UserMessage(input),
+		},
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONObject: &shared.ResponseFormatJSONObjectParam{},
+		},
+		Temperature: param.NewOpt(0.0),
+		// Transcripts are often confidential, never keep them for distillation or evals
+		Store: param.NewOpt(false),
+	})
+	if err != nil {
+		return err
+	}
+	if len(completion.Choices) == 0 {
+		return fmt.Errorf("%s returned no answer", model)
+	}
+
+	if err := json.Unmarshal([]byte(completion.Choices[0].Message.Content), out); err != nil {
+		return fmt.Errorf("failed to parse answer of %s: %w", model, err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,7 +15,23 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	OpenAIAPIKey string `json:"openai_api_key"`
+	OpenAIAPIKey string        `json:"openai_api_key"`
+	Routing      []RoutingRule `json:"routing,omitempty"`
+	// ZeroDataRetention confirms the organization has a zero data retention agreement
+	ZeroDataRetention bool `json:"zero_data_retention,omitempty"`
+	// FFmpeg overrides the ffmpeg binary and enables hardware acceleration
+	FFmpeg *FFmpegConfig `json:"ffmpeg,omitempty"`
+	// Macros map spoken phrases to the text they are replaced with
+	Macros map[string]string `json:"macros,omitempty"`
+	// Organization and Project are the IDs usage is billed to in accounts with
+	// several projects, unless --org and --project are given
+	Organization string `json:"organization,omitempty"`
+	Project      string `json:"project,omitempty"`
+	// UsageLog records the statistics pindar usage shows, set with pindar usage --enable
+	UsageLog bool `json:"usage_log,omitempty"`
+	// Model and Format are the defaults set with pindar init for --model and --format
+	Model  string `json:"model,omitempty"`
+	Format string `json:"format,omitempty"`
 }
 
 // getConfigDir returns the platform-specific configuration directory
@@ -60,12 +77,12 @@ func loadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 	
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	config, err := parseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s:\n  %w", configPath, err)
 	}
 	
-	return &config, nil
+	return config, nil
 }
 
 // saveConfig saves configuration to the config file
@@ -89,13 +106,13 @@ func saveConfig(config *Config) error {
 
 // promptForAPIKey prompts the user to enter their OpenAI API key
 func promptForAPIKey() (string, error) {
-	fmt.Print("OpenAI API key not found. Please enter your OpenAI API key: ")
+	uiPrint(tr("OpenAI API key: "))
 	
 	// Use term.ReadPassword for secure input (doesn't echo to terminal)
 	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
 	if err != nil {
 		// Fallback to regular input if term.ReadPassword fails
-		fmt.Print("\nFalling back to regular input: ")
+		uiPrint(tr("\nFalling back to regular input: "))
 		reader := bufio.NewReader(os.Stdin)
 		apiKey, err := reader.ReadString('\n')
 		if err != nil {
@@ -104,7 +121,7 @@ func promptForAPIKey() (string, error) {
 		return strings.TrimSpace(apiKey), nil
 	}
 	
-	fmt.Println() // Add newline after password input
+	uiPrintln("") // Add newline after password input
 	apiKey := strings.TrimSpace(string(bytePassword))
 	
 	if apiKey == "" {
@@ -117,8 +134,7 @@ func promptForAPIKey() (string, error) {
 // getAPIKey retrieves the API key using the priority order:
 // 1. CLI argument
 // 2. Environment variable
-// 3. Config file
-// 4. Prompt user and save to config
+// 3. Config file, where pindar init saves it
 func getAPIKey(cliAPIKey string) (string, error) {
 	// 1. CLI argument has highest priority
 	if cliAPIKey != "" {
@@ -140,22 +156,5 @@ func getAPIKey(cliAPIKey string) (string, error) {
 		return config.OpenAIAPIKey, nil
 	}
 	
-	// 4. Prompt user and save to config
-	fmt.Println("No OpenAI API key found in arguments, environment, or config file.")
-	apiKey, err := promptForAPIKey()
-	if err != nil {
-		return "", err
-	}
-	
-	// Save the API key to config
-	config.OpenAIAPIKey = apiKey
-	if err := saveConfig(config); err != nil {
-		fmt.Printf("Warning: Failed to save API key to config file: %v\n", err)
-		fmt.Println("You may need to provide the API key again next time.")
-	} else {
-		configPath, _ := getConfigFilePath()
-		fmt.Printf("API key saved to: %s\n", configPath)
-	}
-	
-	return apiKey, nil
+	return "", errors.New(tr("no OpenAI API key found; run \"pindar init\" to set one up, or pass --api-key or set OPENAI_API_KEY"))
 }
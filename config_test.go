@@ -122,3 +122,72 @@ func TestFilePermissions(t *testing.T) {
 		t.Errorf("Expected file permissions %v, got %v", expectedPerm, fileInfo.Mode().Perm())
 	}
 }
+
+func TestParseConfig(t *testing.T) {
+	config, err := parseConfig([]byte(`{"OpenAI_API_Key": "sk-test", "ffmpeg": {"path": "/usr/bin/ffmpeg"}, "macros": null}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.OpenAIAPIKey != "sk-test" || config.FFmpeg.Path != "/usr/bin/ffmpeg" {
+		t.Errorf("Unexpected config %+v", config)
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "typo",
+			data: "{\n  \"openai_apikey\": \"sk-test\"\n}",
+			want: []string{`line 2: unknown key openai_apikey, did you mean openai_api_key?`},
+		},
+		{
+			name: "nested typo and unknown key",
+			data: "{\n  \"ffmpeg\": {\n    \"hwacel\": \"vaapi\"\n  },\n  \"colour\": \"blue\"\n}",
+			want: []string{`line 3: unknown key ffmpeg.hwacel, did you mean ffmpeg.hwaccel?`, `line 5: unknown key colour, known keys are ffmpeg, format,`},
+		},
+		{
+			name: "quoted bool",
+			data: "{\n  \"usage_log\": \"true\"\n}",
+			want: []string{`line 2: usage_log must be true or false, not a string; remove the quotes`},
+		},
+		{
+			name: "types in lists and maps",
+			data: "{\"routing\": [{\"model\": 5}], \"macros\": {\"sign off\": true}}",
+			want: []string{`routing[0].model must be a string, not a number; put the value in quotes`, `macros["sign off"] must be a string`},
+		},
+		{
+			name: "object instead of list",
+			data: `{"routing": {"model": "whisper-1"}}`,
+			want: []string{`routing must be a list, not an object; wrap it in [ ]`},
+		},
+		{
+			name: "trailing comma",
+			data: "{\n  \"model\": \"whisper-1\",\n}",
+			want: []string{`line 3, column 1:`, `remove the comma after the last entry`},
+		},
+	}
+	for _, test := range tests {
+		_, err := parseConfig([]byte(test.data))
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+			continue
+		}
+		for _, want := range test.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: expected the error to contain %q, got %q", test.name, want, err)
+			}
+		}
+	}
+}
+
+func TestParseConfigDeprecatedKey(t *testing.T) {
+	deprecatedConfigKeys["ffmpeg.binary"] = "ffmpeg.path"
+	defer delete(deprecatedConfigKeys, "ffmpeg.binary")
+
+	_, err := parseConfig([]byte(`{"ffmpeg": {"binary": "/usr/bin/ffmpeg"}}`))
+	if err == nil || !strings.Contains(err.Error(), "ffmpeg.binary is no longer supported, rename it to ffmpeg.path") {
+		t.Errorf("Expected an error naming the new key, got %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// deprecatedConfigKeys maps config keys pindar no longer reads to the keys
+// that replaced them, by their full path like "ffmpeg.path". A renamed key
+// is added here so configs with the old name fail with the new one instead
+// of being ignored.
+var deprecatedConfigKeys = map[string]string{}
+
+// parseConfig parses the config file and checks it against the Config struct.
+// Unknown keys and values of the wrong type are errors rather than ignored,
+// so a typo like "openai_apikey" doesn't quietly leave a setting unset. The
+// error lists every problem with its line and a fix where there is one.
+func parseConfig(data []byte) (*Config, error) {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, errors.New(describeSyntaxError(data, syntaxErr))
+		}
+		return nil, err
+	}
+
+	checker := configChecker{data: data, dec: json.NewDecoder(bytes.NewReader(data))}
+	checker.check(reflect.TypeOf(Config{}), "")
+	if len(checker.issues) > 0 {
+		return nil, errors.New(strings.Join(checker.issues, "\n  "))
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// lineAndColumn returns the 1-based line and column of a byte offset
+func lineAndColumn(data []byte, offset int64) (int, int) {
+	before := data[:min(int(offset), len(data))]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// describeSyntaxError points to where the JSON breaks, and recognizes the
+// trailing comma JSON doesn't allow but hand-edited files often have
+func describeSyntaxError(data []byte, err *json.SyntaxError) string {
+	// The offset is past the character that broke the JSON
+	line, column := lineAndColumn(data, err.Offset-1)
+	message := fmt.Sprintf(tr("line %d, column %d: %v"), line, column, err)
+	if err.Offset > 0 && err.Offset <= int64(len(data)) && strings.ContainsRune("}]", rune(data[err.Offset-1])) {
+		if before := bytes.TrimRight(data[:err.Offset-1], " \t\r\n"); bytes.HasSuffix(before, []byte(",")) {
+			message += tr("; remove the comma after the last entry")
+		}
+	}
+	return message
+}
+
+// configChecker walks the tokens of the config file alongside the Config
+// type and collects the keys and values that don't fit it
+type configChecker struct {
+	data   []byte
+	dec    *json.Decoder
+	issues []string
+}
+
+// line returns the line of the token the decoder read last
+func (c *configChecker) line() int {
+	line, _ := lineAndColumn(c.data, c.dec.InputOffset())
+	return line
+}
+
+func (c *configChecker) addIssue(format string, a ...any) {
+	c.issues = append(c.issues, fmt.Sprintf(format, a...))
+}
+
+// check reads the next value and compares it with the type at path. The
+// JSON is known to be valid, so the decoder's errors can be ignored.
+func (c *configChecker) check(t reflect.Type, path string) {
+	token, _ := c.dec.Token()
+	if token == nil {
+		// null leaves any setting unset
+		return
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	want, fix := "", ""
+	switch t.Kind() {
+	case reflect.Struct:
+		if token != json.Delim('{') {
+			want = tr("an object")
+			break
+		}
+		for c.dec.More() {
+			keyToken, _ := c.dec.Token()
+			key := keyToken.(string)
+			field, ok := configField(t, key)
+			if !ok {
+				c.unknownKey(t, joinConfigPath(path, key))
+				c.skip()
+				continue
+			}
+			c.check(field.Type, joinConfigPath(path, key))
+		}
+		c.dec.Token()
+		return
+	case reflect.Map:
+		if token != json.Delim('{') {
+			want = tr("an object")
+			break
+		}
+		for c.dec.More() {
+			keyToken, _ := c.dec.Token()
+			c.check(t.Elem(), fmt.Sprintf("%s[%q]", path, keyToken))
+		}
+		c.dec.Token()
+		return
+	case reflect.Slice:
+		if token != json.Delim('[') {
+			want, fix = tr("a list"), tr("; wrap it in [ ]")
+			break
+		}
+		for i := 0; c.dec.More(); i++ {
+			c.check(t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+		c.dec.Token()
+		return
+	case reflect.String:
+		if _, ok := token.(string); ok {
+			return
+		}
+		want = tr("a string")
+		if _, ok := token.(json.Delim); !ok {
+			fix = tr("; put the value in quotes")
+		}
+	case reflect.Bool:
+		if _, ok := token.(bool); ok {
+			return
+		}
+		want = tr("true or false")
+		if s, ok := token.(string); ok && (s == "true" || s == "false") {
+			fix = tr("; remove the quotes")
+		}
+	default:
+		c.skipToken(token)
+		return
+	}
+
+	c.addIssue(tr("line %d: %s must be %s, not %s%s"), c.line(), path, want, jsonKind(token), fix)
+	c.skipToken(token)
+}
+
+// unknownKey reports a key the type has no field for, with the key that
+// replaced it or the closest known key
+func (c *configChecker) unknownKey(t reflect.Type, path string) {
+	if replacement, ok := deprecatedConfigKeys[path]; ok {
+		c.addIssue(tr("line %d: %s is no longer supported, rename it to %s"), c.line(), path, replacement)
+		return
+	}
+
+	prefix, key := "", path
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		prefix, key = path[:i+1], path[i+1:]
+	}
+	known := configKeys(t)
+	best, bestDistance := "", 0
+	for _, name := range known {
+		if distance := levenshtein(strings.ToLower(key), name); best == "" || distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	// Allow roughly one typo per four characters, as for languages
+	if best != "" && bestDistance <= 1+len(best)/4 {
+		c.addIssue(tr("line %d: unknown key %s, did you mean %s?"), c.line(), path, prefix+best)
+		return
+	}
+	c.addIssue(tr("line %d: unknown key %s, known keys are %s"), c.line(), path, strings.Join(known, ", "))
+}
+
+// skip reads past the next value
+func (c *configChecker) skip() {
+	token, _ := c.dec.Token()
+	c.skipToken(token)
+}
+
+// skipToken reads past the rest of a value whose first token has been read
+func (c *configChecker) skipToken(token json.Token) {
+	if token != json.Delim('{') && token != json.Delim('[') {
+		return
+	}
+	for depth := 1; depth > 0; {
+		token, err := c.dec.Token()
+		if err != nil {
+			return
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
+
+// configField finds the field of a key the way encoding/json does, which
+// accepts keys in any case
+func configField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if strings.EqualFold(jsonName(field), key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// configKeys lists the keys of a config struct in alphabetical order
+func configKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, jsonName(t.Field(i)))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonName returns the key of a field from its json tag
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func joinConfigPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// jsonKind names the kind of JSON value a token starts
+func jsonKind(token json.Token) string {
+	switch token.(type) {
+	case string:
+		return tr("a string")
+	case bool:
+		return tr("true or false")
+	case float64:
+		return tr("a number")
+	}
+	if token == json.Delim('[') {
+		return tr("a list")
+	}
+	return tr("an object")
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ImportCorrectionsArgs defines the arguments of the import-corrections subcommand
+type ImportCorrectionsArgs struct {
+	Edited   string `arg:"positional,required" help:"Transcript corrected by a human (srt, vtt, verbose_json, or text/Markdown)"`
+	Original string `arg:"--original,required" help:"Transcript as pindar produced it"`
+	Record   bool   `arg:"--record" help:"Append the corrections to the corrections log for quality tracking"`
+}
+
+// CorrectionRecord summarizes the human corrections of one transcript
+type CorrectionRecord struct {
+	File         string        `json:"file"`
+	ImportedAt   time.Time     `json:"imported_at"`
+	Words        int           `json:"words"`
+	Edits        int           `json:"edits"`
+	Replacements []Replacement `json:"replacements,omitempty"`
+}
+
+// Replacement is a phrase of the machine transcript and what a human replaced it with
+type Replacement struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// diffOp is one step of a word diff: '=' keeps, '-' deletes and '+' inserts a word
+type diffOp struct {
+	Op   byte
+	Word string
+}
+
+// transcriptWords returns the words of a transcript, skipping the cue numbers
+// and timings of srt and vtt files and reading the text of JSON results
+func transcriptWords(content, path string) []string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".json" {
+		if result, err := parseResult([]byte(content)); err == nil {
+			return strings.Fields(result.Text)
+		}
+	}
+	if ext != ".srt" && ext != ".vtt" {
+		return strings.Fields(content)
+	}
+
+	var words []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "WEBVTT" || strings.Contains(line, "-->") || strings.IndexFunc(line, func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+			continue
+		}
+		words = append(words, strings.Fields(line)...)
+	}
+	return words
+}
+
+// normalizeWord lowercases a word and strips surrounding punctuation for comparison
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
+
+// diffWords computes a shortest edit script from a to b with Myers' algorithm,
+// comparing normalized words. Memory grows with the square of the number of
+// edits, which stays small for human corrections.
+func diffWords(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	equal := func(x, y int) bool { return normalizeWord(a[x]) == normalizeWord(b[y]) }
+
+	// v[k+offset] is the furthest x reached on diagonal k; trace[d] holds the
+	// diagonals -d..d of v before round d, which is all the backtracking needs
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && equal(x, y) {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(a, b, trace, d)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrackDiff walks the trace of diffWords back from the end to build the edit script
+func backtrackDiff(a, b []string, trace [][]int, depth int) []diffOp {
+	var ops []diffOp
+	x, y := len(a), len(b)
+	for d := depth; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d] }
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := 0
+		if d > 0 {
+			prevX = at(prevK)
+		}
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			ops = append(ops, diffOp{'=', b[y]})
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{'+', b[prevY]})
+			} else {
+				ops = append(ops, diffOp{'-', a[prevX]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	slices.Reverse(ops)
+	return ops
+}
+
+// summarizeCorrections counts the edits of a diff like a word error rate
+// (substitutions, deletions and insertions) and collects the replaced phrases
+func summarizeCorrections(ops []diffOp) (int, []Replacement) {
+	edits := 0
+	var replacements []Replacement
+	var from, to []string
+	flush := func() {
+		edits += max(len(from), len(to))
+		if len(from) > 0 && len(to) > 0 {
+			replacements = append(replacements, Replacement{From: strings.Join(from, " "), To: strings.Join(to, " ")})
+		}
+		from, to = nil, nil
+	}
+
+	for _, op := range ops {
+		switch op.Op {
+		case '-':
+			from = append(from, op.Word)
+		case '+':
+			to = append(to, op.Word)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return edits, replacements
+}
+
+// correctionRate returns edits per word in percent
+func correctionRate(edits, words int) float64 {
+	if words == 0 {
+		return 0
+	}
+	return float64(edits) / float64(words) * 100
+}
+
+// correctionsLogPath returns the path of the log that --record appends to
+func correctionsLogPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "corrections.jsonl"), nil
+}
+
+// recordCorrections appends a record to the corrections log
+func recordCorrections(record CorrectionRecord) (string, error) {
+	logPath, err := correctionsLogPath()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal corrections: %w", err)
+	}
+
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to open corrections log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return "", fmt.Errorf("failed to write corrections log: %w", err)
+	}
+	return logPath, nil
+}
+
+// runImportCorrections compares a human-corrected transcript with pindar's
+// original and optionally records the corrections
+func runImportCorrections(argv []string) {
+	var args ImportCorrectionsArgs
+	parseSubcommand("import-corrections", &args, argv)
+
+	original, err := os.ReadFile(args.Original)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading transcript: %v\n"), err)
+		os.Exit(1)
+	}
+	edited, err := os.ReadFile(args.Edited)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading transcript: %v\n"), err)
+		os.Exit(1)
+	}
+
+	originalWords := transcriptWords(string(original), args.Original)
+	edits, replacements := summarizeCorrections(diffWords(originalWords, transcriptWords(string(edited), args.Edited)))
+
+	uiPrintf(tr(" Compared %d words: %d edits (%.1f%% correction rate)\n"), len(originalWords), edits, correctionRate(edits, len(originalWords)))
+	for _, r := range replacements {
+		uiPrintf("   %s → %s\n", r.From, r.To)
+	}
+
+	if args.Record {
+		file, err := filepath.Abs(args.Original)
+		if err != nil {
+			file = args.Original
+		}
+		logPath, err := recordCorrections(CorrectionRecord{
+			File:         file,
+			ImportedAt:   time.Now().UTC(),
+			Words:        len(originalWords),
+			Edits:        edits,
+			Replacements: replacements,
+		})
+		if err != nil {
+			uiPrintf(tr("❌ Error recording corrections: %v\n"), err)
+			os.Exit(1)
+		}
+		uiPrintf(tr("💾 Corrections recorded in: %s\n"), logPath)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{"Identical", "the cat sat", "the cat sat", "=the =cat =sat"},
+		{"Ignores case and punctuation", "Hello, world", "hello world.", "=hello =world."},
+		{"Substitution", "we met shiva on monday", "we met Siobhán on monday", "=we =met -shiva +Siobhán =on =monday"},
+		{"Insertion and deletion", "a b c", "b c d", "-a =b =c +d"},
+		{"Empty original", "", "new text", "+new +text"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var parts []string
+			for _, op := range diffWords(strings.Fields(tc.a), strings.Fields(tc.b)) {
+				parts = append(parts, string(op.Op)+op.Word)
+			}
+			result := strings.Join(parts, " ")
+			if result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestSummarizeCorrections(t *testing.T) {
+	ops := diffWords(strings.Fields("we met shiva on monday at the cafe"), strings.Fields("we met Siobhán on Tuesday at cafe"))
+	edits, replacements := summarizeCorrections(ops)
+
+	if edits != 3 {
+		t.Errorf("Expected 3 edits, got %d", edits)
+	}
+	expected := []Replacement{{From: "shiva", To: "Siobhán"}, {From: "monday", To: "Tuesday"}}
+	if !reflect.DeepEqual(replacements, expected) {
+		t.Errorf("Expected %v, got %v", expected, replacements)
+	}
+}
+
+func TestTranscriptWords(t *testing.T) {
+	srt := "1\n00:00:00,500 --> 00:00:02,250\nHello there.\n\n2\n00:00:03,000 --> 00:00:04,000\nGeneral Kenobi.\n"
+	expected := []string{"Hello", "there.", "General", "Kenobi."}
+	if result := transcriptWords(srt, "edited.srt"); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+	if result := transcriptWords("## Intro [00:00:00]\n\n1 2 3", "notes.md"); len(result) != 6 {
+		t.Errorf("Expected all words of a Markdown file, got %q", result)
+	}
+	json := `{"schema_version": 1, "text": "Hello there.", "segments": [{"id": 0, "start": 0, "end": 1, "text": " Hello there."}]}`
+	if result := transcriptWords(json, "edited.json"); !reflect.DeepEqual(result, expected[:2]) {
+		t.Errorf("Expected the text of a JSON result, got %q", result)
+	}
+}
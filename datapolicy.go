@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Data handling policies selectable with --data-policy
+const (
+	dataPolicyDefault       = "default"
+	dataPolicyZeroRetention = "zero-retention"
+)
+
+// checkDataPolicy verifies that the requested data handling policy can be honored
+// before any audio leaves the machine. OpenAI offers no per-request retention
+// opt-out for transcriptions; zero data retention is an organization-level
+// agreement, which has to be confirmed in the config file.
+func checkDataPolicy(policy string, config *Config) error {
+	switch policy {
+	case dataPolicyDefault, "":
+		return nil
+	case dataPolicyZeroRetention:
+		if !config.ZeroDataRetention {
+			return errors.New(tr("OpenAI can't apply zero data retention per request. If your organization has a zero data retention agreement, set \"zero_data_retention\": true in the config file"))
+		}
+		return nil
+	default:
+		return fmt.Errorf(tr("unknown data policy %q, use %s or %s"), policy, dataPolicyDefault, dataPolicyZeroRetention)
+	}
+}
+
+// describeDataPolicy summarizes how the provider handles uploaded audio under a policy
+func describeDataPolicy(policy string) string {
+	if policy == dataPolicyZeroRetention {
+		return tr("zero retention (organization agreement)")
+	}
+	return tr("provider default (OpenAI may retain API data for up to 30 days for abuse monitoring)")
+}
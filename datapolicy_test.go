@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCheckDataPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		config      Config
+		expectError bool
+	}{
+		{"Default policy", dataPolicyDefault, Config{}, false},
+		{"Empty policy", "", Config{}, false},
+		{"Zero retention without agreement", dataPolicyZeroRetention, Config{}, true},
+		{"Zero retention with agreement", dataPolicyZeroRetention, Config{ZeroDataRetention: true}, false},
+		{"Unknown policy", "no-logs", Config{}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkDataPolicy(tc.policy, &tc.config)
+			if tc.expectError && err == nil {
+				t.Error("Expected an error but got none")
+			}
+			if !tc.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ffmpegStaticRelease is the ffmpeg-static release whose gzip-compressed
+// single-binary builds install-ffmpeg downloads
+const ffmpegStaticRelease = "https://github.com/eugeneware/ffmpeg-static/releases/download/b6.0"
+
+// ffmpegStaticPlatforms maps GOOS/GOARCH to the platform names of the release assets
+var ffmpegStaticPlatforms = map[string]string{
+	"darwin/amd64":  "darwin-x64",
+	"darwin/arm64":  "darwin-arm64",
+	"linux/amd64":   "linux-x64",
+	"linux/arm64":   "linux-arm64",
+	"linux/386":     "linux-ia32",
+	"windows/amd64": "win32-x64",
+	"windows/386":   "win32-ia32",
+}
+
+// ffmpegStaticReleaseAPI describes the release with the SHA-256 digest GitHub
+// computed for each of its assets
+const ffmpegStaticReleaseAPI = "https://api.github.com/repos/eugeneware/ffmpeg-static/releases/tags/b6.0"
+
+// ffmpegDownloadTimeout bounds the whole download, which is around 50 MB
+const ffmpegDownloadTimeout = 10 * time.Minute
+
+// ffmpegReleaseTimeout bounds looking up the digests of the release
+const ffmpegReleaseTimeout = 30 * time.Second
+
+// DepsArgs defines the arguments of the deps subcommand
+type DepsArgs struct {
+	InstallFFmpeg *InstallFFmpegArgs `arg:"subcommand:install-ffmpeg" help:"Download a static ffmpeg build for this platform into pindar's data directory"`
+}
+
+// InstallFFmpegArgs defines the arguments of deps install-ffmpeg
+type InstallFFmpegArgs struct {
+	URL    string `arg:"--url" help:"Download a gzip-compressed ffmpeg binary from this URL instead of the build for this platform"`
+	SHA256 string `arg:"--sha256" help:"SHA-256 digest the downloaded file must have; required with --url"`
+	Force  bool   `arg:"--force" help:"Replace an ffmpeg that was installed before"`
+}
+
+// getDataDir returns the directory for files pindar downloads, like ffmpeg
+func getDataDir() (string, error) {
+	var base string
+	var err error
+	switch runtime.GOOS {
+	case "windows":
+		base, err = os.UserCacheDir() // %LocalAppData%
+	case "darwin":
+		base, err = os.UserConfigDir() // ~/Library/Application Support
+	default:
+		base = os.Getenv("XDG_DATA_HOME")
+		if base == "" {
+			var home string
+			home, err = os.UserHomeDir()
+			base = filepath.Join(home, ".local", "share")
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user data directory: %w", err)
+	}
+	return filepath.Join(base, "pindar"), nil
+}
+
+// installedFFmpegPath returns where install-ffmpeg puts the ffmpeg binary
+func installedFFmpegPath() (string, error) {
+	dataDir, err := getDataDir()
+	if err != nil {
+		return "", err
+	}
+	name := "ffmpeg"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(dataDir, "bin", name), nil
+}
+
+// ffmpegDownloadURL returns the static ffmpeg build for a platform
+func ffmpegDownloadURL(goos, goarch string) (string, error) {
+	platform, ok := ffmpegStaticPlatforms[goos+"/"+goarch]
+	if !ok {
+		return "", fmt.Errorf(tr("no static ffmpeg build for %s/%s, install ffmpeg with your package manager"), goos, goarch)
+	}
+	return ffmpegStaticRelease + "/ffmpeg-" + platform + ".gz", nil
+}
+
+// ffmpegReleaseDigest looks up the SHA-256 digest of a release asset, as
+// GitHub lists it on the release page. The binary is run without asking
+// whenever no other ffmpeg is found, so a download that doesn't match it is
+// never installed.
+func ffmpegReleaseDigest(releaseURL, asset string) (string, error) {
+	client := http.Client{Timeout: ffmpegReleaseTimeout}
+	res, err := client.Get(releaseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the ffmpeg release: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to look up the ffmpeg release at %s: %s", releaseURL, res.Status)
+	}
+	var release struct {
+		Assets []struct {
+			Name   string `json:"name"`
+			Digest string `json:"digest"`
+		} `json:"assets"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("failed to read the ffmpeg release: %w", err)
+	}
+	for _, candidate := range release.Assets {
+		if candidate.Name != asset {
+			continue
+		}
+		if digest, ok := strings.CutPrefix(candidate.Digest, "sha256:"); ok && digest != "" {
+			return digest, nil
+		}
+		break
+	}
+	return "", fmt.Errorf(tr("the release lists no SHA-256 digest for %s, pass the one of the release page with --sha256"), asset)
+}
+
+// downloadFFmpeg downloads and decompresses a gzip-compressed ffmpeg binary to
+// dest, if the downloaded file has the SHA-256 digest checksum. It is written
+// next to dest first, so a failed or mismatching download leaves no partial
+// binary behind.
+func downloadFFmpeg(url, checksum, dest string) (int64, error) {
+	client := http.Client{Timeout: ffmpegDownloadTimeout}
+	res, err := client.Get(url)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download ffmpeg: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to download ffmpeg from %s: %s", url, res.Status)
+	}
+	hash := sha256.New()
+	body := io.TeeReader(res.Body, hash)
+	decompressed, err := gzip.NewReader(body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decompress ffmpeg: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create directory for ffmpeg: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".ffmpeg-download-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create ffmpeg file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, decompressed)
+	if err == nil {
+		// Hash anything after the gzip stream too
+		_, err = io.Copy(io.Discard, body)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write ffmpeg: %w", err)
+	}
+	if digest := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(digest, checksum) {
+		return 0, fmt.Errorf(tr("the download from %s has the SHA-256 digest %s, not %s; not installing it"), url, digest, checksum)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return 0, fmt.Errorf("failed to make ffmpeg executable: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return 0, fmt.Errorf("failed to install ffmpeg: %w", err)
+	}
+	return size, nil
+}
+
+// runDeps installs the external tools pindar depends on
+func runDeps(argv []string) {
+	var args DepsArgs
+	parser := parseSubcommand("deps", &args, argv)
+	if args.InstallFFmpeg == nil {
+		parser.Fail(tr("missing command: install-ffmpeg"))
+	}
+
+	dest, err := installedFFmpegPath()
+	if err != nil {
+		uiPrintf(tr("❌ Error installing ffmpeg: %v\n"), err)
+		os.Exit(1)
+	}
+	if fileExists(dest) && !args.InstallFFmpeg.Force {
+		uiPrintf(tr("✅ ffmpeg is already installed at %s (use --force to replace it)\n"), dest)
+		return
+	}
+
+	url, checksum := args.InstallFFmpeg.URL, args.InstallFFmpeg.SHA256
+	if url != "" && checksum == "" {
+		parser.Fail(fmt.Sprintf(tr("no SHA-256 digest is known for %s, pass the one of the release page with --sha256"), url))
+	}
+	if url == "" {
+		if url, err = ffmpegDownloadURL(runtime.GOOS, runtime.GOARCH); err == nil && checksum == "" {
+			checksum, err = ffmpegReleaseDigest(ffmpegStaticReleaseAPI, path.Base(url))
+		}
+		if err != nil {
+			uiPrintf(tr("❌ Error installing ffmpeg: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	uiPrintf(tr(" Downloading ffmpeg from %s...\n"), url)
+	size, err := downloadFFmpeg(url, checksum, dest)
+	if err != nil {
+		uiPrintf(tr("❌ Error installing ffmpeg: %v\n"), err)
+		os.Exit(1)
+	}
+	uiPrintf(tr("✅ ffmpeg installed at %s (%.0f MB). pindar uses it when no other ffmpeg is found.\n"), dest, float64(size)/1e6)
+	if _, err := exec.LookPath("ffprobe"); err != nil {
+		uiPrintln(tr("⚠️  The static build has no ffprobe; install one with your package manager to select tracks, read chapters and route by duration"))
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFFmpegDownloadURL(t *testing.T) {
+	url, err := ffmpegDownloadURL("darwin", "arm64")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(url, "/ffmpeg-darwin-arm64.gz") {
+		t.Errorf("Expected the darwin-arm64 build, got %s", url)
+	}
+	if _, err := ffmpegDownloadURL("plan9", "amd64"); err == nil {
+		t.Error("Expected an error for a platform without a static build")
+	}
+}
+
+func TestFFmpegReleaseDigest(t *testing.T) {
+	// The assets of the b6.0 release, with made-up digests
+	var assets []string
+	for _, name := range []string{"darwin-arm64", "darwin-x64", "freebsd-x64", "linux-arm", "linux-arm64", "linux-ia32", "linux-x64", "win32-ia32", "win32-x64"} {
+		assets = append(assets, fmt.Sprintf(`{"name": "ffmpeg-%s.gz", "digest": "sha256:%x"}`, name, sha256.Sum256([]byte(name))))
+	}
+	assets = append(assets, `{"name": "ffmpeg-linux-x64.README", "digest": null}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tag_name": "b6.0", "assets": [%s]}`, strings.Join(assets, ", "))
+	}))
+	defer server.Close()
+
+	for platform := range ffmpegStaticPlatforms {
+		goos, goarch, _ := strings.Cut(platform, "/")
+		url, err := ffmpegDownloadURL(goos, goarch)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if digest, err := ffmpegReleaseDigest(server.URL, path.Base(url)); err != nil || len(digest) != 64 {
+			t.Errorf("Expected a digest for %s, got %q (%v)", platform, digest, err)
+		}
+	}
+	if _, err := ffmpegReleaseDigest(server.URL, "ffmpeg-linux-x64.README"); err == nil {
+		t.Error("Expected an error for an asset without a digest")
+	}
+}
+
+func TestDownloadFFmpeg(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte("#!/bin/sh\necho ffmpeg\n"))
+	gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ffmpeg.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	digest := sha256.Sum256(compressed.Bytes())
+	checksum := hex.EncodeToString(digest[:])
+	dest := filepath.Join(t.TempDir(), "bin", "ffmpeg")
+	if _, err := downloadFFmpeg(server.URL+"/ffmpeg.gz", strings.Repeat("0", 64), dest); err == nil || !strings.Contains(err.Error(), checksum) {
+		t.Errorf("Expected an error naming the digest of a mismatching download, got %v", err)
+	}
+	if fileExists(dest) {
+		t.Error("Expected a mismatching download not to be installed")
+	}
+
+	size, err := downloadFFmpeg(server.URL+"/ffmpeg.gz", checksum, dest)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(dest)
+	if err != nil || string(content) != "#!/bin/sh\necho ffmpeg\n" || size != int64(len(content)) {
+		t.Errorf("Expected the decompressed binary, got %q (%d bytes, %v)", content, size, err)
+	}
+	if info, _ := os.Stat(dest); runtime.GOOS != "windows" && info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected ffmpeg to be executable, got %v", info.Mode())
+	}
+
+	if _, err := downloadFFmpeg(server.URL+"/missing.gz", checksum, dest+"2"); err == nil {
+		t.Error("Expected an error for a failed download")
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(dest)); len(entries) != 1 {
+		t.Errorf("Expected no partial downloads to be left behind, got %d files", len(entries))
+	}
+}
+
+func TestConfigureFFmpegUsesInstalled(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("the data directory is only relocatable with XDG_DATA_HOME")
+	}
+	resetFFmpeg(t)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("PATH", t.TempDir())
+
+	installed, err := installedFFmpegPath()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(installed), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(installed, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := configureFFmpeg("", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ffmpegBinary != installed {
+		t.Errorf("Expected the installed ffmpeg without one in PATH, got %q", ffmpegBinary)
+	}
+}
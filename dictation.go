@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// How the output of a dictation command joins the surrounding words
+const (
+	// attachPrevious is for punctuation, written without a space before it
+	attachPrevious = iota
+	// attachNext is for opening brackets and quotes, written without a space after them
+	attachNext
+	// lineBreak is for new lines and paragraphs, written without spaces around them
+	lineBreak
+)
+
+// dictationCommand is a spoken command and the formatting it is replaced with
+type dictationCommand struct {
+	Phrase       string
+	Output       string
+	Join         int
+	EndsSentence bool
+}
+
+// dictationLanguage holds the commands of one language, and a prompt that
+// makes the model write them out as words instead of guessing the punctuation
+type dictationLanguage struct {
+	Prompt   string
+	Commands []dictationCommand
+}
+
+// dictationLanguages are the languages --dictation knows the commands of
+var dictationLanguages = map[string]dictationLanguage{
+	"en": {
+		Prompt: "Dictation with spoken commands: Dear Ms. Jones comma new paragraph thank you for your letter period",
+		Commands: []dictationCommand{
+			{"period", ".", attachPrevious, true},
+			{"full stop", ".", attachPrevious, true},
+			{"comma", ",", attachPrevious, false},
+			{"question mark", "?", attachPrevious, true},
+			{"exclamation mark", "!", attachPrevious, true},
+			{"exclamation point", "!", attachPrevious, true},
+			{"colon", ":", attachPrevious, false},
+			{"semicolon", ";", attachPrevious, false},
+			{"open parenthesis", "(", attachNext, false},
+			{"close parenthesis", ")", attachPrevious, false},
+			{"open quote", "\"", attachNext, false},
+			{"close quote", "\"", attachPrevious, false},
+			{"end quote", "\"", attachPrevious, false},
+			{"new line", "\n", lineBreak, false},
+			{"new paragraph", "\n\n", lineBreak, false},
+			{"next paragraph", "\n\n", lineBreak, false},
+		},
+	},
+	"de": {
+		Prompt: "Diktat mit gesprochenen Befehlen: Sehr geehrte Frau Jones Komma neuer Absatz vielen Dank für Ihren Brief Punkt",
+		Commands: []dictationCommand{
+			{"punkt", ".", attachPrevious, true},
+			{"komma", ",", attachPrevious, false},
+			{"fragezeichen", "?", attachPrevious, true},
+			{"ausrufezeichen", "!", attachPrevious, true},
+			{"doppelpunkt", ":", attachPrevious, false},
+			{"semikolon", ";", attachPrevious, false},
+			{"klammer auf", "(", attachNext, false},
+			{"klammer zu", ")", attachPrevious, false},
+			{"anführungszeichen auf", "„", attachNext, false},
+			{"anführungszeichen zu", "“", attachPrevious, false},
+			{"neue zeile", "\n", lineBreak, false},
+			{"neuer absatz", "\n\n", lineBreak, false},
+			{"nächster absatz", "\n\n", lineBreak, false},
+		},
+	},
+}
+
+// dictationLanguageFor returns the commands for a language code, English if
+// the language is unknown yet
+func dictationLanguageFor(code string) (dictationLanguage, error) {
+	if code == "" {
+		code = "en"
+	}
+	language, ok := dictationLanguages[code]
+	if !ok {
+		codes := make([]string, 0, len(dictationLanguages))
+		for code := range dictationLanguages {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		return dictationLanguage{}, fmt.Errorf(tr("dictation commands are not available in %s, only in: %s"), languageName(code), strings.Join(codes, ", "))
+	}
+	return language, nil
+}
+
+// promptWithDictation puts an example of dictated text before the prompt.
+// It goes first because trimPrompt keeps the end.
+func promptWithDictation(prompt string, language dictationLanguage) string {
+	if prompt == "" {
+		return language.Prompt
+	}
+	return language.Prompt + ". " + strings.TrimSpace(prompt)
+}
+
+// matchDictationCommand returns the longest command at the start of words and
+// the number of words it spans
+func matchDictationCommand(words []string, commands []dictationCommand) (dictationCommand, int) {
+	var match dictationCommand
+	length := 0
+	for _, command := range commands {
+		phrase := strings.Fields(command.Phrase)
+		if len(phrase) <= length || len(phrase) > len(words) {
+			continue
+		}
+		matched := true
+		for i, word := range phrase {
+			if normalizeWord(words[i]) != word {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			match, length = command, len(phrase)
+		}
+	}
+	return match, length
+}
+
+// capitalizeFirst upper-cases the first letter of a word, skipping leading quotes and brackets
+func capitalizeFirst(word string) string {
+	for i, r := range word {
+		if unicode.IsLetter(r) {
+			return word[:i] + string(unicode.ToUpper(r)) + word[i+utf8.RuneLen(r):]
+		}
+	}
+	return word
+}
+
+// expandDictation replaces spoken commands with the punctuation and line
+// breaks they stand for. Punctuation the model added next to a command is
+// dropped, and sentences after a full stop or line break start upper-case.
+// capitalize says whether the text starts a sentence; the returned value
+// whether the text after it does, for a sentence continuing in the next
+// segment.
+func expandDictation(text string, commands []dictationCommand, capitalize bool) (string, bool) {
+	var out []byte
+	noSpace := true
+	words := strings.Fields(text)
+	for i := 0; i < len(words); {
+		if command, n := matchDictationCommand(words[i:], commands); n > 0 {
+			switch command.Join {
+			case attachPrevious:
+				out = append(bytes.TrimRight(out, " ,.;:!?"), command.Output...)
+				capitalize, noSpace = command.EndsSentence, false
+			case attachNext:
+				if !noSpace {
+					out = append(out, ' ')
+				}
+				out = append(out, command.Output...)
+				noSpace = true
+			case lineBreak:
+				out = append(bytes.TrimRight(out, " "), command.Output...)
+				capitalize, noSpace = true, true
+			}
+			i += n
+			continue
+		}
+
+		word := words[i]
+		if capitalize {
+			word = capitalizeFirst(word)
+		}
+		if !noSpace {
+			out = append(out, ' ')
+		}
+		out = append(out, word...)
+		capitalize, noSpace = false, false
+		i++
+	}
+	return strings.TrimSpace(string(out)), capitalize
+}
+
+// applyDictation expands the commands in the text and segments of a transcript
+func applyDictation(transcript *Transcript, language dictationLanguage) {
+	transcript.Text, _ = expandDictation(transcript.Text, language.Commands, true)
+	capitalize := true
+	for i := range transcript.Segments {
+		segment := &transcript.Segments[i]
+		var expanded string
+		expanded, capitalize = expandDictation(segment.Text, language.Commands, capitalize)
+		if strings.HasPrefix(segment.Text, " ") {
+			expanded = " " + expanded
+		}
+		setSegmentText(segment, expanded)
+	}
+	syncWordsWithText(transcript)
+}
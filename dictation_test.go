@@ -0,0 +1,76 @@
+package main
+
+import "testing"
+
+func TestExpandDictation(t *testing.T) {
+	english := dictationLanguages["en"].Commands
+	german := dictationLanguages["de"].Commands
+	tests := []struct {
+		name     string
+		text     string
+		commands []dictationCommand
+		expected string
+	}{
+		{"punctuation", "dear ms. jones comma new paragraph thank you for your letter period", english,
+			"Dear ms. jones,\n\nThank you for your letter."},
+		{"punctuation the model added", "The patient, comma, is stable. Period. Next paragraph. Plan colon rest.", english,
+			"The patient, is stable.\n\nPlan: rest."},
+		{"brackets and quotes", "he said open quote no close quote open parenthesis twice close parenthesis full stop", english,
+			"He said \"no\" (twice)."},
+		{"question and new line", "any allergies question mark new line none exclamation point", english,
+			"Any allergies?\nNone!"},
+		{"german", "sehr geehrte damen und herren Komma neuer Absatz der Vertrag liegt bei Punkt", german,
+			"Sehr geehrte damen und herren,\n\nDer Vertrag liegt bei."},
+		{"german quotes", "er sagte Anführungszeichen auf nein Anführungszeichen zu Punkt", german,
+			"Er sagte „nein“."},
+	}
+	for _, test := range tests {
+		if result, _ := expandDictation(test.text, test.commands, true); result != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, result)
+		}
+	}
+}
+
+func TestApplyDictation(t *testing.T) {
+	transcript := &Transcript{
+		Text:     "hello comma world period",
+		Segments: []Segment{{Text: " hello comma world period"}},
+	}
+	applyDictation(transcript, dictationLanguages["en"])
+	if transcript.Text != "Hello, world." || transcript.Segments[0].Text != " Hello, world." {
+		t.Errorf("Expected text and segments to be expanded, got %q and %q", transcript.Text, transcript.Segments[0].Text)
+	}
+}
+
+func TestApplyDictationAcrossSegments(t *testing.T) {
+	transcript := &Transcript{
+		Text:     "the results are normal and the patient period can go home",
+		Segments: []Segment{{Text: " the results are normal"}, {Text: " and the patient period"}, {Text: " can go home"}},
+	}
+	applyDictation(transcript, dictationLanguages["en"])
+	expected := []string{" The results are normal", " and the patient.", " Can go home"}
+	for i, segment := range transcript.Segments {
+		if segment.Text != expected[i] {
+			t.Errorf("Segment %d: expected %q, got %q", i, expected[i], segment.Text)
+		}
+	}
+}
+
+func TestDictationLanguageFor(t *testing.T) {
+	if language, err := dictationLanguageFor(""); err != nil || language.Prompt != dictationLanguages["en"].Prompt {
+		t.Errorf("Expected English without a language, got %v", err)
+	}
+	if _, err := dictationLanguageFor("fr"); err == nil {
+		t.Error("Expected an error for a language without commands")
+	}
+}
+
+func TestPromptWithDictation(t *testing.T) {
+	language := dictationLanguages["en"]
+	if prompt := promptWithDictation("", language); prompt != language.Prompt {
+		t.Errorf("Unexpected prompt %q", prompt)
+	}
+	if prompt := promptWithDictation(" Cardiology ", language); prompt != language.Prompt+". Cardiology" {
+		t.Errorf("Expected the example before the prompt, got %q", prompt)
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DiffArgs defines the arguments of the diff subcommand
+type DiffArgs struct {
+	Human    string `arg:"positional,required" help:"Official transcript (srt, vtt, verbose_json, or text/Markdown)"`
+	Machine  string `arg:"positional,required" help:"Machine transcript to review; srt, vtt and verbose_json give the differences timestamps"`
+	HTML     string `arg:"--html" help:"Save the diff as an HTML page that marks the differences in the machine transcript"`
+	AudioURL string `arg:"--audio-url" help:"Audio to add a player to the HTML page with; clicking a paragraph or difference plays it from there"`
+}
+
+// diffWord is a word of the machine transcript with the time it is said and
+// the segment or cue it belongs to
+type diffWord struct {
+	Text    string
+	Start   float64
+	Segment int
+}
+
+// diffToken is a word of the aligned transcripts: '=' in both, '-' only in
+// the machine transcript and '+' only in the official one. Words the machine
+// transcript lacks take the time and segment of the word they follow.
+type diffToken struct {
+	Op      byte
+	Text    string
+	Start   float64
+	Segment int
+}
+
+// Element returns the HTML element marking the token on the diff page, or
+// an empty string for a word in both transcripts
+func (t diffToken) Element() string {
+	switch t.Op {
+	case '-':
+		return "del"
+	case '+':
+		return "ins"
+	}
+	return ""
+}
+
+// diffChange is a run of differing words
+type diffChange struct {
+	Start   float64
+	Machine []string
+	Human   []string
+}
+
+// parseCueTime parses an srt or vtt timestamp like 00:01:02,345 or 01:02.345
+func parseCueTime(value string) (float64, error) {
+	seconds := 0.0
+	for _, part := range strings.Split(strings.Replace(value, ",", ".", 1), ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", value)
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds, nil
+}
+
+// parseCues reads the cues of an srt or vtt file as segments. Blocks without
+// a timing line, like the WEBVTT header and NOTE blocks, are skipped.
+func parseCues(content string) []Segment {
+	var segments []Segment
+	blocks := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n\n")
+	for _, block := range blocks {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		for i, line := range lines {
+			from, to, ok := strings.Cut(line, "-->")
+			if !ok {
+				continue
+			}
+			// vtt cue settings follow the end time
+			start, err := parseCueTime(strings.TrimSpace(from))
+			fields := strings.Fields(to)
+			if err != nil || len(fields) == 0 {
+				break
+			}
+			end, _ := parseCueTime(fields[0])
+			segments = append(segments, Segment{ID: len(segments), Start: start, End: end, Text: strings.Join(lines[i+1:], " ")})
+			break
+		}
+	}
+	return segments
+}
+
+// timedSegments returns the segments and words of an srt or vtt file or a
+// JSON result, or false for a transcript without timestamps
+func timedSegments(content, path string) ([]Segment, []Word, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if result, err := parseResult([]byte(content)); err == nil && len(result.Segments) > 0 {
+			return result.Segments, result.Words, true
+		}
+	case ".srt", ".vtt":
+		if segments := parseCues(content); len(segments) > 0 {
+			return segments, nil, true
+		}
+	}
+	return nil, nil, false
+}
+
+// machineDiffWords returns the words of the machine transcript with the time
+// of their word timestamp or else of their segment or cue, and whether the
+// transcript has times at all
+func machineDiffWords(content, path string) ([]diffWord, bool) {
+	segments, words, ok := timedSegments(content, path)
+	if !ok {
+		var plain []diffWord
+		for _, word := range transcriptWords(content, path) {
+			plain = append(plain, diffWord{Text: word})
+		}
+		return plain, false
+	}
+
+	var timed []diffWord
+	for i, segmentWords := range segmentWords(segments, words) {
+		for _, token := range htmlTokens(segments[i].Text, segmentWords) {
+			if strings.TrimSpace(token.Text) == "" {
+				continue
+			}
+			word := diffWord{Text: token.Text, Start: segments[i].Start, Segment: i}
+			if token.Timed {
+				word.Start = token.Start
+			}
+			timed = append(timed, word)
+		}
+	}
+	return timed, true
+}
+
+// alignDiff aligns the machine transcript word by word with the official one
+func alignDiff(machine []diffWord, human []string) []diffToken {
+	texts := make([]string, len(machine))
+	for i, word := range machine {
+		texts[i] = word.Text
+	}
+
+	var tokens []diffToken
+	next := 0
+	for _, op := range diffWords(texts, human) {
+		token := diffToken{Op: op.Op, Text: op.Word}
+		var at diffWord
+		switch {
+		case op.Op != '+':
+			// Kept words are shown as the machine transcript spells them
+			at = machine[next]
+			token.Text = at.Text
+			next++
+		case next > 0:
+			at = machine[next-1]
+		case len(machine) > 0:
+			at = machine[0]
+		}
+		token.Start, token.Segment = at.Start, at.Segment
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// diffChanges groups the differing words of an aligned diff into changes
+func diffChanges(tokens []diffToken) []diffChange {
+	var changes []diffChange
+	var current *diffChange
+	for _, token := range tokens {
+		if token.Op == '=' {
+			current = nil
+			continue
+		}
+		if current == nil {
+			changes = append(changes, diffChange{Start: token.Start})
+			current = &changes[len(changes)-1]
+		}
+		if token.Op == '-' {
+			current.Machine = append(current.Machine, token.Text)
+		} else {
+			current.Human = append(current.Human, token.Text)
+		}
+	}
+	return changes
+}
+
+// formatDiffChange shows a change the way git's word diff does
+func formatDiffChange(change diffChange) string {
+	var b strings.Builder
+	if len(change.Machine) > 0 {
+		fmt.Fprintf(&b, "[-%s-]", strings.Join(change.Machine, " "))
+	}
+	if len(change.Human) > 0 {
+		fmt.Fprintf(&b, "{+%s+}", strings.Join(change.Human, " "))
+	}
+	return b.String()
+}
+
+// diffParagraph is a segment or cue of the machine transcript on the diff page
+type diffParagraph struct {
+	Start  float64
+	Time   string
+	Tokens []diffToken
+}
+
+var diffTemplate = template.Must(template.New("diff").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font: 18px/1.6 system-ui, sans-serif; max-width: 46em; margin: 0 auto; padding: 0 1em 4em; color: #222; }
+header { position: sticky; top: 0; background: #fff; padding: 1em 0; border-bottom: 1px solid #ddd; }
+h1 { font-size: 1.2em; margin: 0 0 .3em; }
+header p { margin: 0 0 .5em; color: #555; }
+audio { width: 100%; }
+main p { margin: .8em 0; }
+time { color: #888; font-size: .8em; margin-inline-end: .6em; font-variant-numeric: tabular-nums; }
+del { background: #ffe0e0; color: #a00; }
+ins { background: #dff5df; color: #060; text-decoration: none; }
+[data-start] { cursor: pointer; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p>{{.Summary}} <del>{{.MachineLabel}}</del> <ins>{{.HumanLabel}}</ins></p>
+{{- if .Audio}}
+<audio id="player" controls preload="metadata" src="{{.Audio}}"></audio>
+{{- end}}
+</header>
+<main>
+{{- range .Paragraphs}}
+<p{{if $.Timed}} data-start="{{.Start}}"><time>{{.Time}}</time>{{else}}>{{end}}
+{{- range $i, $token := .Tokens}}{{if $i}} {{end}}
+{{- if eq $token.Element "del"}}<del{{if $.Timed}} data-start="{{$token.Start}}"{{end}}>{{$token.Text}}</del>
+{{- else if eq $token.Element "ins"}}<ins{{if $.Timed}} data-start="{{$token.Start}}"{{end}}>{{$token.Text}}</ins>
+{{- else}}{{$token.Text}}{{end}}{{end}}</p>
+{{- end}}
+</main>
+{{- if .Audio}}
+<script>
+(function () {
+  var player = document.getElementById("player");
+  document.querySelectorAll("[data-start]").forEach(function (el) {
+    el.addEventListener("click", function (event) {
+      event.stopPropagation();
+      player.currentTime = parseFloat(el.dataset.start);
+      player.play();
+    });
+  });
+})();
+</script>
+{{- end}}
+</body>
+</html>
+`))
+
+// renderDiffHTML produces a page with the machine transcript by segment or
+// cue, its extra words struck through and the words it lacks inserted
+func renderDiffHTML(tokens []diffToken, timed bool, title, summary string, audio template.URL) (string, error) {
+	data := struct {
+		Title        string
+		Summary      string
+		MachineLabel string
+		HumanLabel   string
+		Audio        template.URL
+		Timed        bool
+		Paragraphs   []diffParagraph
+	}{
+		Title:        title,
+		Summary:      summary,
+		MachineLabel: tr("only in the machine transcript"),
+		HumanLabel:   tr("only in the official transcript"),
+		Audio:        audio,
+		Timed:        timed,
+	}
+	for _, token := range tokens {
+		if n := len(data.Paragraphs); n == 0 || token.Segment != data.Paragraphs[n-1].Tokens[0].Segment {
+			data.Paragraphs = append(data.Paragraphs, diffParagraph{Start: token.Start, Time: formatTimestamp(token.Start)})
+		}
+		paragraph := &data.Paragraphs[len(data.Paragraphs)-1]
+		paragraph.Tokens = append(paragraph.Tokens, token)
+	}
+
+	var b strings.Builder
+	if err := diffTemplate.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render diff: %w", err)
+	}
+	return b.String(), nil
+}
+
+// runDiff compares a machine transcript with an official one word by word,
+// so an editor can review where they differ
+func runDiff(argv []string) {
+	var args DiffArgs
+	parseSubcommand("diff", &args, argv)
+
+	human, err := os.ReadFile(args.Human)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading transcript: %v\n"), err)
+		os.Exit(1)
+	}
+	machine, err := os.ReadFile(args.Machine)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading transcript: %v\n"), err)
+		os.Exit(1)
+	}
+
+	humanWords := transcriptWords(string(human), args.Human)
+	machineWords, timed := machineDiffWords(string(machine), args.Machine)
+	tokens := alignDiff(machineWords, humanWords)
+	changes := diffChanges(tokens)
+	edits := 0
+	for _, change := range changes {
+		edits += max(len(change.Machine), len(change.Human))
+	}
+
+	summary := fmt.Sprintf(tr("%d words of the official transcript, %d differences (%.1f%% word error rate)"), len(humanWords), edits, correctionRate(edits, len(humanWords)))
+	uiPrintf(" %s\n", summary)
+	for _, change := range changes {
+		if timed {
+			uiPrintf("   %s  %s\n", formatTimestamp(change.Start), formatDiffChange(change))
+		} else {
+			uiPrintf("   %s\n", formatDiffChange(change))
+		}
+	}
+	if !timed {
+		uiPrintln(tr("💡 The machine transcript has no timestamps; compare an srt, vtt or verbose_json file to see where the differences are"))
+	}
+
+	if args.HTML != "" {
+		title := fmt.Sprintf("%s ↔ %s", filepath.Base(args.Machine), filepath.Base(args.Human))
+		page, err := renderDiffHTML(tokens, timed, title, summary, template.URL(args.AudioURL))
+		if err == nil {
+			err = os.WriteFile(args.HTML, []byte(page), 0644)
+		}
+		if err != nil {
+			uiPrintf(tr("❌ Error writing diff: %v\n"), err)
+			os.Exit(1)
+		}
+		uiPrintf(tr("💾 Diff saved to: %s\n"), args.HTML)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCues(t *testing.T) {
+	srt := "1\n00:00:01,000 --> 00:00:04,500\nHello there,\nhow are you\n\n2\n00:01:05,250 --> 00:01:08,000\nFine, thanks.\n"
+	segments := parseCues(srt)
+	if len(segments) != 2 {
+		t.Fatalf("Expected 2 cues, got %d", len(segments))
+	}
+	if segments[0].Start != 1 || segments[0].End != 4.5 || segments[0].Text != "Hello there, how are you" {
+		t.Errorf("Unexpected first cue %+v", segments[0])
+	}
+	if segments[1].Start != 65.25 {
+		t.Errorf("Expected the second cue at 65.25, got %v", segments[1].Start)
+	}
+
+	vtt := "WEBVTT\n\nNOTE made by pindar\n\n00:02.000 --> 00:03.000 align:start\nHi\n"
+	if segments := parseCues(vtt); len(segments) != 1 || segments[0].Start != 2 || segments[0].Text != "Hi" {
+		t.Errorf("Unexpected vtt cues %+v", segments)
+	}
+}
+
+func TestAlignDiff(t *testing.T) {
+	srt := "1\n00:00:01,000 --> 00:00:04,000\nHello their, how are you\n\n2\n00:00:05,000 --> 00:00:08,000\nI am find thanks.\n"
+	machine, timed := machineDiffWords(srt, "machine.srt")
+	if !timed {
+		t.Fatal("Expected an srt file to have timestamps")
+	}
+	tokens := alignDiff(machine, strings.Fields("Hello there, how are you doing? I am fine, thanks."))
+	changes := diffChanges(tokens)
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %+v", changes)
+	}
+
+	want := []struct {
+		start float64
+		text  string
+	}{
+		{1, "[-their,-]{+there,+}"},
+		{1, "{+doing?+}"},
+		{5, "[-find-]{+fine,+}"},
+	}
+	for i, w := range want {
+		if changes[i].Start != w.start || formatDiffChange(changes[i]) != w.text {
+			t.Errorf("Change %d: expected %s at %v, got %s at %v", i, w.text, w.start, formatDiffChange(changes[i]), changes[i].Start)
+		}
+	}
+}
+
+func TestMachineDiffWordsUsesWordTimestamps(t *testing.T) {
+	result := `{"text":"Hello world","segments":[{"id":0,"start":1,"end":3,"text":"Hello world."}],"words":[{"word":"Hello","start":1.2,"end":1.5},{"word":"world","start":2.1,"end":2.6}]}`
+	words, timed := machineDiffWords(result, "machine.json")
+	if !timed || len(words) != 2 || words[1].Text != "world." || words[1].Start != 2.1 {
+		t.Errorf("Unexpected words %+v", words)
+	}
+
+	if _, timed := machineDiffWords("Hello world.", "machine.txt"); timed {
+		t.Error("Expected plain text to have no timestamps")
+	}
+}
+
+func TestRenderDiffHTML(t *testing.T) {
+	machine := []diffWord{{Text: "Hello", Segment: 0}, {Text: "<their>", Segment: 0}, {Text: "again", Start: 5, Segment: 1}}
+	tokens := alignDiff(machine, []string{"Hello", "there", "again"})
+	page, err := renderDiffHTML(tokens, true, "machine.srt ↔ human.txt", "3 words", "talk.mp3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		`<del data-start="0">&lt;their&gt;</del>`,
+		`<ins data-start="0">there</ins>`,
+		`<p data-start="5"><time>00:00:05</time>again</p>`,
+		`src="talk.mp3"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected the page to contain %q:\n%s", want, page)
+		}
+	}
+}
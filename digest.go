@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// DigestArgs defines the arguments of the digest subcommand
+type DigestArgs struct {
+	Dir           string `arg:"positional,required" help:"Folder of recordings, e.g. a week of user-research calls"`
+	Output        string `arg:"--output,-o" help:"File to save the digest to (default: <folder>.digest.md)"`
+	Quotes        int    `arg:"--quotes" default:"5" help:"Key quotes to pick per recording at most"`
+	Model         string `arg:"--model" help:"OpenAI model to transcribe recordings without a transcript with"`
+	Language      string `arg:"--language" help:"Language of the recordings as ISO-639-1 code or name (optional)"`
+	Prompt        string `arg:"--prompt" help:"Optional text to guide the transcription, e.g. product names"`
+	Retranscribe  bool   `arg:"--retranscribe" help:"Transcribe every recording again instead of reading the transcripts saved next to them"`
+	AnalysisModel string `arg:"--analysis-model" default:"gpt-4o-mini" help:"Chat model writing the summaries and picking the quotes"`
+	Provider      string `arg:"--provider" default:"openai" help:"Transcription provider: openai, or fake to replay canned responses without an API key"`
+	APIKey        string `arg:"--api-key" env:"OPENAI_API_KEY" help:"OpenAI API key"`
+	Org           string `arg:"--org,env:OPENAI_ORG_ID" help:"OpenAI organization ID to bill usage to"`
+	Project       string `arg:"--project,env:OPENAI_PROJECT_ID" help:"OpenAI project ID to bill usage to"`
+}
+
+// digestTranscriptExtensions are the transcripts next to a recording that
+// pindar digest reads instead of transcribing it, in order of preference
+var digestTranscriptExtensions = []string{".json", ".srt", ".vtt"}
+
+// digestQuote is a statement the analysis model picked from a transcript
+type digestQuote struct {
+	Segment int    `json:"segment"`
+	Text    string `json:"text"`
+}
+
+// recordingDigest is the chapter of one recording in a digest
+type recordingDigest struct {
+	Name     string
+	Duration float64
+	Failed   bool
+	Summary  string
+	Quotes   []digestQuote
+	Segments []Segment
+}
+
+// cachedTranscript returns the transcript saved next to a recording, unless
+// there is none or the recording changed since
+func cachedTranscript(recording string) (string, bool) {
+	info, err := os.Stat(recording)
+	if err != nil {
+		return "", false
+	}
+	stem := strings.TrimSuffix(recording, filepath.Ext(recording))
+	for _, ext := range digestTranscriptExtensions {
+		if transcript, err := os.Stat(stem + ext); err == nil && !transcript.ModTime().Before(info.ModTime()) {
+			return stem + ext, true
+		}
+	}
+	return "", false
+}
+
+// loadDigestTranscript reads the segments and length of a transcript
+func loadDigestTranscript(path string) ([]Segment, float64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	segments, _, ok := timedSegments(string(content), path)
+	if !ok {
+		return nil, 0, fmt.Errorf(tr("%s has no segments"), filepath.Base(path))
+	}
+	duration := segments[len(segments)-1].End
+	if result, err := parseResult(content); err == nil && result.Duration > 0 {
+		duration = result.Duration
+	}
+	return segments, duration, nil
+}
+
+// summarizeRecording asks the analysis model for a summary of a transcript
+// and its most telling quotes
+func summarizeRecording(ctx context.Context, client openai.Client, model string, quotes int, segments []Segment) (string, []digestQuote, error) {
+	instructions := fmt.Sprintf(`You summarize recordings, like user-research calls, for a weekly review. The user sends numbered `+
+		`transcript segments. Return JSON of the form {"summary": ..., "quotes": [{"segment": ..., "text": ...}]}. `+
+		`"summary" is one paragraph with the main points and findings. "quotes" are up to %d of the most telling `+
+		`statements, copied word for word from the transcript, with the number of the segment they start in. `+
+		`Write the summary in the language of the transcript.`, quotes)
+
+	var answer struct {
+		Summary string        `json:"summary"`
+		Quotes  []digestQuote `json:"quotes"`
+	}
+	if err := chatJSON(ctx, client, model, instructions, numberedSegments(segments), &answer); err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace(answer.Summary), verifyQuotes(answer.Quotes, segments, quotes), nil
+}
+
+// verifyQuotes keeps up to limit quotes that are said in the segment they
+// are said to start in or the two after it, ignoring case and punctuation.
+// Paraphrased or made up quotes are dropped, so the digest never puts words
+// in anyone's mouth.
+func verifyQuotes(quotes []digestQuote, segments []Segment, limit int) []digestQuote {
+	var kept []digestQuote
+	for _, quote := range quotes {
+		if quote.Segment < 0 || quote.Segment >= len(segments) || len(kept) == limit {
+			continue
+		}
+		var said []string
+		for _, segment := range segments[quote.Segment:min(quote.Segment+3, len(segments))] {
+			said = append(said, segment.Text)
+		}
+		if strings.Contains(normalizePhrase(strings.Join(said, " ")), normalizePhrase(quote.Text)) {
+			kept = append(kept, quote)
+		}
+	}
+	return kept
+}
+
+// renderDigest formats the digest as Markdown with a chapter per recording
+func renderDigest(title string, recordings []recordingDigest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Digest: %s\n\n", title)
+	total := 0.0
+	for _, recording := range recordings {
+		total += recording.Duration
+	}
+	fmt.Fprintf(&b, "%d recordings, %s of audio\n\n", len(recordings), formatTimestamp(total))
+	for i, recording := range recordings {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, recording.Name)
+	}
+
+	for i, recording := range recordings {
+		fmt.Fprintf(&b, "\n## %d. %s\n\n", i+1, recording.Name)
+		if recording.Failed {
+			b.WriteString("_Transcription failed_\n")
+			continue
+		}
+		fmt.Fprintf(&b, "_%s_\n\n", formatTimestamp(recording.Duration))
+		summary := recording.Summary
+		if summary == "" {
+			summary = "_No summary_"
+		}
+		fmt.Fprintf(&b, "%s\n", summary)
+
+		if len(recording.Quotes) == 0 {
+			continue
+		}
+		b.WriteString("\n### Key quotes\n")
+		for _, quote := range recording.Quotes {
+			segment := recording.Segments[quote.Segment]
+			source := formatTimestamp(segment.Start)
+			if segment.Speaker != "" {
+				source += ", " + segment.Speaker
+			}
+			fmt.Fprintf(&b, "\n> %s\n> — %s\n", strings.TrimSpace(quote.Text), source)
+		}
+	}
+	return b.String()
+}
+
+// digestFileName returns the path of the digest, named after the folder
+// unless --output is given
+func digestFileName(args DigestArgs) string {
+	if args.Output != "" {
+		return args.Output
+	}
+	abs, err := filepath.Abs(args.Dir)
+	if err != nil {
+		abs = args.Dir
+	}
+	return fitFileName(filepath.Base(abs), ".digest.md")
+}
+
+// runDigest writes one document summarizing every recording of a folder,
+// with a chapter per recording. Recordings without a transcript next to them
+// are transcribed first, each by its own pindar process like --session, and
+// their transcripts are saved as verbose_json next to them for the next run.
+func runDigest(argv []string) {
+	var args DigestArgs
+	parser := parseSubcommand("digest", &args, argv)
+	if args.Quotes < 0 {
+		parser.Fail(tr("--quotes can't be negative"))
+	}
+
+	entries, err := os.ReadDir(args.Dir)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading folder: %v\n"), err)
+		os.Exit(1)
+	}
+	var recordings []string
+	for _, entry := range entries {
+		if !entry.IsDir() && sessionAudioExtensions[getFileExtension(entry.Name())] {
+			recordings = append(recordings, filepath.Join(args.Dir, entry.Name()))
+		}
+	}
+	if len(recordings) == 0 {
+		uiPrintf(tr("❌ No recordings found in %s\n"), args.Dir)
+		os.Exit(1)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		uiPrintf(tr(" Error loading config: %v\n"), err)
+		os.Exit(1)
+	}
+	apiKey := ""
+	env := os.Environ()
+	if args.Provider != providerFake {
+		if apiKey, err = getAPIKey(args.APIKey); err != nil {
+			uiPrintf(tr(" Error getting API key: %v\n"), err)
+			os.Exit(1)
+		}
+		// Ask for the API key once instead of in every process
+		env = append(env, "OPENAI_API_KEY="+apiKey)
+	}
+	client, err := newClient(args.Provider, apiKey, accountOptions(args.Org, args.Project, config)...)
+	if err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		uiPrintf(tr("❌ Error running digest: %v\n"), err)
+		os.Exit(1)
+	}
+	tmpDir, err := os.MkdirTemp("", "pindar_digest")
+	if err != nil {
+		uiPrintf(tr("❌ Error running digest: %v\n"), err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	options := []string{"--format", "verbose_json", "--output-dir", args.Dir, "--provider", args.Provider}
+	for _, option := range [][2]string{{"--model", args.Model}, {"--language", args.Language}, {"--prompt", args.Prompt}, {"--org", args.Org}, {"--project", args.Project}} {
+		if option[1] != "" {
+			options = append(options, option[0], option[1])
+		}
+	}
+
+	ctx := context.Background()
+	digests := make([]recordingDigest, len(recordings))
+	var summary batchSummary
+	for i, recording := range recordings {
+		digests[i].Name = filepath.Base(recording)
+		uiPrintf("\n[%d/%d] %s\n", i+1, len(recordings), digests[i].Name)
+
+		transcript, ok := cachedTranscript(recording)
+		if ok && !args.Retranscribe {
+			uiPrintf(tr(" Reading the transcript %s\n"), filepath.Base(transcript))
+		} else {
+			cmd := exec.Command(executable, append(options, "--", recording)...)
+			cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+			cmd.Env = env
+			report, elapsed, err := runBatchJob(cmd, tmpDir)
+			if err != nil {
+				summary.addFailure(recording, err.Error())
+				digests[i].Failed = true
+				continue
+			}
+			summary.addTranscribed(report, elapsed)
+			transcript = strings.TrimSuffix(recording, filepath.Ext(recording)) + ".json"
+		}
+
+		segments, duration, err := loadDigestTranscript(transcript)
+		if err != nil {
+			uiPrintf(tr("❌ Error reading transcript: %v\n"), err)
+			summary.addFailure(recording, err.Error())
+			digests[i].Failed = true
+			continue
+		}
+		digests[i].Segments, digests[i].Duration = segments, duration
+
+		uiPrintf(tr(" Summarizing with %s...\n"), args.AnalysisModel)
+		if digests[i].Summary, digests[i].Quotes, err = summarizeRecording(ctx, client, args.AnalysisModel, args.Quotes, segments); err != nil {
+			printAPIError(err)
+			os.Exit(1)
+		}
+	}
+
+	digestFile := digestFileName(args)
+	title := strings.TrimSuffix(filepath.Base(digestFile), ".digest.md")
+	if err := os.WriteFile(digestFile, []byte(renderDigest(title, digests)), 0644); err != nil {
+		uiPrintf(tr("❌ Error writing digest: %v\n"), err)
+		os.Exit(1)
+	}
+	uiPrintf(tr("\n💾 Digest of %d recordings saved to: %s\n"), len(recordings), digestFile)
+	if summary.Files > 0 {
+		finishBatch(&summary, "")
+	}
+}
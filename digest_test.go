@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCachedTranscript(t *testing.T) {
+	dir := t.TempDir()
+	recording := filepath.Join(dir, "call-anna.m4a")
+	os.WriteFile(recording, []byte("audio"), 0644)
+	if _, ok := cachedTranscript(recording); ok {
+		t.Error("Expected no transcript for a new recording")
+	}
+
+	os.WriteFile(filepath.Join(dir, "call-anna.srt"), []byte("1\n00:00:01,000 --> 00:00:02,000\nHi\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "call-anna.json"), []byte(`{"text":"Hi"}`), 0644)
+	if path, ok := cachedTranscript(recording); !ok || path != filepath.Join(dir, "call-anna.json") {
+		t.Errorf("Expected the JSON transcript to be preferred, got %q", path)
+	}
+
+	// A recording replaced after it was transcribed is transcribed again
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(recording, later, later)
+	if _, ok := cachedTranscript(recording); ok {
+		t.Error("Expected a transcript older than the recording to be ignored")
+	}
+}
+
+func TestVerifyQuotes(t *testing.T) {
+	segments := []Segment{
+		{Text: "I export the report every Monday."},
+		{Text: "Honestly, the export button"},
+		{Text: "is impossible to find."},
+	}
+	quotes := []digestQuote{
+		{Segment: 1, Text: "honestly the export button is impossible to find"},
+		{Segment: 0, Text: "I hate the export button."},
+		{Segment: 7, Text: "I export the report"},
+		{Segment: 0, Text: "I export the report every Monday"},
+	}
+	kept := verifyQuotes(quotes, segments, 5)
+	if len(kept) != 2 || kept[0].Segment != 1 || kept[1].Segment != 0 {
+		t.Errorf("Expected the two quotes said word for word, got %+v", kept)
+	}
+	if kept := verifyQuotes(quotes, segments, 1); len(kept) != 1 {
+		t.Errorf("Expected 1 quote at most, got %+v", kept)
+	}
+}
+
+func TestRenderDigest(t *testing.T) {
+	digest := renderDigest("week-18", []recordingDigest{
+		{
+			Name:     "call-anna.m4a",
+			Duration: 1830,
+			Summary:  "Anna exports reports weekly and can't find the export button.",
+			Quotes:   []digestQuote{{Segment: 1, Text: "The export button is impossible to find."}},
+			Segments: []Segment{{Start: 0}, {Start: 95, Speaker: "Anna"}},
+		},
+		{Name: "call-ben.m4a", Failed: true},
+	})
+
+	for _, want := range []string{
+		"# Digest: week-18\n",
+		"2 recordings, 00:30:30 of audio\n\n1. call-anna.m4a\n2. call-ben.m4a\n",
+		"## 1. call-anna.m4a\n\n_00:30:30_\n\nAnna exports reports weekly",
+		"### Key quotes\n\n> The export button is impossible to find.\n> — 00:01:35, Anna\n",
+		"## 2. call-ben.m4a\n\n_Transcription failed_\n",
+	} {
+		if !strings.Contains(digest, want) {
+			t.Errorf("Expected the digest to contain %q:\n%s", want, digest)
+		}
+	}
+}
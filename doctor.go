@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/option"
+)
+
+// DoctorArgs defines the arguments of the doctor subcommand
+type DoctorArgs struct {
+	APIKey     string `arg:"--api-key" env:"OPENAI_API_KEY" help:"OpenAI API key to check (defaults to the one in the config file)"`
+	FFmpegPath string `arg:"--ffmpeg-path,env:PINDAR_FFMPEG" help:"ffmpeg binary to check"`
+	Offline    bool   `arg:"--offline" help:"Skip the network and API key checks"`
+}
+
+// Results of a doctor check
+const (
+	checkOK = iota
+	checkWarning
+	checkFailed
+)
+
+// minFreeSpace is the free space below which conversions may run out of room
+const minFreeSpace = 1 << 30
+
+// doctorCheck is the outcome of one check with a suggested fix for problems
+type doctorCheck struct {
+	Name   string
+	Status int
+	Detail string
+	Fix    string
+}
+
+// checkConfig loads the config file and validates the settings that are only
+// read when a transcription needs them
+func checkConfig() (doctorCheck, *Config) {
+	check := doctorCheck{Name: tr("Config file")}
+	path, err := getConfigFilePath()
+	if err != nil {
+		check.Status, check.Detail = checkFailed, err.Error()
+		return check, &Config{}
+	}
+	config, err := loadConfig()
+	if err != nil {
+		check.Status, check.Detail = checkFailed, err.Error()
+		check.Fix = fmt.Sprintf(tr("Fix the JSON in %s or delete the file to start over"), path)
+		return check, &Config{}
+	}
+
+	if err := validateRoutingRules(config.Routing); err != nil {
+		check.Status, check.Detail = checkFailed, err.Error()
+		check.Fix = tr("See \"Model Routing\" in the README for the rule format")
+		return check, config
+	}
+	if config.FFmpeg != nil {
+		if err := validateHWAccel(config.FFmpeg.HWAccel); err != nil {
+			check.Status, check.Detail = checkFailed, err.Error()
+			return check, config
+		}
+	}
+	if _, err := compileMacros(config.Macros); err != nil {
+		check.Status, check.Detail = checkFailed, err.Error()
+		return check, config
+	}
+
+	check.Detail = path
+	if !fileExists(path) {
+		check.Detail = tr("none yet, defaults are used")
+	}
+	return check, config
+}
+
+// checkTool reports whether an ffmpeg tool runs and which version it is. A
+// tool that can't run gets the missing status, a warning for optional tools.
+func checkTool(name, binary string, missing int, fix string) doctorCheck {
+	check := doctorCheck{Name: name}
+	output, err := exec.Command(binary, "-version").Output()
+	if err != nil {
+		check.Status, check.Detail, check.Fix = missing, fmt.Sprintf(tr("%s can't be run: %v"), binary, err), fix
+		return check
+	}
+	check.Detail, _, _ = strings.Cut(string(output), "\n")
+	return check
+}
+
+// checkNetwork connects to the API host
+func checkNetwork() doctorCheck {
+	check := doctorCheck{Name: tr("Network")}
+	conn, err := net.DialTimeout("tcp", "api.openai.com:443", 10*time.Second)
+	if err != nil {
+		check.Status, check.Detail = checkFailed, err.Error()
+		check.Fix = tr("Check your internet connection, proxy and firewall")
+		return check
+	}
+	conn.Close()
+	check.Detail = tr("api.openai.com is reachable")
+	return check
+}
+
+// checkAPIKey verifies the key by fetching a model, which costs nothing
+func checkAPIKey(ctx context.Context, apiKey string, account []option.RequestOption) doctorCheck {
+	check := doctorCheck{Name: tr("API key")}
+	if apiKey == "" {
+		check.Status, check.Detail = checkWarning, tr("no API key found in arguments, environment, or config file")
+		check.Fix = tr("Run \"pindar init\" to save a key, or set OPENAI_API_KEY")
+		return check
+	}
+
+	client, err := newClient(providerOpenAI, apiKey, account...)
+	if err == nil {
+		_, err = client.Models.Get(ctx, "whisper-1")
+	}
+	if err != nil {
+		check.Status, check.Detail = checkFailed, err.Error()
+		check.Fix = tr("Create a new key at https://platform.openai.com/api-keys")
+		return check
+	}
+	check.Detail = tr("valid, transcription models are available")
+	return check
+}
+
+// checkCorrectionsLog reads the corrections log that advise relies on
+func checkCorrectionsLog() doctorCheck {
+	check := doctorCheck{Name: tr("Corrections log")}
+	path, err := correctionsLogPath()
+	if err == nil {
+		var records []CorrectionRecord
+		records, err = loadCorrections(path)
+		if err == nil {
+			check.Detail = fmt.Sprintf(tr("%d transcripts recorded"), len(records))
+			return check
+		}
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		check.Detail = tr("none yet")
+		return check
+	}
+	check.Status, check.Detail = checkWarning, err.Error()
+	check.Fix = tr("Remove the invalid lines from the log, or delete it to start over")
+	return check
+}
+
+// checkDiskSpace checks that the temporary directory has room for converted audio
+func checkDiskSpace() doctorCheck {
+	check := doctorCheck{Name: tr("Disk space")}
+	dir := os.TempDir()
+	free, err := freeDiskSpace(dir)
+	if err != nil {
+		check.Status, check.Detail = checkWarning, err.Error()
+		return check
+	}
+	check.Detail = fmt.Sprintf(tr("%.1f GB free in %s"), float64(free)/1e9, dir)
+	if free < minFreeSpace {
+		check.Status = checkWarning
+		check.Fix = tr("Free up space or point TMPDIR at a larger disk; long recordings are converted there")
+	}
+	return check
+}
+
+// printCheck prints a check result with its fix
+func printCheck(check doctorCheck) {
+	icon := "✅"
+	switch check.Status {
+	case checkWarning:
+		icon = "⚠️ "
+	case checkFailed:
+		icon = "❌"
+	}
+	uiPrintf("%s %s: %s\n", icon, check.Name, check.Detail)
+	if check.Fix != "" {
+		uiPrintf("   💡 %s\n", check.Fix)
+	}
+}
+
+// runDoctor checks the setup pindar depends on and suggests fixes
+func runDoctor(argv []string) {
+	var args DoctorArgs
+	parseSubcommand("doctor", &args, argv)
+
+	configCheck, config := checkConfig()
+	ffmpeg := args.FFmpegPath
+	if ffmpeg == "" && config.FFmpeg != nil {
+		ffmpeg = config.FFmpeg.Path
+	}
+	configureFFmpeg(ffmpeg, nil) // only fails on hwaccel, which checkConfig reports
+
+	checks := []doctorCheck{
+		configCheck,
+		checkTool("ffmpeg", ffmpegBinary, checkFailed, tr("Install ffmpeg, run \"pindar deps install-ffmpeg\", or point --ffmpeg-path at it")),
+		checkTool("ffprobe", ffprobeBinary, checkWarning, tr("Install ffprobe (part of ffmpeg); without it tracks, chapters and durations can't be detected")),
+	}
+	if !args.Offline {
+		apiKey := args.APIKey
+		if apiKey == "" {
+			apiKey = config.OpenAIAPIKey
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		checks = append(checks, checkNetwork(), checkAPIKey(ctx, apiKey, accountOptions("", "", config)))
+	}
+	checks = append(checks, checkCorrectionsLog(), checkDiskSpace())
+
+	failed := 0
+	for _, check := range checks {
+		printCheck(check)
+		if check.Status == checkFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		uiPrintf(tr("\n%d of %d checks failed.\n"), failed, len(checks))
+		os.Exit(1)
+	}
+	uiPrintln(tr("\nEverything needed to transcribe is in place."))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// useConfigDir points the user config directory at a temporary directory
+func useConfigDir(t *testing.T) string {
+	if runtime.GOOS != "linux" {
+		t.Skip("the config directory is only relocatable with XDG_CONFIG_HOME")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	return filepath.Join(dir, "pindar")
+}
+
+func TestCheckConfig(t *testing.T) {
+	dir := useConfigDir(t)
+
+	if check, _ := checkConfig(); check.Status != checkOK {
+		t.Errorf("Expected a missing config to be fine, got %+v", check)
+	}
+
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"routing": [{"model": "whisper-1"}, {"max_duration": "soon", "model": "x"}]}`), 0600)
+	check, _ := checkConfig()
+	if check.Status != checkFailed || !strings.Contains(check.Detail, "routing rule 2") {
+		t.Errorf("Expected the unreachable invalid rule to be reported, got %+v", check)
+	}
+
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"ffmpeg": {"hwaccel": "magic"}}`), 0600)
+	if check, _ := checkConfig(); check.Status != checkFailed {
+		t.Errorf("Expected an unknown hwaccel to fail, got %+v", check)
+	}
+
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{`), 0600)
+	if check, _ := checkConfig(); check.Status != checkFailed || check.Fix == "" {
+		t.Errorf("Expected invalid JSON to fail with a fix, got %+v", check)
+	}
+}
+
+func TestCheckToolMissing(t *testing.T) {
+	check := checkTool("ffmpeg", filepath.Join(t.TempDir(), "ffmpeg"), checkFailed, "install it")
+	if check.Status != checkFailed || check.Fix != "install it" {
+		t.Errorf("Expected a failed check with the fix, got %+v", check)
+	}
+}
+
+func TestCheckAPIKeyMissing(t *testing.T) {
+	check := checkAPIKey(context.Background(), "", nil)
+	if check.Status != checkWarning || check.Fix == "" {
+		t.Errorf("Expected a warning with a fix, got %+v", check)
+	}
+}
+
+func TestCheckCorrectionsLog(t *testing.T) {
+	dir := useConfigDir(t)
+
+	if check := checkCorrectionsLog(); check.Status != checkOK {
+		t.Errorf("Expected a missing log to be fine, got %+v", check)
+	}
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "corrections.jsonl"), []byte("not json\n"), 0600)
+	if check := checkCorrectionsLog(); check.Status != checkWarning {
+		t.Errorf("Expected a broken log to be reported, got %+v", check)
+	}
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	check := checkDiskSpace()
+	if check.Status == checkFailed || !strings.Contains(check.Detail, "GB") {
+		t.Errorf("Expected the free space of the temporary directory, got %+v", check)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// entityTypes are the kinds of entities extracted with --entities
+var entityTypes = []string{"person", "organization", "place", "date"}
+
+// Entity is a person, organization, place or date mentioned in the transcript
+type Entity struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text"`
+	Mentions []Mention `json:"mentions"`
+}
+
+// Mention is the time span of the segment an entity is mentioned in
+type Mention struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// foundEntity is an entity as returned by the analysis model, with segment numbers
+type foundEntity struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Segments []int  `json:"segments"`
+}
+
+// numberedSegments formats segments as "[index] text" lines for the analysis model
+func numberedSegments(segments []Segment) string {
+	var b strings.Builder
+	for i, segment := range segments {
+		fmt.Fprintf(&b, "[%d] %s\n", i, strings.TrimSpace(segment.Text))
+	}
+	return b.String()
+}
+
+// extractEntities asks the analysis model for the entities mentioned in the transcript
+func extractEntities(ctx context.Context, client openai.Client, model string, transcript *Transcript) ([]Entity, error) {
+	instructions := `You extract named entities from transcripts. The user sends numbered transcript segments. ` +
+		`Return JSON of the form {"entities": [{"type": ..., "text": ..., "segments": [...]}]} with one entry per ` +
+		`distinct entity. "type" is one of ` + strings.Join(entityTypes, ", ") + `, "text" is the entity as written ` +
+		`in the transcript and "segments" lists the numbers of all segments mentioning it.`
+
+	var answer struct {
+		Entities []foundEntity `json:"entities"`
+	}
+	if err := chatJSON(ctx, client, model, instructions, numberedSegments(transcript.Segments), &answer); err != nil {
+		return nil, err
+	}
+	return resolveEntities(answer.Entities, transcript.Segments), nil
+}
+
+// resolveEntities turns the segment numbers of the model's answer into
+// timestamps, dropping unknown types and segment numbers
+func resolveEntities(found []foundEntity, segments []Segment) []Entity {
+	entities := []Entity{}
+	for _, f := range found {
+		entityType := strings.ToLower(strings.TrimSpace(f.Type))
+		text := strings.TrimSpace(f.Text)
+		if !slices.Contains(entityTypes, entityType) || text == "" {
+			continue
+		}
+
+		entity := Entity{Type: entityType, Text: text, Mentions: []Mention{}}
+		indexes := slices.Clone(f.Segments)
+		slices.Sort(indexes)
+		for _, i := range slices.Compact(indexes) {
+			if i >= 0 && i < len(segments) {
+				entity.Mentions = append(entity.Mentions, Mention{Start: segments[i].Start, End: segments[i].End})
+			}
+		}
+		entities = append(entities, entity)
+	}
+	return entities
+}
+
+// saveEntities extracts the entities of the transcript and writes them to a JSON sidecar file
+func saveEntities(ctx context.Context, client openai.Client, args Args, originalFile string, transcript *Transcript) error {
+	uiPrintf(tr(" Extracting entities with %s...\n"), args.AnalysisModel)
+	entities, err := extractEntities(ctx, client, args.AnalysisModel, transcript)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Entities []Entity `json:"entities"`
+	}{entities}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal entities: %w", err)
+	}
+
+	entitiesFile := sidecarFileName(args, originalFile, ".entities.json")
+	if err := os.WriteFile(entitiesFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write entities file: %w", err)
+	}
+	uiPrintf(tr("💾 %d entities saved to: %s\n"), len(entities), entitiesFile)
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveEntities(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 4.5, Text: " Anna from Acme called."},
+		{Start: 4.5, End: 9, Text: " She is in Berlin on Monday."},
+	}
+
+	found := []foundEntity{
+		{Type: "Person", Text: "Anna", Segments: []int{1, 0, 0}},
+		{Type: "place", Text: "Berlin", Segments: []int{1, 7}},
+		{Type: "product", Text: "Widget", Segments: []int{0}},
+	}
+
+	expected := []Entity{
+		{Type: "person", Text: "Anna", Mentions: []Mention{{0, 4.5}, {4.5, 9}}},
+		{Type: "place", Text: "Berlin", Mentions: []Mention{{4.5, 9}}},
+	}
+	result := resolveEntities(found, segments)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestNumberedSegments(t *testing.T) {
+	expected := "[0] Hello.\n[1] World.\n"
+	result := numberedSegments([]Segment{{Text: " Hello."}, {Text: " World. "}})
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// epubParagraphLength is the length after which a paragraph of an e-book ends
+// at the next sentence boundary. Transcripts have no paragraphs of their own.
+const epubParagraphLength = 600
+
+// epubChapter is a chapter of an e-book with its text split into paragraphs
+type epubChapter struct {
+	Title      string
+	Paragraphs []string
+}
+
+// epubParagraphs splits transcript text into paragraphs of about
+// epubParagraphLength characters, breaking only after a sentence
+func epubParagraphs(text string) []string {
+	var paragraphs []string
+	var current []string
+	length := 0
+	for _, word := range strings.Fields(text) {
+		current = append(current, word)
+		length += len(word) + 1
+		if length >= epubParagraphLength && endsSentence(word) {
+			paragraphs = append(paragraphs, strings.Join(current, " "))
+			current, length = nil, 0
+		}
+	}
+	if len(current) > 0 {
+		paragraphs = append(paragraphs, strings.Join(current, " "))
+	}
+	return paragraphs
+}
+
+// epubChapters returns a chapter for every transcribed chapter, or a single
+// chapter named title when the input had none
+func epubChapters(title string, transcript *Transcript, chapters []chapter, chapterTranscripts []*Transcript) []epubChapter {
+	if len(chapterTranscripts) == 0 {
+		return []epubChapter{{Title: title, Paragraphs: epubParagraphs(transcript.Text)}}
+	}
+	result := make([]epubChapter, len(chapterTranscripts))
+	for i, chapterTranscript := range chapterTranscripts {
+		result[i] = epubChapter{Title: chapters[i].Title, Paragraphs: epubParagraphs(chapterTranscript.Text)}
+	}
+	return result
+}
+
+// epubFile is a file inside the e-book archive
+type epubFile struct {
+	Name    string
+	Content string
+}
+
+// escapeXML escapes text for XML content and attribute values
+func escapeXML(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// renderEPUB produces an EPUB 3 e-book with one XHTML file per chapter and a
+// table of contents. The identifier is derived from the title, so transcribing
+// a book again replaces it in reading apps instead of adding a copy.
+func renderEPUB(title, language string, chapters []epubChapter, modified time.Time) (string, error) {
+	lang, err := normalizeLanguage(language)
+	if err != nil || lang == "" {
+		lang = "und"
+	}
+	sum := sha256.Sum256([]byte(title))
+	identifier := fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+	modified = modified.UTC().Truncate(time.Second)
+
+	files := []epubFile{
+		{"META-INF/container.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`},
+	}
+
+	var manifest, spine, toc strings.Builder
+	for i, c := range chapters {
+		name := fmt.Sprintf("chapter%03d.xhtml", i+1)
+		fmt.Fprintf(&manifest, `    <item id="chapter%03d" href="%s" media-type="application/xhtml+xml"/>`+"\n", i+1, name)
+		fmt.Fprintf(&spine, `    <itemref idref="chapter%03d"/>`+"\n", i+1)
+		fmt.Fprintf(&toc, `      <li><a href="%s">%s</a></li>`+"\n", name, escapeXML(c.Title))
+
+		var body strings.Builder
+		fmt.Fprintf(&body, "<h1>%s</h1>\n", escapeXML(c.Title))
+		for _, paragraph := range c.Paragraphs {
+			fmt.Fprintf(&body, "<p>%s</p>\n", escapeXML(paragraph))
+		}
+		files = append(files, epubFile{"OEBPS/" + name, epubXHTML(c.Title, lang, body.String())})
+	}
+
+	files = append(files,
+		epubFile{"OEBPS/nav.xhtml", epubXHTML(title, lang,
+			"<nav epub:type=\"toc\" id=\"toc\">\n  <h1>"+escapeXML(title)+"</h1>\n  <ol>\n"+toc.String()+"  </ol>\n</nav>\n")},
+		epubFile{"OEBPS/content.opf", fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="%s">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">%s</dc:identifier>
+    <dc:title>%s</dc:title>
+    <dc:language>%s</dc:language>
+    <meta property="dcterms:modified">%s</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+%s  </manifest>
+  <spine>
+%s  </spine>
+</package>
+`, lang, identifier, escapeXML(title), lang, modified.Format(time.RFC3339), manifest.String(), spine.String())},
+	)
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	// The mimetype must come first and uncompressed so readers can identify the file
+	mimetype, err := w.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store, Modified: modified})
+	if err != nil {
+		return "", fmt.Errorf("failed to create epub: %w", err)
+	}
+	mimetype.Write([]byte("application/epub+zip"))
+	for _, file := range files {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: file.Name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return "", fmt.Errorf("failed to create epub: %w", err)
+		}
+		f.Write([]byte(file.Content))
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to create epub: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// epubXHTML wraps a body in an XHTML document of an e-book
+func epubXHTML(title, lang, body string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%s" lang="%s">
+<head>
+  <title>%s</title>
+</head>
+<body>
+%s</body>
+</html>
+`, lang, lang, escapeXML(title), body)
+}
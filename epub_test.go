@@ -0,0 +1,91 @@
+package main
+
+import (
+	"archive/zip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEPUBParagraphs(t *testing.T) {
+	sentence := strings.Repeat("word ", 30) + "end."
+	paragraphs := epubParagraphs(strings.Repeat(sentence+" ", 5) + " Short tail")
+	if len(paragraphs) != 2 {
+		t.Fatalf("Expected 2 paragraphs, got %d: %q", len(paragraphs), paragraphs)
+	}
+	if !strings.HasSuffix(paragraphs[0], "end.") || paragraphs[1] != sentence+" Short tail" {
+		t.Errorf("Expected paragraphs to break after a sentence, got %q", paragraphs)
+	}
+	if len(epubParagraphs("  ")) != 0 {
+		t.Error("Expected no paragraphs for empty text")
+	}
+}
+
+func TestEPUBChapters(t *testing.T) {
+	chapters := []chapter{{Title: "Opening", Start: 0, End: 10}, {Title: "Finale", Start: 10, End: 20}}
+	transcripts := []*Transcript{{Text: "First."}, {Text: "Second."}}
+
+	book := epubChapters("Book", combineChapterTranscripts(chapters, transcripts), chapters, transcripts)
+	if len(book) != 2 || book[1].Title != "Finale" || book[1].Paragraphs[0] != "Second." {
+		t.Errorf("Expected one chapter per transcribed chapter, got %+v", book)
+	}
+
+	book = epubChapters("Book", &Transcript{Text: "Whole."}, nil, nil)
+	if len(book) != 1 || book[0].Title != "Book" || book[0].Paragraphs[0] != "Whole." {
+		t.Errorf("Expected a single chapter named after the book, got %+v", book)
+	}
+}
+
+func TestRenderEPUB(t *testing.T) {
+	chapters := []epubChapter{
+		{Title: "Tom & Jerry", Paragraphs: []string{"A <b> tag.", "Second paragraph."}},
+		{Title: "Two", Paragraphs: []string{"More."}},
+	}
+	data, err := renderEPUB("My Book", "german", chapters, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("renderEPUB() failed: %v", err)
+	}
+
+	archive, err := zip.NewReader(strings.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Expected a zip archive: %v", err)
+	}
+	if first := archive.File[0]; first.Name != "mimetype" || first.Method != zip.Store {
+		t.Errorf("Expected an uncompressed mimetype first, got %s (method %d)", first.Name, first.Method)
+	}
+
+	files := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(content)
+	}
+
+	if files["mimetype"] != "application/epub+zip" {
+		t.Errorf("Unexpected mimetype %q", files["mimetype"])
+	}
+	for name, expected := range map[string]string{
+		"META-INF/container.xml": `full-path="OEBPS/content.opf"`,
+		"OEBPS/content.opf":      `<dc:language>de</dc:language>`,
+		"OEBPS/nav.xhtml":        `<li><a href="chapter001.xhtml">Tom &amp; Jerry</a></li>`,
+		"OEBPS/chapter001.xhtml": `<p>A &lt;b&gt; tag.</p>`,
+		"OEBPS/chapter002.xhtml": `<h1>Two</h1>`,
+	} {
+		if !strings.Contains(files[name], expected) {
+			t.Errorf("Expected %q in %s, got %q", expected, name, files[name])
+		}
+	}
+	if !strings.Contains(files["OEBPS/content.opf"], `<meta property="dcterms:modified">2024-05-01T12:00:00Z</meta>`) {
+		t.Errorf("Expected the modification date in the package, got %q", files["OEBPS/content.opf"])
+	}
+
+	again, _ := renderEPUB("My Book", "german", chapters, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	if again != data {
+		t.Error("Expected the same book for the same input")
+	}
+}
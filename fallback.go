@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// whisperFallbackTemperatures is the ladder of the reference implementation,
+// selected with --temperature-fallback whisper
+var whisperFallbackTemperatures = []float64{0.2, 0.4, 0.6, 0.8, 1.0}
+
+// parseFallbackTemperatures parses the temperatures of --temperature-fallback
+func parseFallbackTemperatures(list string) ([]float64, error) {
+	if strings.EqualFold(strings.TrimSpace(list), "whisper") {
+		return whisperFallbackTemperatures, nil
+	}
+	var temperatures []float64
+	for _, field := range strings.Split(list, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		temperature, err := strconv.ParseFloat(field, 64)
+		if err != nil || temperature < 0 || temperature > 1 {
+			return nil, fmt.Errorf(tr("invalid --temperature-fallback temperature %q, use numbers between 0 and 1 or whisper"), field)
+		}
+		temperatures = append(temperatures, temperature)
+	}
+	return temperatures, nil
+}
+
+// textCompressionRatio returns how well a text compresses with zlib, as the
+// reference implementation measures it. Text repeating itself compresses
+// unusually well.
+func textCompressionRatio(text string) float64 {
+	if text == "" {
+		return 0
+	}
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write([]byte(text))
+	w.Close()
+	return float64(len(text)) / float64(b.Len())
+}
+
+// compressionWindowWords is about the number of words spoken in the 30
+// seconds of audio the compression ratio threshold is calibrated for
+const compressionWindowWords = 80
+
+// compressionRatio returns the highest compression ratio of the transcript's
+// 30-second windows. The threshold only means something for windows of that
+// length: over a whole transcript, ordinary prose compresses better than it
+// after a few thousand words. whisper-1 reports the ratio of every segment;
+// text without it is measured in chunks of about as many words.
+func compressionRatio(transcript *Transcript) float64 {
+	highest := 0.0
+	for _, segment := range transcript.Segments {
+		highest = max(highest, segment.CompressionRatio)
+	}
+	if highest > 0 {
+		return highest
+	}
+	words := strings.Fields(transcript.Text)
+	for start := 0; start < len(words); start += compressionWindowWords {
+		window := strings.Join(words[start:min(start+compressionWindowWords, len(words))], " ")
+		highest = max(highest, textCompressionRatio(window))
+	}
+	return highest
+}
+
+// noSpeechProb returns the mean no-speech probability of the segments
+func noSpeechProb(transcript *Transcript) float64 {
+	if len(transcript.Segments) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range transcript.Segments {
+		sum += s.NoSpeechProb
+	}
+	return sum / float64(len(transcript.Segments))
+}
+
+// fallbackReason returns why a result is unreliable enough to transcribe
+// again at a higher temperature, or an empty string. Like the reference
+// implementation, low confidence is accepted for audio that is probably
+// silence, since there is nothing better to find.
+func fallbackReason(transcript *Transcript) string {
+	if ratio := compressionRatio(transcript); ratio > maxCompressionRatio {
+		return fmt.Sprintf(tr("compression ratio %.1f"), ratio)
+	}
+	if score, ok := transcriptScore(transcript); ok && score < lowLogprobThreshold && noSpeechProb(transcript) <= noSpeechThreshold {
+		return fmt.Sprintf(tr("avg logprob %.2f"), score)
+	}
+	return ""
+}
+
+// transcribeWithFallback transcribes a file and, while the result looks
+// unreliable, transcribes it again at the next --temperature-fallback
+// temperature above --temperature. If even the last one doesn't help, its
+// result is kept, as in the reference implementation.
+func transcribeWithFallback(ctx context.Context, client openai.Client, args Args, path, uploadName string) (*Transcript, error) {
+	transcript, err := transcribeBestOf(ctx, client, args, path, uploadName)
+	if err != nil || args.Fallback == "" {
+		return transcript, err
+	}
+	temperatures, err := parseFallbackTemperatures(args.Fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, temperature := range temperatures {
+		if temperature <= args.Temperature {
+			continue
+		}
+		reason := fallbackReason(transcript)
+		if reason == "" {
+			return transcript, nil
+		}
+		uiPrintf(tr(" The result looks unreliable (%s), transcribing again at temperature %.1f\n"), reason, temperature)
+
+		runArgs := args
+		runArgs.Temperature = temperature
+		if transcript, err = transcribeBestOf(ctx, client, runArgs, path, uploadName); err != nil {
+			return nil, err
+		}
+	}
+	if reason := fallbackReason(transcript); reason != "" {
+		uiPrintf(tr("⚠️  The result still looks unreliable (%s) at the highest fallback temperature\n"), reason)
+	}
+	return transcript, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseFallbackTemperatures(t *testing.T) {
+	if temperatures, err := parseFallbackTemperatures("whisper"); err != nil || !slices.Equal(temperatures, whisperFallbackTemperatures) {
+		t.Errorf("Expected the whisper ladder, got %v (%v)", temperatures, err)
+	}
+	if temperatures, err := parseFallbackTemperatures(" 0.5, 1,"); err != nil || !slices.Equal(temperatures, []float64{0.5, 1}) {
+		t.Errorf("Expected 0.5 and 1, got %v (%v)", temperatures, err)
+	}
+	if temperatures, err := parseFallbackTemperatures(""); err != nil || len(temperatures) != 0 {
+		t.Errorf("Expected no temperatures, got %v (%v)", temperatures, err)
+	}
+	for _, list := range []string{"0.2,hot", "1.5", "-0.2"} {
+		if _, err := parseFallbackTemperatures(list); err == nil {
+			t.Errorf("Expected an error for %q", list)
+		}
+	}
+}
+
+func TestTextCompressionRatio(t *testing.T) {
+	loop := strings.Repeat("I'm going to go. ", 30)
+	if ratio := textCompressionRatio(loop); ratio <= maxCompressionRatio {
+		t.Errorf("Expected a loop to compress well, got %.2f", ratio)
+	}
+	speech := "Welcome back to the show. Today we talk about sourdough, why it rises and what can go wrong."
+	if ratio := textCompressionRatio(speech); ratio > maxCompressionRatio {
+		t.Errorf("Expected speech to compress normally, got %.2f", ratio)
+	}
+	if textCompressionRatio("") != 0 {
+		t.Error("Expected 0 for empty text")
+	}
+}
+
+func TestFallbackReason(t *testing.T) {
+	confident := &Transcript{Text: "Hello there.", Segments: []Segment{{Start: 0, End: 2, AvgLogprob: -0.3, NoSpeechProb: 0.1}}}
+	if reason := fallbackReason(confident); reason != "" {
+		t.Errorf("Expected a confident result to be accepted, got %q", reason)
+	}
+
+	unsure := &Transcript{Text: "Hello there.", Segments: []Segment{{Start: 0, End: 2, AvgLogprob: -1.4, NoSpeechProb: 0.1}}}
+	if reason := fallbackReason(unsure); !strings.Contains(reason, "-1.40") {
+		t.Errorf("Expected the logprob as reason, got %q", reason)
+	}
+
+	silence := &Transcript{Text: "Hmm.", Segments: []Segment{{Start: 0, End: 2, AvgLogprob: -1.4, NoSpeechProb: 0.9}}}
+	if reason := fallbackReason(silence); reason != "" {
+		t.Errorf("Expected low confidence on silence to be accepted, got %q", reason)
+	}
+
+	looping := &Transcript{Text: strings.Repeat("Thank you. ", 40)}
+	if reason := fallbackReason(looping); !strings.HasPrefix(reason, "compression ratio") {
+		t.Errorf("Expected the compression ratio as reason, got %q", reason)
+	}
+}
+
+func TestCompressionRatio(t *testing.T) {
+	// Long speech compresses well as a whole, but not window by window
+	vocabulary := strings.Fields("we the a to and of in that it is was for on you with as they at be this have from or one had by word but not what all were when your can said there use an each which she do how their if will up other about out many then them these so some her would make like him into time has look two more write go see number no way could people my than first water been call who oil its now find long down day did get come made may part")
+	random := rand.New(rand.NewPCG(1, 2))
+	words := make([]string, 5000)
+	for i := range words {
+		words[i] = vocabulary[random.IntN(len(vocabulary))]
+	}
+	speech := &Transcript{Text: strings.Join(words, " ")}
+	if textCompressionRatio(speech.Text) <= maxCompressionRatio {
+		t.Fatal("Expected the whole text to pass the threshold")
+	}
+	if ratio := compressionRatio(speech); ratio > maxCompressionRatio {
+		t.Errorf("Expected speech to compress normally in windows, got %.2f", ratio)
+	}
+
+	reported := &Transcript{Text: speech.Text, Segments: []Segment{{CompressionRatio: 1.2}, {CompressionRatio: 2.7}}}
+	if ratio := compressionRatio(reported); ratio != 2.7 {
+		t.Errorf("Expected the highest ratio of the segments, got %.2f", ratio)
+	}
+}
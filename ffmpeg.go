@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FFmpegConfig selects the ffmpeg binary and its hardware acceleration in the config file
+type FFmpegConfig struct {
+	Path string `json:"path,omitempty"`
+	// HWAccel is passed to ffmpeg as -hwaccel, e.g. videotoolbox, vaapi or cuda
+	HWAccel       string `json:"hwaccel,omitempty"`
+	HWAccelDevice string `json:"hwaccel_device,omitempty"`
+}
+
+// hwaccelMethods are the ffmpeg -hwaccel methods accepted in the config
+var hwaccelMethods = []string{"auto", "videotoolbox", "vaapi", "cuda", "qsv", "d3d11va", "dxva2", "vdpau"}
+
+// The ffmpeg and ffprobe binaries and the options placed before every ffmpeg
+// input, set once by configureFFmpeg
+var (
+	ffmpegBinary      = "ffmpeg"
+	ffprobeBinary     = "ffprobe"
+	ffmpegInputOption []string
+)
+
+// configureFFmpeg selects the binaries and hardware acceleration. A path given
+// on the command line takes precedence over the config file, and the ffmpeg
+// from "pindar deps install-ffmpeg" is used when there is none in PATH.
+func configureFFmpeg(path string, config *FFmpegConfig) error {
+	if config == nil {
+		config = &FFmpegConfig{}
+	}
+	if path == "" {
+		path = config.Path
+	}
+	if path == "" {
+		if _, err := exec.LookPath("ffmpeg"); err != nil {
+			if installed, err := installedFFmpegPath(); err == nil && fileExists(installed) {
+				path = installed
+			}
+		}
+	}
+
+	if path != "" {
+		ffmpegBinary = path
+		ffprobeBinary = siblingFFprobe(path)
+	}
+
+	ffmpegInputOption = nil
+	if err := validateHWAccel(config.HWAccel); err != nil {
+		return err
+	}
+	if config.HWAccel != "" {
+		ffmpegInputOption = append(ffmpegInputOption, "-hwaccel", config.HWAccel)
+		if config.HWAccelDevice != "" {
+			ffmpegInputOption = append(ffmpegInputOption, "-hwaccel_device", config.HWAccelDevice)
+		}
+	}
+	return nil
+}
+
+// validateHWAccel checks a -hwaccel method from the config; empty disables it
+func validateHWAccel(method string) error {
+	if method != "" && !slices.Contains(hwaccelMethods, method) {
+		return fmt.Errorf(tr("unknown hwaccel %q, use one of: %s"), method, strings.Join(hwaccelMethods, ", "))
+	}
+	return nil
+}
+
+// siblingFFprobe returns the ffprobe next to an ffmpeg binary, falling back
+// to the one in PATH for builds that don't ship ffprobe
+func siblingFFprobe(ffmpeg string) string {
+	if !strings.ContainsRune(ffmpeg, os.PathSeparator) && !strings.ContainsRune(ffmpeg, '/') {
+		return "ffprobe"
+	}
+	probe := filepath.Join(filepath.Dir(ffmpeg), "ffprobe"+filepath.Ext(ffmpeg))
+	if !fileExists(probe) {
+		return "ffprobe"
+	}
+	return probe
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// ffmpegCommand builds an ffmpeg command reading input, with the configured
+// input options before it and the output options after it
+func ffmpegCommand(before []string, input string, after ...string) (*exec.Cmd, error) {
+	if err := findFFmpeg(); err != nil {
+		return nil, err
+	}
+	args := append(slices.Clone(before), ffmpegInputOption...)
+	args = append(args, "-i", ffmpegPath(input))
+	return exec.Command(ffmpegBinary, append(args, after...)...), nil
+}
+
+// ffmpegDeviceCommand builds an ffmpeg command recording from a capture device
+// of the given input format (pulse, avfoundation, dshow) instead of a file
+func ffmpegDeviceCommand(format, device string, after ...string) (*exec.Cmd, error) {
+	if err := findFFmpeg(); err != nil {
+		return nil, err
+	}
+	args := []string{"-hide_banner", "-f", format, "-i", device}
+	return exec.Command(ffmpegBinary, append(args, after...)...), nil
+}
+
+// findFFmpeg checks that the configured ffmpeg binary can be found
+func findFFmpeg() error {
+	if _, err := exec.LookPath(ffmpegBinary); err != nil {
+		return fmt.Errorf("ffmpeg not found (%s): install ffmpeg, run \"pindar deps install-ffmpeg\", or point --ffmpeg-path at it", ffmpegBinary)
+	}
+	return nil
+}
+
+// ffprobeCommand builds an ffprobe command inspecting input
+func ffprobeCommand(input string, options ...string) (*exec.Cmd, error) {
+	if _, err := exec.LookPath(ffprobeBinary); err != nil {
+		return nil, fmt.Errorf("ffprobe was not found (%s)", ffprobeBinary)
+	}
+	return exec.Command(ffprobeBinary, append(options, ffmpegPath(input))...), nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexflint/go-arg"
+)
+
+// resetFFmpeg restores the default binaries after a test configures them
+func resetFFmpeg(t *testing.T) {
+	t.Cleanup(func() {
+		ffmpegBinary, ffprobeBinary, ffmpegInputOption = "ffmpeg", "ffprobe", nil
+	})
+}
+
+func TestConfigureFFmpegPath(t *testing.T) {
+	resetFFmpeg(t)
+	dir := t.TempDir()
+	ffmpeg := filepath.Join(dir, "ffmpeg")
+	if err := os.WriteFile(filepath.Join(dir, "ffprobe"), nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := configureFFmpeg("", &FFmpegConfig{Path: "/opt/config/ffmpeg"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ffmpegBinary != "/opt/config/ffmpeg" || ffprobeBinary != "ffprobe" {
+		t.Errorf("Expected the config path and ffprobe from PATH, got %q and %q", ffmpegBinary, ffprobeBinary)
+	}
+
+	if err := configureFFmpeg(ffmpeg, &FFmpegConfig{Path: "/opt/config/ffmpeg"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ffmpegBinary != ffmpeg || ffprobeBinary != filepath.Join(dir, "ffprobe") {
+		t.Errorf("Expected --ffmpeg-path to win with its sibling ffprobe, got %q and %q", ffmpegBinary, ffprobeBinary)
+	}
+}
+
+func TestConfigureFFmpegHWAccel(t *testing.T) {
+	resetFFmpeg(t)
+
+	if err := configureFFmpeg("", &FFmpegConfig{HWAccel: "vaapi", HWAccelDevice: "/dev/dri/renderD128"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"-hwaccel", "vaapi", "-hwaccel_device", "/dev/dri/renderD128"}
+	if !slices.Equal(ffmpegInputOption, expected) {
+		t.Errorf("Expected %v, got %v", expected, ffmpegInputOption)
+	}
+
+	if err := configureFFmpeg("", &FFmpegConfig{HWAccel: "warp-drive"}); err == nil {
+		t.Error("Expected an error for an unknown hwaccel method")
+	}
+	if err := configureFFmpeg("", nil); err != nil || ffmpegInputOption != nil {
+		t.Errorf("Expected no input options without config, got %v, %v", ffmpegInputOption, err)
+	}
+}
+
+func TestFFmpegCommandArguments(t *testing.T) {
+	resetFFmpeg(t)
+	ffmpegBinary = os.Args[0] // any existing executable
+	ffmpegInputOption = []string{"-hwaccel", "videotoolbox"}
+
+	cmd, err := ffmpegCommand([]string{"-ss", "1.000"}, "in:put.mkv", "-vn", "out.mp4")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"-ss", "1.000", "-hwaccel", "videotoolbox", "-i", "file:in:put.mkv", "-vn", "out.mp4"}
+	if !slices.Equal(cmd.Args[1:], expected) {
+		t.Errorf("Expected %v, got %v", expected, cmd.Args[1:])
+	}
+}
+
+func TestFFmpegCommandMissingBinary(t *testing.T) {
+	resetFFmpeg(t)
+	if err := configureFFmpeg(filepath.Join(t.TempDir(), "no-ffmpeg"), nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := ffmpegCommand(nil, "in.mkv"); err == nil || !strings.Contains(err.Error(), "--ffmpeg-path") {
+		t.Errorf("Expected a hint at --ffmpeg-path, got %v", err)
+	}
+}
+
+func TestFFmpegPathFromEnvironment(t *testing.T) {
+	t.Setenv("PINDAR_FFMPEG", "/opt/ffmpeg/bin/ffmpeg")
+
+	var args Args
+	var doctor DoctorArgs
+	var listen ListenArgs
+	for _, dest := range []struct {
+		args any
+		argv []string
+		path *string
+	}{
+		{&args, []string{"talk.mp3"}, &args.FFmpegPath},
+		{&doctor, nil, &doctor.FFmpegPath},
+		{&listen, nil, &listen.FFmpegPath},
+	} {
+		parser, err := arg.NewParser(arg.Config{}, dest.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := parser.Parse(dest.argv); err != nil || *dest.path != "/opt/ffmpeg/bin/ffmpeg" {
+			t.Errorf("Expected PINDAR_FFMPEG to set --ffmpeg-path of %T, got %q (%v)", dest.args, *dest.path, err)
+		}
+	}
+}
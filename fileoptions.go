@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileOptionsSuffix is appended to an audio file's name to find its options file
+const fileOptionsSuffix = ".pindar.yaml"
+
+// FileOptions are the settings of one recording, read from a sidecar file
+// like recording.mp3.pindar.yaml. They override the command line options.
+type FileOptions struct {
+	Language string   `yaml:"language"`
+	Prompt   string   `yaml:"prompt"`
+	Speakers []string `yaml:"speakers"`
+}
+
+// loadFileOptions reads the options file of an audio file. It returns nil
+// without error if the file has none.
+func loadFileOptions(audioFile string) (*FileOptions, string, error) {
+	path := audioFile + fileOptionsSuffix
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, path, nil
+	}
+	if err != nil {
+		return nil, path, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var options FileOptions
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&options); err != nil && !errors.Is(err, io.EOF) {
+		return nil, path, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &options, path, nil
+}
+
+// applyFileOptions overrides the arguments with the options set in the file
+func applyFileOptions(args *Args, options FileOptions) {
+	if options.Language != "" {
+		args.Language = options.Language
+	}
+	if options.Prompt != "" {
+		args.Prompt = options.Prompt
+	}
+	if len(options.Speakers) > 0 {
+		args.Speakers = options.Speakers
+	}
+}
+
+// promptWithSpeakers appends the speaker names to the prompt, so the model
+// spells them as given. They go last because trimPrompt keeps the end.
+func promptWithSpeakers(prompt string, speakers []string) string {
+	if len(speakers) == 0 {
+		return prompt
+	}
+	names := "Speakers: " + strings.Join(speakers, ", ") + "."
+	if prompt == "" {
+		return names
+	}
+	return strings.TrimSpace(prompt) + " " + names
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadFileOptions(t *testing.T) {
+	audio := filepath.Join(t.TempDir(), "recording.mp3")
+
+	options, _, err := loadFileOptions(audio)
+	if err != nil || options != nil {
+		t.Fatalf("Expected no options without a sidecar, got %+v, %v", options, err)
+	}
+
+	yaml := "language: german\nprompt: Ein Gespräch über Lyrik\nspeakers:\n  - Aoife\n  - Siobhán\n"
+	if err := os.WriteFile(audio+".pindar.yaml", []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	options, path, err := loadFileOptions(audio)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path != audio+".pindar.yaml" || options.Language != "german" || options.Prompt != "Ein Gespräch über Lyrik" || !slices.Equal(options.Speakers, []string{"Aoife", "Siobhán"}) {
+		t.Errorf("Unexpected options %+v from %s", options, path)
+	}
+
+	// A typo must not be silently ignored
+	if err := os.WriteFile(audio+".pindar.yaml", []byte("langauge: de\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := loadFileOptions(audio); err == nil {
+		t.Error("Expected an error for an unknown option")
+	}
+
+	if err := os.WriteFile(audio+".pindar.yaml", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if options, _, err := loadFileOptions(audio); err != nil || options == nil {
+		t.Errorf("Expected empty options for an empty sidecar, got %+v, %v", options, err)
+	}
+}
+
+func TestApplyFileOptions(t *testing.T) {
+	args := Args{Language: "en", Prompt: "Podcast", Speakers: []string{"Host"}}
+	applyFileOptions(&args, FileOptions{Language: "de"})
+	if args.Language != "de" || args.Prompt != "Podcast" || !slices.Equal(args.Speakers, []string{"Host"}) {
+		t.Errorf("Expected only the language to change, got %+v", args)
+	}
+	applyFileOptions(&args, FileOptions{Prompt: "Lyrik", Speakers: []string{"Aoife"}})
+	if args.Prompt != "Lyrik" || !slices.Equal(args.Speakers, []string{"Aoife"}) {
+		t.Errorf("Expected the prompt and speakers to change, got %+v", args)
+	}
+}
+
+func TestPromptWithSpeakers(t *testing.T) {
+	tests := []struct {
+		prompt   string
+		speakers []string
+		expected string
+	}{
+		{"A talk about poetry.", nil, "A talk about poetry."},
+		{"", []string{"Aoife", "Siobhán"}, "Speakers: Aoife, Siobhán."},
+		{"A talk about poetry. ", []string{"Aoife"}, "A talk about poetry. Speakers: Aoife."},
+	}
+	for _, test := range tests {
+		if got := promptWithSpeakers(test.prompt, test.speakers); got != test.expected {
+			t.Errorf("promptWithSpeakers(%q, %v) = %q, expected %q", test.prompt, test.speakers, got, test.expected)
+		}
+	}
+}
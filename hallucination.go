@@ -0,0 +1,323 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/openai/openai-go"
+)
+
+// Modes of --hallucinations
+const (
+	hallucinationsOff   = "off"
+	hallucinationsFlag  = "flag"
+	hallucinationsStrip = "strip"
+)
+
+// Reasons a segment is taken for a hallucination, as written to verbose_json
+const (
+	hallucinationRepetition = "repetition"
+	hallucinationNoSpeech   = "no_speech"
+	hallucinationKnown      = "known_phrase"
+)
+
+// Whisper's own thresholds: a segment that is probably silence and was
+// decoded with low confidence, or compresses so well it must repeat itself
+const (
+	noSpeechThreshold   = 0.6
+	lowLogprobThreshold = -1.0
+	maxCompressionRatio = 2.4
+)
+
+// loopMinRepeats and loopMinWords define a repetition loop: a phrase said at
+// least 3 times in a row, making up at least 8 words, so "no, no, no" isn't one
+const (
+	loopMinRepeats = 3
+	loopMinWords   = 8
+)
+
+// knownHallucinations are phrases the models write for silence and noise,
+// learned from the video subtitles they were trained on. They are compared
+// in lower case without the punctuation around them.
+var knownHallucinations = []string{
+	"thanks for watching",
+	"thank you for watching",
+	"thank you so much for watching",
+	"please subscribe",
+	"like and subscribe",
+	"subtitles by the amara.org community",
+	"untertitel im auftrag des zdf",
+	"untertitelung des zdf",
+	"untertitel der amara.org-community",
+	"vielen dank fürs zuschauen",
+	"sous-titres réalisés par la communauté d'amara.org",
+	"merci d'avoir regardé cette vidéo",
+	"subtítulos realizados por la comunidad de amara.org",
+	"gracias por ver el video",
+	"sottotitoli creati dalla comunità amara.org",
+}
+
+// normalizeHallucinationText lowercases text and trims the punctuation and
+// spaces around it
+func normalizeHallucinationText(text string) string {
+	return strings.TrimFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// isKnownHallucination reports whether text is one of the known phrases,
+// possibly followed by something like a year
+func isKnownHallucination(text string) bool {
+	normalized := normalizeHallucinationText(text)
+	for _, phrase := range knownHallucinations {
+		if rest, ok := strings.CutPrefix(normalized, phrase); ok && strings.IndexFunc(rest, unicode.IsLetter) == -1 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRepetitionLoop reports whether text repeats a phrase the way a model
+// stuck in a loop does
+func hasRepetitionLoop(text string) bool {
+	var words []string
+	for _, word := range strings.Fields(text) {
+		if word = normalizeHallucinationText(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	for n := 1; n*loopMinRepeats <= len(words); n++ {
+		for start := 0; start+n*loopMinRepeats <= len(words); start++ {
+			repeats := 1
+			for next := start + n; next+n <= len(words) && equalWords(words[start:start+n], words[next:next+n]); next += n {
+				repeats++
+			}
+			if repeats >= loopMinRepeats && repeats*n >= loopMinWords {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// equalWords reports whether two phrases have the same words
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hallucinationReason returns why a segment is likely hallucinated, or an
+// empty string if it looks like real speech
+func hallucinationReason(segment Segment) string {
+	switch {
+	case isKnownHallucination(segment.Text):
+		return hallucinationKnown
+	case segment.NoSpeechProb > noSpeechThreshold && segment.AvgLogprob < lowLogprobThreshold:
+		return hallucinationNoSpeech
+	case segment.CompressionRatio > maxCompressionRatio || hasRepetitionLoop(segment.Text):
+		return hallucinationRepetition
+	}
+	return ""
+}
+
+// detectHallucinations marks the likely hallucinated segments with the
+// reason and returns them. A text repeated over several segments in a row is
+// a loop as well; its first occurrence is kept.
+func detectHallucinations(segments []Segment) []Segment {
+	var found []Segment
+	run := 0
+	for i := range segments {
+		segment := &segments[i]
+		if i > 0 && normalizeHallucinationText(segment.Text) != "" &&
+			normalizeHallucinationText(segment.Text) == normalizeHallucinationText(segments[i-1].Text) {
+			run++
+		} else {
+			run = 0
+		}
+
+		segment.Hallucination = hallucinationReason(*segment)
+		if segment.Hallucination == "" && run >= loopMinRepeats-1 {
+			segment.Hallucination = hallucinationRepetition
+			// The repeat before this one is part of the loop as well
+			if previous := &segments[i-1]; run == loopMinRepeats-1 && previous.Hallucination == "" {
+				previous.Hallucination = hallucinationRepetition
+				found = append(found, *previous)
+			}
+		}
+		if segment.Hallucination != "" {
+			found = append(found, *segment)
+		}
+	}
+	return found
+}
+
+// stripHallucinations removes the segments marked as hallucinated, with their
+// words, and rebuilds the text from the remaining segments
+func stripHallucinations(transcript *Transcript) {
+	var kept []Segment
+	for _, segment := range transcript.Segments {
+		if segment.Hallucination == "" {
+			segment.ID = len(kept)
+			kept = append(kept, segment)
+		}
+	}
+	if len(kept) == len(transcript.Segments) {
+		return
+	}
+	transcript.Segments = kept
+	transcript.Words = wordsWithinSegments(transcript.Words, kept)
+	transcript.Text = segmentsText(kept)
+}
+
+// checkHallucinations flags or strips the likely hallucinated segments of a
+// transcript and reports them. Transcripts without segments aren't checked.
+func checkHallucinations(transcript *Transcript, mode string) int {
+	found := detectHallucinations(transcript.Segments)
+	for _, segment := range found {
+		if mode == hallucinationsStrip {
+			uiPrintf(tr(" Removed a likely hallucination at %s (%s): %s\n"), formatTimestamp(segment.Start), segment.Hallucination, strings.TrimSpace(segment.Text))
+		} else {
+			uiPrintf(tr("⚠️  Likely hallucination at %s (%s): %s\n"), formatTimestamp(segment.Start), segment.Hallucination, strings.TrimSpace(segment.Text))
+		}
+	}
+	if mode == hallucinationsStrip {
+		stripHallucinations(transcript)
+	}
+	return len(found)
+}
+
+// retriedSegment returns what replaces a hallucinated segment after a retry:
+// the retry's segments combined at the segment's place, and their words
+// shifted by offset, where the retried audio starts. ok is false if the
+// retry shows signs of hallucinating as well. An empty text means the retry
+// heard no speech.
+func retriedSegment(segment Segment, retry *Transcript, offset float64) (replacement Segment, words []Word, ok bool) {
+	if strings.TrimSpace(retry.Text) == "" {
+		return Segment{ID: segment.ID, Start: segment.Start, End: segment.End}, nil, true
+	}
+	if len(detectHallucinations(retry.Segments)) > 0 || isKnownHallucination(retry.Text) || hasRepetitionLoop(retry.Text) {
+		return Segment{}, nil, false
+	}
+
+	replacement = segment
+	replacement.Text = " " + strings.TrimSpace(retry.Text)
+	if len(retry.Segments) > 0 {
+		combined := combineSegments(retry.Segments)
+		replacement.Temperature = combined.Temperature
+		replacement.AvgLogprob = combined.AvgLogprob
+		replacement.CompressionRatio = combined.CompressionRatio
+		replacement.NoSpeechProb = combined.NoSpeechProb
+		replacement.Tokens = combined.Tokens
+	}
+	replacement.Hallucination = ""
+	for _, word := range retry.Words {
+		word.Start += offset
+		word.End += offset
+		words = append(words, word)
+	}
+	return replacement, words, true
+}
+
+// replaceSegmentWords replaces the words starting within a segment with others
+func replaceSegmentWords(words []Word, segment Segment, replacements []Word) []Word {
+	var replaced []Word
+	for _, word := range words {
+		if word.Start < segment.Start || word.Start >= segment.End {
+			replaced = append(replaced, word)
+		}
+	}
+	replaced = append(replaced, replacements...)
+	slices.SortStableFunc(replaced, func(a, b Word) int { return cmp.Compare(a.Start, b.Start) })
+	return replaced
+}
+
+// retryHallucinations transcribes the audio of likely hallucinated segments
+// again, up to --hallucination-retries times at rising temperatures and
+// without the prompt, which is what often keeps a model in a loop. The first
+// retry without signs of hallucinating replaces the segment; one that hears
+// no speech removes it. Segments without a clean retry stay as they are.
+func retryHallucinations(ctx context.Context, client openai.Client, args Args, path string, transcript *Transcript) error {
+	found := detectHallucinations(transcript.Segments)
+	if len(found) == 0 {
+		return nil
+	}
+	uiPrintf(tr(" Retrying %d likely hallucinated segments...\n"), len(found))
+
+	tmpDir, err := os.MkdirTemp("", "pindar_retry")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	retryArgs := args
+	retryArgs.Prompt = ""
+	retryArgs.BestOf = 1
+	retryArgs.RefineBelow = nil
+	temperatures := bestOfTemperatures(args.Temperature, args.HallucinationRetries+1)[1:]
+
+	var kept []Segment
+	for _, segment := range transcript.Segments {
+		if segment.Hallucination == "" {
+			kept = append(kept, segment)
+			continue
+		}
+
+		slicePath := filepath.Join(tmpDir, fmt.Sprintf("segment_%04d.mp4", segment.ID))
+		start := max(0, segment.Start-refinePadding)
+		if err := extractAudioSlice(ctx, path, slicePath, start, segment.End+refinePadding, 0); err != nil {
+			return fmt.Errorf("segment %d: %w", segment.ID, err)
+		}
+		replaced := false
+		for _, temperature := range temperatures {
+			retryArgs.Temperature = temperature
+			retry, err := transcribeFile(ctx, client, retryArgs, slicePath, filepath.Base(slicePath))
+			if err != nil {
+				return fmt.Errorf("segment %d: %w", segment.ID, err)
+			}
+			replacement, words, ok := retriedSegment(segment, retry, start)
+			if !ok {
+				continue
+			}
+
+			transcript.Words = replaceSegmentWords(transcript.Words, segment, words)
+			if replacement.Text == "" {
+				uiPrintf(tr(" Removed a likely hallucination at %s, the retry heard no speech: %s\n"), formatTimestamp(segment.Start), strings.TrimSpace(segment.Text))
+			} else {
+				uiPrintf(tr(" Replaced a likely hallucination at %s: %s → %s\n"), formatTimestamp(segment.Start), strings.TrimSpace(segment.Text), strings.TrimSpace(replacement.Text))
+				kept = append(kept, replacement)
+			}
+			replaced = true
+			break
+		}
+		os.Remove(slicePath)
+		if !replaced {
+			kept = append(kept, segment)
+		}
+	}
+
+	for i := range kept {
+		kept[i].ID = i
+	}
+	transcript.Segments = kept
+	transcript.Text = segmentsText(kept)
+	return nil
+}
+
+// validateHallucinationMode checks the --hallucinations mode
+func validateHallucinationMode(mode string) error {
+	switch mode {
+	case hallucinationsOff, hallucinationsFlag, hallucinationsStrip:
+		return nil
+	}
+	return fmt.Errorf(tr("unknown --hallucinations mode %q, use %s, %s or %s"), mode, hallucinationsOff, hallucinationsFlag, hallucinationsStrip)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsKnownHallucination(t *testing.T) {
+	for _, text := range []string{" Thanks for watching!", "Untertitel im Auftrag des ZDF, 2017", "¡Gracias por ver el video!"} {
+		if !isKnownHallucination(text) {
+			t.Errorf("Expected %q to be a known hallucination", text)
+		}
+	}
+	for _, text := range []string{"Thanks for watching the kids yesterday.", "Please subscribe to our newsletter at the door.", ""} {
+		if isKnownHallucination(text) {
+			t.Errorf("Expected %q not to be a known hallucination", text)
+		}
+	}
+}
+
+func TestHasRepetitionLoop(t *testing.T) {
+	loops := []string{
+		"I'm going to go. I'm going to go. I'm going to go.",
+		"Okay, okay, okay, okay, okay, okay, okay, okay.",
+		"So we start and then and then and then and then and then",
+	}
+	for _, text := range loops {
+		if !hasRepetitionLoop(text) {
+			t.Errorf("Expected %q to be a loop", text)
+		}
+	}
+	for _, text := range []string{"No, no, no, that's wrong.", "Very very very good.", "We tried it twice, we tried it twice."} {
+		if hasRepetitionLoop(text) {
+			t.Errorf("Expected %q not to be a loop", text)
+		}
+	}
+}
+
+func TestDetectHallucinations(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, Text: " Hello and welcome.", AvgLogprob: -0.2, NoSpeechProb: 0.01},
+		{Start: 2, Text: " Thank you.", AvgLogprob: -1.4, NoSpeechProb: 0.8},
+		{Start: 4, Text: " Let's begin.", AvgLogprob: -0.3},
+		{Start: 6, Text: " Let's begin.", AvgLogprob: -0.3},
+		{Start: 8, Text: " Let's begin!", AvgLogprob: -0.3},
+		{Start: 10, Text: " Let's begin.", AvgLogprob: -0.3},
+		{Start: 12, Text: " Thanks for watching!"},
+		{Start: 14, Text: " Bye.", CompressionRatio: 2.6},
+	}
+	found := detectHallucinations(segments)
+
+	expected := []string{"", hallucinationNoSpeech, "", hallucinationRepetition, hallucinationRepetition, hallucinationRepetition, hallucinationKnown, hallucinationRepetition}
+	for i, segment := range segments {
+		if segment.Hallucination != expected[i] {
+			t.Errorf("Segment %d: expected %q, got %q", i, expected[i], segment.Hallucination)
+		}
+	}
+	if len(found) != 6 || found[1].Start != 6 {
+		t.Errorf("Expected the 6 flagged segments in order, got %+v", found)
+	}
+}
+
+func TestStripHallucinations(t *testing.T) {
+	transcript := &Transcript{
+		Text: "Hello. Thanks for watching! Bye.",
+		Segments: []Segment{
+			{ID: 0, Start: 0, End: 2, Text: " Hello."},
+			{ID: 1, Start: 2, End: 4, Text: " Thanks for watching!", Hallucination: hallucinationKnown},
+			{ID: 2, Start: 4, End: 6, Text: " Bye."},
+		},
+		Words: []Word{{Word: "Hello", Start: 0.1}, {Word: "Thanks", Start: 2.1}, {Word: "Bye", Start: 4.1}},
+	}
+	stripHallucinations(transcript)
+
+	if transcript.Text != "Hello. Bye." || len(transcript.Segments) != 2 || transcript.Segments[1].ID != 1 {
+		t.Errorf("Expected the hallucinated segment to be removed, got %q and %+v", transcript.Text, transcript.Segments)
+	}
+	if len(transcript.Words) != 2 || transcript.Words[1].Word != "Bye" {
+		t.Errorf("Expected the words of the segment to be removed, got %+v", transcript.Words)
+	}
+}
+
+func TestValidateHallucinationMode(t *testing.T) {
+	for _, mode := range []string{"off", "flag", "strip"} {
+		if err := validateHallucinationMode(mode); err != nil {
+			t.Errorf("Unexpected error for %s: %v", mode, err)
+		}
+	}
+	if err := validateHallucinationMode("delete"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+func TestRetriedSegment(t *testing.T) {
+	segment := Segment{ID: 4, Start: 10, End: 14, Text: " Thanks for watching!", Hallucination: hallucinationKnown, NoSpeechProb: 0.9}
+
+	retry := &Transcript{
+		Text:     " And that's the end of the lecture.",
+		Segments: []Segment{{Start: 0.3, End: 3.5, Text: " And that's the end of the lecture.", AvgLogprob: -0.2, NoSpeechProb: 0.05}},
+		Words:    []Word{{Word: "And", Start: 0.3, End: 0.5}, {Word: "lecture", Start: 3, End: 3.5}},
+	}
+	replacement, words, ok := retriedSegment(segment, retry, 9.75)
+	if !ok {
+		t.Fatal("Expected a clean retry to be accepted")
+	}
+	if replacement.ID != 4 || replacement.Start != 10 || replacement.End != 14 || replacement.Text != " And that's the end of the lecture." {
+		t.Errorf("Expected the retry's text at the segment's place, got %+v", replacement)
+	}
+	if replacement.Hallucination != "" || replacement.NoSpeechProb > 0.1 {
+		t.Errorf("Expected the retry's statistics without a reason, got %+v", replacement)
+	}
+	if len(words) != 2 || words[0].Start != 10.05 || words[1].End != 13.25 {
+		t.Errorf("Expected the words shifted by the offset, got %+v", words)
+	}
+
+	looping := &Transcript{Text: " Thank you for watching.", Segments: []Segment{{Text: " Thank you for watching."}}}
+	if _, _, ok := retriedSegment(segment, looping, 9.75); ok {
+		t.Error("Expected a retry hallucinating as well to be rejected")
+	}
+
+	replacement, _, ok = retriedSegment(segment, &Transcript{Text: " "}, 9.75)
+	if !ok || replacement.Text != "" {
+		t.Errorf("Expected an empty retry to remove the segment, got %+v", replacement)
+	}
+}
+
+func TestReplaceSegmentWords(t *testing.T) {
+	words := []Word{{Word: "before", Start: 9}, {Word: "thanks", Start: 10}, {Word: "watching", Start: 12}, {Word: "after", Start: 14}}
+	replaced := replaceSegmentWords(words, Segment{Start: 10, End: 14}, []Word{{Word: "end", Start: 11}})
+
+	var got []string
+	for _, word := range replaced {
+		got = append(got, word.Word)
+	}
+	if strings.Join(got, " ") != "before end after" {
+		t.Errorf("Expected the segment's words replaced, got %v", got)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// htmlPage describes the page around an html transcript
+type htmlPage struct {
+	Title string
+	// Audio is the src of the player: a URL, a path relative to the page, or a data URI
+	Audio template.URL
+	// Offset is where the transcript starts in the audio, for chapter pages
+	Offset float64
+}
+
+// htmlToken is a piece of segment text; timed tokens are words the player highlights
+type htmlToken struct {
+	Text  string
+	Timed bool
+	Start float64
+	End   float64
+}
+
+// htmlSegment is a clickable paragraph of the page
+type htmlSegment struct {
+	Start float64
+	Time  string
+	// Speaker is set where the speaker changes
+	Speaker string
+	Tokens  []htmlToken
+}
+
+var htmlTemplate = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html{{if .Language}} lang="{{.Language}}"{{end}}{{if .RTL}} dir="rtl"{{end}}>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font: 18px/1.6 system-ui, sans-serif; max-width: 46em; margin: 0 auto; padding: 0 1em 4em; color: #222; }
+header { position: sticky; top: 0; background: #fff; padding: 1em 0; border-bottom: 1px solid #ddd; }
+h1 { font-size: 1.2em; margin: 0 0 .5em; }
+audio { width: 100%; }
+p { cursor: pointer; margin: .8em 0; border-radius: 4px; }
+p:hover { background: #f4f4f4; }
+p.current { background: #eef5ff; }
+time { color: #888; font-size: .8em; margin-inline-end: .6em; font-variant-numeric: tabular-nums; }
+span.current { background: #ffe48a; border-radius: 3px; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<audio id="player" controls preload="metadata" src="{{.Audio}}" data-offset="{{.Offset}}"></audio>
+</header>
+<main id="transcript">
+{{- range .Segments}}
+<p data-start="{{.Start}}"><time>{{.Time}}</time>{{if .Speaker}}<b>{{.Speaker}}:</b> {{end}}{{range .Tokens}}{{if .Timed}}<span data-start="{{.Start}}" data-end="{{.End}}">{{.Text}}</span>{{else}}{{.Text}}{{end}}{{end}}</p>
+{{- end}}
+</main>
+<script>
+(function () {
+  var player = document.getElementById("player");
+  var offset = parseFloat(player.dataset.offset) || 0;
+  var paragraphs = Array.prototype.slice.call(document.querySelectorAll("p[data-start]"));
+  var words = Array.prototype.slice.call(document.querySelectorAll("span[data-start]"));
+  var current = [];
+
+  paragraphs.forEach(function (p) {
+    p.addEventListener("click", function () {
+      player.currentTime = offset + parseFloat(p.dataset.start);
+      player.play();
+    });
+  });
+
+  // The last element starting at or before t, found by binary search
+  function at(elements, t) {
+    var lo = 0, hi = elements.length - 1, found = null;
+    while (lo <= hi) {
+      var mid = (lo + hi) >> 1;
+      if (parseFloat(elements[mid].dataset.start) <= t) { found = elements[mid]; lo = mid + 1; } else { hi = mid - 1; }
+    }
+    return found;
+  }
+
+  player.addEventListener("timeupdate", function () {
+    var t = player.currentTime - offset;
+    var word = at(words, t);
+    if (word && t > parseFloat(word.dataset.end)) { word = null; }
+    var highlighted = [at(paragraphs, t), word].filter(Boolean);
+    current.forEach(function (el) { if (highlighted.indexOf(el) < 0) { el.classList.remove("current"); } });
+    highlighted.forEach(function (el) { el.classList.add("current"); });
+    current = highlighted;
+  });
+})();
+</script>
+</body>
+</html>
+`))
+
+// htmlTokens splits segment text at spaces and times the tokens that match the
+// next transcribed word, keeping the punctuation the word timestamps lack
+func htmlTokens(text string, words []Word) []htmlToken {
+	var tokens []htmlToken
+	fields := strings.Fields(text)
+	next := 0
+	for i, field := range fields {
+		token := htmlToken{Text: field}
+		if next < len(words) && normalizeWord(field) == normalizeWord(words[next].Word) {
+			token.Timed, token.Start, token.End = true, words[next].Start, words[next].End
+			next++
+		}
+		tokens = append(tokens, token)
+		if i < len(fields)-1 {
+			tokens = append(tokens, htmlToken{Text: " "})
+		}
+	}
+	return tokens
+}
+
+// renderHTML produces a standalone page with an audio player and the transcript:
+// clicking a paragraph seeks the player, and playback highlights the current word
+func renderHTML(segments []Segment, words []Word, language string, page htmlPage) (string, error) {
+	lang, err := normalizeLanguage(language)
+	if err != nil {
+		lang = ""
+	}
+
+	data := struct {
+		htmlPage
+		Language string
+		RTL      bool
+		Segments []htmlSegment
+	}{htmlPage: page, Language: lang, RTL: rtlLanguages[lang]}
+	speaker := ""
+	for i, segmentWords := range segmentWords(segments, words) {
+		segment := htmlSegment{
+			Start:  segments[i].Start,
+			Time:   formatTimestamp(segments[i].Start),
+			Tokens: htmlTokens(segments[i].Text, segmentWords),
+		}
+		if segments[i].Speaker != speaker {
+			segment.Speaker, speaker = segments[i].Speaker, segments[i].Speaker
+		}
+		data.Segments = append(data.Segments, segment)
+	}
+
+	var b strings.Builder
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render html: %w", err)
+	}
+	return b.String(), nil
+}
+
+// htmlAudioSource returns the player's src: --audio-url if given, the audio
+// embedded as a data URI with --embed-audio, or otherwise the path of the audio
+// relative to the page so the two can be moved together
+func htmlAudioSource(args Args, originalFile, outputFile string) (template.URL, error) {
+	if args.AudioURL != "" {
+		return template.URL(args.AudioURL), nil
+	}
+	if args.EmbedAudio {
+		data, err := os.ReadFile(originalFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read audio for embedding: %w", err)
+		}
+		mediaType := mime.TypeByExtension(filepath.Ext(originalFile))
+		if mediaType == "" {
+			mediaType = "audio/mpeg"
+		}
+		return template.URL("data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)), nil
+	}
+
+	if outputFile == "" {
+		return pathURL(originalFile), nil
+	}
+	audio, err := filepath.Abs(originalFile)
+	if err != nil {
+		return "", err
+	}
+	page, err := filepath.Abs(filepath.Dir(outputFile))
+	if err != nil {
+		return "", err
+	}
+	relative, err := filepath.Rel(page, audio)
+	if err != nil {
+		return pathURL(audio), nil
+	}
+	return pathURL(relative), nil
+}
+
+// pathURL turns a file path into a URL path, escaping characters with a
+// meaning in URLs, like # and ?, in every element
+func pathURL(path string) template.URL {
+	elements := strings.Split(filepath.ToSlash(path), "/")
+	for i, element := range elements {
+		elements[i] = url.PathEscape(element)
+	}
+	// A colon in the first element would be read as a URL scheme
+	if strings.Contains(elements[0], ":") && !filepath.IsAbs(path) {
+		elements[0] = "./" + elements[0]
+	}
+	return template.URL(strings.Join(elements, "/"))
+}
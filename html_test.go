@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTMLTokens(t *testing.T) {
+	words := []Word{
+		{Word: "Hello", Start: 0.8, End: 1.2},
+		{Word: "world", Start: 1.3, End: 1.9},
+	}
+	tokens := htmlTokens(" Hello, (loud) world!", words)
+
+	var text strings.Builder
+	var timed []string
+	for _, token := range tokens {
+		text.WriteString(token.Text)
+		if token.Timed {
+			timed = append(timed, token.Text)
+		}
+	}
+	if text.String() != "Hello, (loud) world!" {
+		t.Errorf("Expected the text to be kept, got %q", text.String())
+	}
+	if strings.Join(timed, "|") != "Hello,|world!" {
+		t.Errorf("Expected the words to be timed with their punctuation, got %v", timed)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	segments, words := karaokeTestData()
+	segments[1].Text = " Sing <along>"
+	page := htmlPage{Title: "Tom & Jerry.mp3", Audio: "Tom%20%26%20Jerry.mp3", Offset: 90}
+
+	result, err := renderHTML(segments, words, "de", page)
+	if err != nil {
+		t.Fatalf("renderHTML() failed: %v", err)
+	}
+	for _, expected := range []string{
+		`<html lang="de">`,
+		`<title>Tom &amp; Jerry.mp3</title>`,
+		`src="Tom%20%26%20Jerry.mp3" data-offset="90"`,
+		`<p data-start="0.5"><time>00:00:00</time><span data-start="0.8" data-end="1.2">Hello</span> <span data-start="1.3" data-end="1.9">world</span></p>`,
+		`<span data-start="3" data-end="3.8">&lt;along&gt;</span>`,
+		`<p data-start="4"><time>00:00:04</time>(instrumental)</p>`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in the page", expected)
+		}
+	}
+}
+
+func TestHTMLAudioSource(t *testing.T) {
+	dir := t.TempDir()
+	audio := filepath.Join(dir, "audio", "talk.wav")
+	if err := os.MkdirAll(filepath.Dir(audio), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(audio, []byte("RIFF"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := htmlAudioSource(Args{}, audio, filepath.Join(dir, "pages", "talk.html"))
+	if err != nil || src != "../audio/talk.wav" {
+		t.Errorf("Expected a path relative to the page, got %q (%v)", src, err)
+	}
+
+	src, _ = htmlAudioSource(Args{AudioURL: "https://example.com/talk.wav", EmbedAudio: true}, audio, "")
+	if src != "https://example.com/talk.wav" {
+		t.Errorf("Expected --audio-url to take precedence, got %q", src)
+	}
+
+	src, err = htmlAudioSource(Args{EmbedAudio: true}, audio, "")
+	if err != nil || !strings.HasPrefix(string(src), "data:audio/") || !strings.HasSuffix(string(src), ";base64,UklGRg==") {
+		t.Errorf("Expected the audio as a data URI, got %q (%v)", src, err)
+	}
+}
+
+func TestHTMLAudioSourceEscapesPath(t *testing.T) {
+	dir := t.TempDir()
+	audio := filepath.Join(dir, "talk #1?100%.wav")
+	if err := os.WriteFile(audio, []byte("RIFF"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := htmlAudioSource(Args{}, audio, filepath.Join(dir, "talk.html"))
+	if err != nil || src != "talk%20%231%3F100%25.wav" {
+		t.Errorf("Expected the file name to be escaped, got %q (%v)", src, err)
+	}
+	if src := pathURL("a:b.wav"); src != "./a:b.wav" {
+		t.Errorf("Expected a colon not to be read as a scheme, got %q", src)
+	}
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// uiLanguage is the language of the CLI's own messages, set with --ui-lang
+var uiLanguage = "en"
+
+// translations holds the CLI messages per UI language, keyed by the English
+// message. Messages without a translation are printed in English.
+var translations = map[string]map[string]string{
+	"de": {
+		// Banner and parameters
+		"  Pindar - Audio Transcription CLI":      "  Pindar - Audio-Transkription per Kommandozeile",
+		"\n  Transcription Parameters:":           "\n  Transkriptionsparameter:",
+		"   File:        %s\n":                    "   Datei:       %s\n",
+		"   Model:       %s\n":                    "   Modell:      %s\n",
+		"   Language:    %s (%s)\n":               "   Sprache:     %s (%s)\n",
+		"   Language:    auto-detect\n":           "   Sprache:     automatisch erkennen\n",
+		"   Format:      %s\n":                    "   Format:      %s\n",
+		"   Temperature: %.1f\n":                  "   Temperatur:  %.1f\n",
+		"   Prompt:      %s\n":                    "   Prompt:      %s\n",
+		"   Best of:     %d runs\n":               "   Beste von:   %d Durchläufen\n",
+		"   Data policy: %s\n":                    "   Datenschutz: %s\n",
+		"   Refine:      below %.2f with %s\n":    "   Nachbessern: unter %.2f mit %s\n",
+		"zero retention (organization agreement)": "keine Speicherung (Vereinbarung der Organisation)",
+		"provider default (OpenAI may retain API data for up to 30 days for abuse monitoring)": "Standard des Anbieters (OpenAI kann API-Daten bis zu 30 Tage zur Missbrauchserkennung speichern)",
+
+		// Progress
+		" Starting transcription...":                                                  " Transkription wird gestartet...",
+		"✅ Transcription completed successfully!":                                     "✅ Transkription erfolgreich abgeschlossen!",
+		" Converting .%s to .mp4 format...\n":                                         " Konvertiere .%s ins .mp4-Format...\n",
+		" Applying the phone-call preset (band-pass filter, upsampling to 16 kHz)...": " Wende das Telefonie-Preset an (Bandpassfilter, Hochrechnen auf 16 kHz)...",
+		" Transcribing both sides of the call separately...":                          " Transkribiere beide Seiten des Anrufs getrennt...",
+		"--split-call needs a stereo recording, but %s has %d channel(s)":             "--split-call braucht eine Stereoaufnahme, aber %s hat %d Kanal/Kanäle",
+		" Extracting audio track %d from .%s to .mp4 format...\n":                     " Extrahiere Tonspur %d aus .%s ins .mp4-Format...\n",
+		" Uploading .%s directly as .%s (no conversion needed)\n":                     " Lade .%s direkt als .%s hoch (keine Konvertierung nötig)\n",
+		" Found %d chapters, transcribing each chapter separately...\n":               " %d Kapitel gefunden, jedes Kapitel wird einzeln transkribiert...\n",
+		" Refining %d of %d low-confidence segments with %s...\n":                     " Bessere %d von %d unsicheren Segmenten mit %s nach...\n",
+		" Routing %s audio to %s (rule %d)\n":                                         " Leite %s Audio an %s weiter (Regel %d)\n",
+		"   Run %d/%d (temperature %.1f): avg logprob %.3f\n":                         "   Durchlauf %d/%d (Temperatur %.1f): mittlere Logprob %.3f\n",
+		" Detecting person names with %s...\n":                                        " Suche Personennamen mit %s...\n",
+		" Extracting entities with %s...\n":                                           " Extrahiere Entitäten mit %s...\n",
+		" Tagging segments with %s...\n":                                              " Verschlagworte Segmente mit %s...\n",
+		" Writing meeting minutes with %s...\n":                                       " Erstelle Protokoll mit %s...\n",
+		" Pairing interview questions and answers with %s...\n":                       " Ordne Fragen und Antworten des Interviews mit %s zu...\n",
+		" Compared %d words: %d edits (%.1f%% correction rate)\n":                     " %d Wörter verglichen: %d Änderungen (%.1f%% Korrekturrate)\n",
+		"\n  Correction rates:":                                                       "\n  Korrekturraten:",
+		"   %5.1f%%  %s (%d of %d words)\n":                                           "   %5.1f%%  %s (%d von %d Wörtern)\n",
+		"   %5.1f%%  overall, %d files\n":                                             "   %5.1f%%  insgesamt, %d Dateien\n",
+		"--top must be at least 1":                                                    "--top muss mindestens 1 sein",
+		"\n  Frequently corrected:":                                                   "\n  Häufig korrigiert:",
+		"   %s (%d×, transcribed as %s)\n":                                            "   %s (%d×, transkribiert als %s)\n",
+		"\n No term was corrected %d or more times.\n":                                "\n Kein Begriff wurde %d-mal oder öfter korrigiert.\n",
+
+		// Tracks
+		" %s has %d audio tracks, using track 1 (select another with --track)\n": " %s hat %d Tonspuren, verwende Spur 1 (andere Spur mit --track wählen)\n",
+		" Audio tracks in %s:\n":                                    " Tonspuren in %s:\n",
+		" Select track [1-%d] (default 1): ":                        " Spur auswählen [1-%d] (Standard 1): ",
+		"--track must be a positive track number":                   "--track muss eine positive Spurnummer sein",
+		"no audio track found in %s":                                "keine Tonspur in %s gefunden",
+		"track %d requested but %s only has %d audio track(s):\n%s": "Spur %d angefordert, aber %s hat nur %d Tonspur(en):\n%s",
+		"invalid track selection %q":                                "ungültige Spurauswahl %q",
+
+		// Output
+		"End of transcription.":          "Ende der Transkription.",
+		"Error:":                         "Fehler:",
+		"Warning:":                       "Warnung:",
+		"Tip:":                           "Tipp:",
+		"\n📝 Transcription:":             "\n📝 Transkription:",
+		"💾 Transcription saved to: %s\n": "💾 Transkription gespeichert unter: %s\n",
+		"💾 %d chapter transcriptions saved next to the combined file\n":                       "💾 %d Kapitel-Transkriptionen neben der Gesamtdatei gespeichert\n",
+		"💾 Restored transcript saved to: %s\n":                                                "💾 Wiederhergestellte Transkription gespeichert unter: %s\n",
+		"💾 %d entities saved to: %s\n":                                                        "💾 %d Entitäten gespeichert unter: %s\n",
+		"💾 Meeting minutes saved to: %s\n":                                                    "💾 Protokoll gespeichert unter: %s\n",
+		"💾 %d questions and answers saved to: %s\n":                                           "💾 %d Fragen und Antworten gespeichert unter: %s\n",
+		"💾 Label Studio task saved to: %s\n":                                                  "💾 Label-Studio-Aufgabe gespeichert unter: %s\n",
+		"💾 Corrections recorded in: %s\n":                                                     "💾 Korrekturen aufgezeichnet in: %s\n",
+		"\n💡 Add these terms to the prompt so the model spells them right:\n   --prompt %q\n": "\n💡 Diese Begriffe in den Prompt aufnehmen, damit das Modell sie richtig schreibt:\n   --prompt %q\n",
+		"🔒 Replaced %d names with placeholders, mapping saved to: %s\n":                       "🔒 %d Namen durch Platzhalter ersetzt, Zuordnung gespeichert unter: %s\n",
+
+		// Warnings
+		"⚠️  Note: %s output requires timestamps, which %s does not provide. Using whisper-1 instead.\n":                        "⚠️  Hinweis: Die Ausgabe als %s benötigt Zeitstempel, die %s nicht liefert. Stattdessen wird whisper-1 verwendet.\n",
+		"⚠️  Note: Refining low-confidence segments requires timestamps, which %s does not provide. Using whisper-1 instead.\n": "⚠️  Hinweis: Das Nachbessern unsicherer Segmente benötigt Zeitstempel, die %s nicht liefert. Stattdessen wird whisper-1 verwendet.\n",
+		"⚠️  Note: The requested outputs require timestamps, which %s does not provide. Using whisper-1 instead.\n":             "⚠️  Hinweis: Die angeforderten Ausgaben benötigen Zeitstempel, die %s nicht liefert. Stattdessen wird whisper-1 verwendet.\n",
+		"⚠️  Segment tags are only included in verbose_json and csv output, not in %s\n":                                        "⚠️  Segment-Schlagworte sind nur in der Ausgabe als verbose_json und csv enthalten, nicht in %s\n",
+		"⚠️  Prompt is about %d tokens, more than the %d-token limit. Keeping only its last %d characters.\n":                   "⚠️  Der Prompt hat etwa %d Tokens, mehr als das Limit von %d Tokens. Nur die letzten %d Zeichen werden verwendet.\n",
+		"⚠️  Run %d/%d returned no confidence information, keeping the best result so far\n":                                    "⚠️  Durchlauf %d/%d lieferte keine Konfidenzwerte, das bisher beste Ergebnis wird behalten\n",
+		"⚠️  Could not read chapters, transcribing as a single file: %v\n":                                                      "⚠️  Kapitel konnten nicht gelesen werden, Transkription als einzelne Datei: %v\n",
+		"⚠️  Could not determine audio duration for routing: %v\n":                                                              "⚠️  Audiodauer für die Modellauswahl konnte nicht bestimmt werden: %v\n",
+
+		// Errors
+		" Error getting API key: %v\n":                                                               " Fehler beim Abrufen des API-Schlüssels: %v\n",
+		" Error loading config: %v\n":                                                                " Fehler beim Laden der Konfiguration: %v\n",
+		" Error selecting audio track: %v\n":                                                         " Fehler bei der Auswahl der Tonspur: %v\n",
+		" Error converting audio file: %v\n":                                                         " Fehler beim Konvertieren der Audiodatei: %v\n",
+		"❌ Data policy error: %v\n":                                                                  "❌ Datenschutzfehler: %v\n",
+		"❌ Invalid ffmpeg configuration: %v\n":                                                       "❌ Ungültige ffmpeg-Konfiguration: %v\n",
+		"missing command: install-ffmpeg":                                                            "fehlender Befehl: install-ffmpeg",
+		"❌ Error installing ffmpeg: %v\n":                                                            "❌ Fehler beim Installieren von ffmpeg: %v\n",
+		"✅ ffmpeg is already installed at %s (use --force to replace it)\n":                          "✅ ffmpeg ist bereits unter %s installiert (mit --force ersetzen)\n",
+		" Downloading ffmpeg from %s...\n":                                                           " Lade ffmpeg von %s herunter...\n",
+		"✅ ffmpeg installed at %s (%.0f MB). pindar uses it when no other ffmpeg is found.\n":        "✅ ffmpeg unter %s installiert (%.0f MB). pindar verwendet es, wenn kein anderes ffmpeg gefunden wird.\n",
+		"the download from %s has the SHA-256 digest %s, not %s; not installing it":                  "der Download von %s hat den SHA-256-Hash %s, nicht %s; er wird nicht installiert",
+		"no SHA-256 digest is known for %s, pass the one of the release page with --sha256":          "für %s ist kein SHA-256-Hash bekannt, bitte den von der Release-Seite mit --sha256 angeben",
+		"the release lists no SHA-256 digest for %s, pass the one of the release page with --sha256": "das Release nennt keinen SHA-256-Hash für %s, bitte den von der Release-Seite mit --sha256 angeben",
+		"⚠️  The static build has no ffprobe; install one with your package manager to select tracks, read chapters and route by duration": "⚠️  Der statische Build enthält kein ffprobe; installieren Sie es mit Ihrem Paketmanager, um Spuren auszuwählen, Kapitel zu lesen und nach Dauer zu routen",
+		"no static ffmpeg build for %s/%s, install ffmpeg with your package manager":                                                       "kein statischer ffmpeg-Build für %s/%s, installieren Sie ffmpeg mit Ihrem Paketmanager",
+		"Config file": "Konfigurationsdatei",
+		"Fix the JSON in %s or delete the file to start over":               "Korrigieren Sie das JSON in %s oder löschen Sie die Datei, um neu zu beginnen",
+		"See \"Model Routing\" in the README for the rule format":           "Das Regelformat steht im README unter \"Model Routing\"",
+		"none yet, defaults are used":                                       "noch keine, es gelten die Standardwerte",
+		"%s can't be run: %v":                                               "%s kann nicht ausgeführt werden: %v",
+		"Network":                                                           "Netzwerk",
+		"Check your internet connection, proxy and firewall":                "Prüfen Sie Internetverbindung, Proxy und Firewall",
+		"api.openai.com is reachable":                                       "api.openai.com ist erreichbar",
+		"API key":                                                           "API-Schlüssel",
+		"no API key found in arguments, environment, or config file":        "kein API-Schlüssel in Argumenten, Umgebung oder Konfigurationsdatei gefunden",
+		"Run \"pindar init\" to save a key, or set OPENAI_API_KEY":          "Führen Sie \"pindar init\" aus, um einen Schlüssel zu speichern, oder setzen Sie OPENAI_API_KEY",
+		"Create a new key at https://platform.openai.com/api-keys":          "Erstellen Sie einen neuen Schlüssel unter https://platform.openai.com/api-keys",
+		"valid, transcription models are available":                         "gültig, Transkriptionsmodelle sind verfügbar",
+		"Corrections log":                                                   "Korrekturprotokoll",
+		"%d transcripts recorded":                                           "%d Transkripte erfasst",
+		"none yet":                                                          "noch keines",
+		"Remove the invalid lines from the log, or delete it to start over": "Entfernen Sie die ungültigen Zeilen aus dem Protokoll oder löschen Sie es, um neu zu beginnen",
+		"Disk space":         "Speicherplatz",
+		"%.1f GB free in %s": "%.1f GB frei in %s",
+		"Free up space or point TMPDIR at a larger disk; long recordings are converted there":           "Geben Sie Speicher frei oder setzen Sie TMPDIR auf ein größeres Laufwerk; lange Aufnahmen werden dort konvertiert",
+		"Install ffmpeg, run \"pindar deps install-ffmpeg\", or point --ffmpeg-path at it":              "Installieren Sie ffmpeg, führen Sie \"pindar deps install-ffmpeg\" aus oder geben Sie es mit --ffmpeg-path an",
+		"Install ffprobe (part of ffmpeg); without it tracks, chapters and durations can't be detected": "Installieren Sie ffprobe (Teil von ffmpeg); ohne es können Spuren, Kapitel und Dauer nicht erkannt werden",
+		"\n%d of %d checks failed.\n":                           "\n%d von %d Prüfungen fehlgeschlagen.\n",
+		"\nEverything needed to transcribe is in place.":        "\nAlles Nötige zum Transkribieren ist vorhanden.",
+		"audio file is required":                                "Audiodatei ist erforderlich",
+		"pass either an audio file or --manifest, not both":     "geben Sie entweder eine Audiodatei oder --manifest an, nicht beides",
+		"❌ Error reading manifest: %v\n":                        "❌ Fehler beim Lesen des Manifests: %v\n",
+		" Using the options in %s\n":                            " Verwende die Optionen aus %s\n",
+		"❌ Error running manifest: %v\n":                        "❌ Fehler beim Ausführen des Manifests: %v\n",
+		"the manifest has no file column (columns: %s)":         "das Manifest hat keine Spalte file (Spalten: %s)",
+		"the manifest lists no files":                           "das Manifest enthält keine Dateien",
+		"line %d: %w":                                           "Zeile %d: %w",
+		"\n✅ Transcribed all %d files of the manifest\n":        "\n✅ Alle %d Dateien des Manifests transkribiert\n",
+		"unknown hwaccel %q, use one of: %s":                    "unbekannte Hardwarebeschleunigung %q, verwenden Sie eine von: %s",
+		"❌ Invalid routing configuration: %v\n":                 "❌ Ungültige Routing-Konfiguration: %v\n",
+		"❌ Error rendering transcription: %v\n":                 "❌ Fehler beim Erzeugen der Transkription: %v\n",
+		"❌ Error rendering chapter transcription: %v\n":         "❌ Fehler beim Erzeugen der Kapitel-Transkription: %v\n",
+		"❌ Error writing chapter file: %v\n":                    "❌ Fehler beim Schreiben der Kapiteldatei: %v\n",
+		"❌ Error writing output file: %v\n":                     "❌ Fehler beim Schreiben der Ausgabedatei: %v\n",
+		"❌ Error anonymizing transcription: %v\n":               "❌ Fehler beim Anonymisieren der Transkription: %v\n",
+		"❌ Error extracting entities: %v\n":                     "❌ Fehler beim Extrahieren der Entitäten: %v\n",
+		"❌ Error tagging segments: %v\n":                        "❌ Fehler beim Verschlagworten der Segmente: %v\n",
+		"❌ Error writing meeting minutes: %v\n":                 "❌ Fehler beim Erstellen des Protokolls: %v\n",
+		"❌ Error pairing interview questions and answers: %v\n": "❌ Fehler beim Zuordnen der Fragen und Antworten: %v\n",
+		"❌ Error writing Label Studio task: %v\n":               "❌ Fehler beim Schreiben der Label-Studio-Aufgabe: %v\n",
+		"❌ Error creating Anki deck: %v\n":                      "❌ Fehler beim Erstellen des Anki-Decks: %v\n",
+		"the transcript has no sentences to make cards of":      "das Transkript enthält keine Sätze für Karteikarten",
+		" Translating %d sentences into %s with %s...\n":        " Übersetze %d Sätze nach %s mit %s...\n",
+		" Cutting %d audio snippets for Anki...\n":              " Schneide %d Audioausschnitte für Anki zu...\n",
+		"💾 Anki deck saved to: %s\n":                            "💾 Anki-Deck gespeichert unter: %s\n",
+		"❌ Error creating bilingual transcript: %v\n":           "❌ Fehler beim Erstellen des zweisprachigen Transkripts: %v\n",
+		" Translating %d segments into %s with %s...\n":         " Übersetze %d Segmente nach %s mit %s...\n",
+		"💾 Bilingual transcript saved to: %s\n":                 "💾 Zweisprachiges Transkript gespeichert unter: %s\n",
+		"%s has no criteria":                                    "%s enthält keine Kriterien",
+		"criterion %d has no name":                              "Kriterium %d hat keinen Namen",
+		"criterion %q needs either \"any\" or \"none\" phrases": "Kriterium %q braucht entweder \"any\"- oder \"none\"-Phrasen",
+		"criterion %q has a negative \"within\" or \"points\"":  "Kriterium %q hat einen negativen Wert für \"within\" oder \"points\"",
+		"⚠️  Scorecard criteria limited to a speaker only match with --split-call, --tracks or --turns": "⚠️  Scorecard-Kriterien für einen bestimmten Sprecher greifen nur mit --split-call, --tracks oder --turns",
+		"❌ Error scoring the call: %v\n":                                                "❌ Fehler beim Bewerten des Anrufs: %v\n",
+		"the transcript has no segments to score":                                       "das Transkript enthält keine Segmente zum Bewerten",
+		"💾 QA scorecard saved to: %s\n":                                                 "💾 QA-Scorecard gespeichert unter: %s\n",
+		"dictation commands are not available in %s, only in: %s":                       "Diktierbefehle gibt es nicht auf %s, nur auf: %s",
+		"⚠️  %v, leaving them as spoken\n":                                              "⚠️  %v, sie bleiben wie gesprochen\n",
+		"❌ Invalid macro configuration: %v\n":                                           "❌ Ungültige Makro-Konfiguration: %v\n",
+		"the macro %q has no words to listen for":                                       "das Makro %q enthält keine Wörter, auf die gehört werden kann",
+		" Expanded %d spoken macros\n":                                                  " %d gesprochene Makros ersetzt\n",
+		"--chunk must be at least 2 seconds":                                            "--chunk muss mindestens 2 Sekunden betragen",
+		"❌ Error recording: %v\n":                                                       "❌ Fehler bei der Aufnahme: %v\n",
+		" Listening to %s (%s), transcribing every %d seconds. Press Ctrl+C to stop.\n": " Höre %s (%s) zu und transkribiere alle %d Sekunden. Mit Strg+C beenden.\n",
+		"⚠️  Could not transcribe %s: %v\n":                                             "⚠️  %s konnte nicht transkribiert werden: %v\n",
+		"\n Stopping, transcribing the rest...":                                         "\n Beende, transkribiere den Rest...",
+		"❌ Error recording from %s: %v\n%s":                                             "❌ Fehler bei der Aufnahme von %s: %v\n%s",
+		" Select the loopback device of your system with --device and --input-format, see \"Live Transcription\" in the README": " Wähle das Loopback-Gerät deines Systems mit --device und --input-format, siehe \"Live Transcription\" in der README",
+		"Nothing was transcribed.": "Es wurde nichts transkribiert.",
+		"not a pindar state archive (expected a .tar.gz or .tar.zst written by pindar export-state)":                  "kein pindar-Zustandsarchiv (erwartet wird eine mit pindar export-state geschriebene .tar.gz oder .tar.zst)",
+		"the archive contains an unexpected entry %q":                                                                 "das Archiv enthält einen unerwarteten Eintrag %q",
+		"❌ Error exporting state: %v\n":                                                                               "❌ Fehler beim Exportieren des Zustands: %v\n",
+		"Nothing to export, %s is empty.\n":                                                                           "Nichts zu exportieren, %s ist leer.\n",
+		"💾 State (%d files) saved to: %s\n":                                                                           "💾 Zustand (%d Dateien) gespeichert unter: %s\n",
+		"⚠️  The archive contains your API key if it is saved in the config file, keep it private.":                   "⚠️  Das Archiv enthält deinen API-Schlüssel, falls er in der Konfigurationsdatei gespeichert ist. Halte es privat.",
+		"❌ Error importing state: %v\n":                                                                               "❌ Fehler beim Importieren des Zustands: %v\n",
+		"❌ These files already exist in %s: %s (use --force to replace them)\n":                                       "❌ Diese Dateien existieren bereits in %s: %s (mit --force ersetzen)\n",
+		"✅ Imported %d files into %s\n":                                                                               "✅ %d Dateien nach %s importiert\n",
+		"❌ Post-hook failed: %v\n":                                                                                    "❌ Post-Hook fehlgeschlagen: %v\n",
+		"%s does not define a function transform(transcript)":                                                         "%s definiert keine Funktion transform(transcript)",
+		"%s: transform must return the transcript table, not a %s":                                                    "%s: transform muss die Transkript-Tabelle zurückgeben, nicht %s",
+		"%s: invalid segments: %v":                                                                                    "%s: ungültige Segmente: %v",
+		"unexpected table key %s":                                                                                     "unerwarteter Tabellenschlüssel %s",
+		"a %s can't be part of a transcript":                                                                          "%s kann nicht Teil eines Transkripts sein",
+		"%s must not be a %s":                                                                                         "%s darf kein %s sein",
+		"❌ Error running script: %v\n":                                                                                "❌ Fehler beim Ausführen des Skripts: %v\n",
+		"unknown placeholder %s in --output-dir, use {year}, {month}, {day} or {date}":                                "unbekannter Platzhalter %s in --output-dir, verwende {year}, {month}, {day} oder {date}",
+		"the output directory %s is a file":                                                                           "das Ausgabeverzeichnis %s ist eine Datei",
+		"the output directory %s does not exist (leave out --no-create-dirs to create it)":                            "das Ausgabeverzeichnis %s existiert nicht (ohne --no-create-dirs wird es angelegt)",
+		" Created output directory %s\n":                                                                              " Ausgabeverzeichnis %s angelegt\n",
+		"the transcript has schema version %d, but this pindar only reads up to version %d; update pindar":            "das Transkript hat die Schemaversion %d, aber dieses pindar liest nur bis Version %d; aktualisiere pindar",
+		"no typographic conventions for %s, only for: %s":                                                             "keine typografischen Konventionen für %s, nur für: %s",
+		"\nSummary of %d files:\n":                                                                                    "\nZusammenfassung von %d Dateien:\n",
+		"   Transcribed:    %d, %d failed\n":                                                                          "   Transkribiert:  %d, %d fehlgeschlagen\n",
+		"   Audio:          %.1f minutes\n":                                                                           "   Audio:          %.1f Minuten\n",
+		"   Estimated cost: $%.2f\n":                                                                                  "   Geschätzte Kosten: $%.2f\n",
+		"   Average time:   %s per file\n":                                                                            "   Durchschnittliche Dauer: %s pro Datei\n",
+		"❌ Error writing summary: %v\n":                                                                               "❌ Fehler beim Schreiben der Zusammenfassung: %v\n",
+		"💾 Summary saved to: %s\n":                                                                                    "💾 Zusammenfassung gespeichert unter: %s\n",
+		"⚠️  Could not write job report: %v\n":                                                                        "⚠️  Auftragsbericht konnte nicht geschrieben werden: %v\n",
+		"--summary only applies to --manifest, --url-list and --session runs":                                         "--summary gilt nur für Läufe mit --manifest, --url-list und --session",
+		"%s must be auto, always or never, not %q":                                                                    "%s muss auto, always oder never sein, nicht %q",
+		" Retrying %d likely hallucinated segments...\n":                                                              " Transkribiere %d wahrscheinlich halluzinierte Segmente erneut...\n",
+		" Removed a likely hallucination at %s, the retry heard no speech: %s\n":                                      " Wahrscheinliche Halluzination bei %s entfernt, der erneute Versuch hörte keine Sprache: %s\n",
+		" Replaced a likely hallucination at %s: %s → %s\n":                                                           " Wahrscheinliche Halluzination bei %s ersetzt: %s → %s\n",
+		" Removed a likely hallucination at %s (%s): %s\n":                                                            " Wahrscheinliche Halluzination bei %s entfernt (%s): %s\n",
+		"⚠️  Likely hallucination at %s (%s): %s\n":                                                                   "⚠️  Wahrscheinliche Halluzination bei %s (%s): %s\n",
+		"unknown --hallucinations mode %q, use %s, %s or %s":                                                          "unbekannter --hallucinations-Modus %q, verwende %s, %s oder %s",
+		"--idle must be at least 1 second":                                                                            "--idle muss mindestens 1 Sekunde betragen",
+		"❌ %s does not exist, start the recording first\n":                                                            "❌ %s existiert nicht, starte zuerst die Aufnahme\n",
+		" Following %s, transcribing every %d seconds until nothing is added for %d seconds. Press Ctrl+C to stop.\n": " Verfolge %s, transkribiere alle %d Sekunden, bis %d Sekunden lang nichts hinzukommt. Mit Strg+C beenden.\n",
+		"❌ Error reading %s: %v\n%s":                                                                                  "❌ Fehler beim Lesen von %s: %v\n%s",
+		"\n The recording has ended, transcribing the rest...":                                                        "\n Die Aufnahme ist beendet, transkribiere den Rest...",
+		"invalid track %q, expected name=file":                                                                        "ungültige Spur %q, erwartet wird Name=Datei",
+		"%s has more than one track":                                                                                  "%s hat mehr als eine Spur",
+		"--tracks needs the recordings of at least two speakers":                                                      "--tracks braucht die Aufnahmen von mindestens zwei Sprechern",
+		"pass either an audio file or --tracks, not both":                                                             "Gib entweder eine Audiodatei oder --tracks an, nicht beides",
+		"--split-call and --tracks can't be combined":                                                                 "--split-call und --tracks können nicht kombiniert werden",
+		" Transcribing the tracks of %d speakers separately...\n":                                                     " Transkribiere die Spuren von %d Sprechern einzeln...\n",
+		"%s is a %s video, pass --yes to transcribe it":                                                               "%s ist ein Video mit %s, gib --yes an, um es zu transkribieren",
+		" %s is a %s video. Extract its audio and transcribe it? [y/N] ":                                              " %s ist ein Video mit %s. Ton extrahieren und transkribieren? [j/N] ",
+		"transcription cancelled":                                                                                     "Transkription abgebrochen",
+		" Extracting the audio of the %s video...\n":                                                                  " Extrahiere den Ton des Videos mit %s...\n",
+		" Extracted %s of audio from the %s video\n":                                                                  " %s Ton aus dem Video mit %s extrahiert\n",
+		"❌ Failing because of %d warnings (--fail-on-warnings)\n":                                                     "❌ Abbruch wegen %d Warnungen (--fail-on-warnings)\n",
+		"unknown format %q, use one of: %s":                                                                           "unbekanntes Format %q, verwende eines von: %s",
+		"%s output needs word timestamps, which the transcript doesn't have":                                          "Die Ausgabe %s braucht Wort-Zeitstempel, die das Transkript nicht hat",
+		"%s output needs timestamps, which the transcript doesn't have":                                               "Die Ausgabe %s braucht Zeitstempel, die das Transkript nicht hat",
+		"no output format given":                                                                                      "kein Ausgabeformat angegeben",
+		"❌ Not rendering %s, it would overwrite %s; pass --output-dir or --output-name\n":                             "❌ %s wird nicht erzeugt, es würde %s überschreiben; gib --output-dir oder --output-name an\n",
+		"⚠️  The responses kept by --keep-raw contain the names --anonymize replaces":                                 "⚠️  Die von --keep-raw aufbewahrten Antworten enthalten die Namen, die --anonymize ersetzt",
+		"❌ Error saving raw responses: %v\n":                                                                          "❌ Fehler beim Speichern der Rohantworten: %v\n",
+		"💾 Raw responses saved to: %s\n":                                                                              "💾 Rohantworten gespeichert unter: %s\n",
+		"pass either an audio file, --manifest, --url-list or --session":                                              "Gib entweder eine Audiodatei, --manifest, --url-list oder --session an",
+		"%s saves a file per recording, which --session doesn't keep; transcribe the takes with --manifest instead":   "%s speichert eine Datei pro Aufnahme, die --session nicht behält; transkribieren Sie die Takes stattdessen mit --manifest",
+		"❌ Error reading session: %v\n":                                                                               "❌ Fehler beim Lesen der Session: %v\n",
+		"⚠️  Skipping %s, its name has no take number\n":                                                              "⚠️  Überspringe %s, der Name enthält keine Take-Nummer\n",
+		"❌ No takes found in %s (expected names like take_03_vocal.wav)\n":                                            "❌ Keine Takes in %s gefunden (erwartet werden Namen wie take_03_vocal.wav)\n",
+		"❌ Error running session: %v\n":                                                                               "❌ Fehler beim Ausführen der Session: %v\n",
+		"❌ Error writing session log: %v\n":                                                                           "❌ Fehler beim Schreiben des Session-Protokolls: %v\n",
+		"\n💾 Session log of %d takes saved to: %s\n":                                                                  "\n💾 Session-Protokoll mit %d Takes gespeichert unter: %s\n",
+		"   Copy the files in %s into Anki's collection.media folder, then import the deck with File > Import\n":      "   Kopiere die Dateien aus %s in Ankis Ordner collection.media und importiere das Deck dann über Datei > Importieren\n",
+		"❌ Error recording corrections: %v\n":                                                                         "❌ Fehler beim Aufzeichnen der Korrekturen: %v\n",
+		"❌ Error reading corrections log: %v\n":                                                                       "❌ Fehler beim Lesen des Korrekturprotokolls: %v\n",
+		"❌ Error reading transcript: %v\n":                                                                            "❌ Fehler beim Lesen der Transkription: %v\n",
+		"❌ Error reading mapping file: %v\n":                                                                          "❌ Fehler beim Lesen der Zuordnungsdatei: %v\n",
+		"❌ Error reading passphrase: %v\n":                                                                            "❌ Fehler beim Lesen der Passphrase: %v\n",
+		"❌ Error decrypting mapping file: %v\n":                                                                       "❌ Fehler beim Entschlüsseln der Zuordnungsdatei: %v\n",
+		"wrong passphrase or corrupted mapping file":                                                                  "falsche Passphrase oder beschädigte Zuordnungsdatei",
+		"no terminal to ask for the passphrase, set %s":                                                               "kein Terminal für die Abfrage der Passphrase vorhanden, bitte %s setzen",
+		"passphrase cannot be empty":                                                                                  "die Passphrase darf nicht leer sein",
+		"passphrases do not match":                                                                                    "die Passphrasen stimmen nicht überein",
+		"No corrections recorded yet. Import corrected transcripts with: pindar import-corrections <edited> --original <original> --record\n": "Noch keine Korrekturen aufgezeichnet. Korrigierte Transkriptionen importieren mit: pindar import-corrections <bearbeitet> --original <original> --record\n",
+		"❌ Audio file too long: The audio duration exceeds the 25-minute limit for this model.\n":                                             "❌ Audiodatei zu lang: Die Dauer überschreitet das 25-Minuten-Limit dieses Modells.\n",
+		"💡 Suggestions:\n": "💡 Vorschläge:\n",
+		"   • Split the audio into shorter segments (< 25 minutes each)\n":         "   • Audio in kürzere Abschnitte aufteilen (jeweils < 25 Minuten)\n",
+		"   • Use audio editing software to create multiple files\n":               "   • Mit einem Audioeditor mehrere Dateien erstellen\n",
+		"   • Consider using a different transcription service for longer files\n": "   • Für längere Dateien einen anderen Transkriptionsdienst verwenden\n",
+		"❌ API Key Error: Invalid or missing OpenAI API key.\n":                    "❌ API-Schlüssel-Fehler: Ungültiger oder fehlender OpenAI-API-Schlüssel.\n",
+		"💡 Please check your API key and try again.\n":                             "💡 Bitte API-Schlüssel prüfen und erneut versuchen.\n",
+		" Uploading: %.1f of %.1f MB (%s elapsed)\n":                               " Hochladen: %.1f von %.1f MB (%s vergangen)\n",
+		" Waiting for the transcription (%s elapsed, giving up after %s)\n":        " Warte auf die Transkription (%s vergangen, Abbruch nach %s)\n",
+		"❌ Timeout: %v\n": "❌ Zeitüberschreitung: %v\n",
+		"💡 The time limit grows with the audio duration. Check your connection, or split the recording into shorter files.\n": "💡 Das Zeitlimit wächst mit der Audiodauer. Prüfen Sie Ihre Verbindung oder teilen Sie die Aufnahme in kürzere Dateien auf.\n",
+		"❌ Rate Limit/Quota Error: API usage limit reached.\n":                                                                "❌ Limit-/Kontingentfehler: API-Nutzungslimit erreicht.\n",
+		"💡 Please wait a moment and try again, or check your OpenAI account billing.\n":                                       "💡 Bitte kurz warten und erneut versuchen oder die Abrechnung des OpenAI-Kontos prüfen.\n",
+		"❌ Error calling OpenAI API: %v\n":                                                                                    "❌ Fehler beim Aufruf der OpenAI-API: %v\n",
+		"unknown language %q, did you mean %s?":                                                                               "unbekannte Sprache %q, meinten Sie %s?",
+		"unknown language %q, use an ISO-639-1 code like \"en\" or a name like \"german\"":                                    "unbekannte Sprache %q, bitte einen ISO-639-1-Code wie \"de\" oder einen Namen wie \"german\" angeben",
+		" or ":                                 " oder ",
+		"unknown data policy %q, use %s or %s": "unbekannte Datenschutzrichtlinie %q, bitte %s oder %s verwenden",
+		"unknown provider %q, use %s or %s":    "unbekannter Anbieter %q, bitte %s oder %s verwenden",
+		"--record-cassette and --replay-cassette can't be combined": "--record-cassette und --replay-cassette können nicht kombiniert werden",
+		"OpenAI can't apply zero data retention per request. If your organization has a zero data retention agreement, set \"zero_data_retention\": true in the config file": "OpenAI kann eine Nicht-Speicherung nicht pro Anfrage zusichern. Falls Ihre Organisation eine Zero-Data-Retention-Vereinbarung hat, setzen Sie \"zero_data_retention\": true in der Konfigurationsdatei",
+
+		// API key setup
+		"no OpenAI API key found; run \"pindar init\" to set one up, or pass --api-key or set OPENAI_API_KEY": "kein OpenAI-API-Schlüssel gefunden; richten Sie mit \"pindar init\" einen ein, übergeben Sie --api-key oder setzen Sie OPENAI_API_KEY",
+		"OpenAI API key: ":                  "OpenAI-API-Schlüssel: ",
+		"\nFalling back to regular input: ": "\nWechsle zur normalen Eingabe: ",
+		"❌ Error reading API key: %v\n":     "❌ Fehler beim Lesen des API-Schlüssels: %v\n",
+		"💾 Config saved to: %s\n":           "💾 Konfiguration gespeichert unter: %s\n",
+		"💡 Run \"pindar doctor\" to check the key and the rest of the setup.": "💡 Führen Sie \"pindar doctor\" aus, um den Schlüssel und die übrige Einrichtung zu prüfen.",
+		"Default model":         "Standardmodell",
+		"Default output format": "Standard-Ausgabeformat",
+		"⚠️  API key: none found; pass --api-key or set OPENAI_API_KEY before transcribing": "⚠️  API-Schlüssel: keiner gefunden; übergeben Sie vor dem Transkribieren --api-key oder setzen Sie OPENAI_API_KEY",
+		"✅ API key: keeping the key in the config file":                                     "✅ API-Schlüssel: der Schlüssel in der Konfigurationsdatei bleibt",
+		"✅ API key: saving the key given with --api-key":                                    "✅ API-Schlüssel: der mit --api-key übergebene Schlüssel wird gespeichert",
+		"✅ API key: using OPENAI_API_KEY from the environment, it isn't saved":              "✅ API-Schlüssel: OPENAI_API_KEY aus der Umgebung wird verwendet, aber nicht gespeichert",
+		"❌ Error saving config: %v\n":                                                       "❌ Fehler beim Speichern der Konfiguration: %v\n",
+		"\n  Minutes transcribed per week:":                                                 "\n  Transkribierte Minuten pro Woche:",
+		"\n  Models:":                                                                       "\n  Modelle:",
+		"  %-24s %4.0f%%  %d runs, %.1f minutes\n":                                          "  %-24s %4.0f%%  %d Läufe, %.1f Minuten\n",
+		"\n  Corrections:":                                                                  "\n  Korrekturen:",
+		"  none imported yet (pindar import-corrections --record)":                          "  noch keine importiert (pindar import-corrections --record)",
+		"  %.1f%% average correction rate over %d transcripts\n":                            "  %.1f%% durchschnittliche Korrekturrate über %d Transkripte\n",
+		"pass either --enable or --disable, not both":                                       "Geben Sie entweder --enable oder --disable an, nicht beides",
+		"--weeks must be at least 1":                                                        "--weeks muss mindestens 1 sein",
+		"❌ Error reading usage log: %v\n":                                                   "❌ Fehler beim Lesen des Nutzungsprotokolls: %v\n",
+		"No transcriptions recorded yet.":                                                   "Noch keine Transkriptionen erfasst.",
+		"Usage statistics are off. Turn them on with: pindar usage --enable":                "Nutzungsstatistiken sind aus. Schalten Sie sie ein mit: pindar usage --enable",
+		"\n⚠️  Usage statistics are off, the numbers end where they were turned off":        "\n⚠️  Nutzungsstatistiken sind aus, die Zahlen enden mit dem Ausschalten",
+		"✅ Usage statistics are recorded from now on. They stay on this machine.":           "✅ Nutzungsstatistiken werden ab jetzt erfasst. Sie bleiben auf diesem Rechner.",
+		"✅ Usage statistics are no longer recorded.":                                        "✅ Nutzungsstatistiken werden nicht mehr erfasst.",
+		"⚠️  Could not record usage: %v\n":                                                  "⚠️  Nutzung konnte nicht erfasst werden: %v\n",
+
+		// Temperature fallback
+		"   Fallback:    %s\n": "   Rückfall:    %s\n",
+		" The result looks unreliable (%s), transcribing again at temperature %.1f\n": " Das Ergebnis wirkt unzuverlässig (%s), erneute Transkription mit Temperatur %.1f\n",
+		"avg logprob %.2f":       "durchschn. Logprob %.2f",
+		"compression ratio %.1f": "Kompressionsrate %.1f",
+		"invalid --temperature-fallback temperature %q, use numbers between 0 and 1 or whisper": "ungültige Temperatur %q für --temperature-fallback, verwenden Sie Zahlen zwischen 0 und 1 oder whisper",
+		"⚠️  The result still looks unreliable (%s) at the highest fallback temperature\n":      "⚠️  Das Ergebnis wirkt auch mit der höchsten Fallback-Temperatur unzuverlässig (%s)\n",
+
+		// Name mapping
+		"Passphrase for the name mapping: ": "Passphrase für die Namenszuordnung: ",
+		"Repeat passphrase: ":               "Passphrase wiederholen: ",
+
+		// Encrypted queue
+		"--queue takes a single audio file":    "--queue nimmt eine einzelne Audiodatei",
+		"❌ Error queueing %s: %v\n":            "❌ Fehler beim Einreihen von %s: %v\n",
+		"🔒 Queued %s as %s in %s\n":            "🔒 %s als %s in %s eingereiht\n",
+		"Passphrase for the queue: ":           "Passphrase für die Warteschlange: ",
+		"not a pindar %s bundle":               "kein pindar-Bundle vom Typ %s",
+		"wrong passphrase or corrupted bundle": "falsche Passphrase oder beschädigtes Bundle",
+		"the AWS CLI (aws) is needed for an S3 queue, install it and configure its credentials": "für eine S3-Warteschlange wird die AWS CLI (aws) benötigt, bitte installieren und ihre Zugangsdaten einrichten",
+		"--summary only applies to draining jobs, not to --collect":                             "--summary gilt nur für das Abarbeiten von Aufträgen, nicht für --collect",
+		"❌ Error reading queue: %v\n":                                                           "❌ Fehler beim Lesen der Warteschlange: %v\n",
+		"❌ Error draining queue: %v\n":                                                          "❌ Fehler beim Abarbeiten der Warteschlange: %v\n",
+		"No jobs queued in %s.\n":                                                               "Keine Aufträge in %s.\n",
+		"No results returned yet.":                                                              "Noch keine Ergebnisse zurückgegeben.",
+		"✅ %s: saved %d files to %s\n":                                                          "✅ %s: %d Dateien in %s gespeichert\n",
+		"\n✅ Transcribed all %d queued jobs, the results are sealed in %s\n":                    "\n✅ Alle %d Aufträge transkribiert, die Ergebnisse liegen verschlüsselt in %s\n",
+		"unexpected argument %q in the job's options":                                           "unerwartetes Argument %q in den Optionen des Auftrags",
+		"%s is missing its value in the job's options":                                          "%s fehlt der Wert in den Optionen des Auftrags",
+		"%s can't be queued, drain only runs jobs with %s":                                      "%s kann nicht eingereiht werden, drain führt Aufträge nur mit %s aus",
+		"%s already exists, collect into another --output-dir":                                  "%s existiert bereits, bitte in ein anderes --output-dir abholen",
+
+		// Transcript diff
+		"%d words of the official transcript, %d differences (%.1f%% word error rate)":                                          "%d Wörter im offiziellen Transkript, %d Abweichungen (%.1f%% Wortfehlerrate)",
+		"💡 The machine transcript has no timestamps; compare an srt, vtt or verbose_json file to see where the differences are": "💡 Das maschinelle Transkript hat keine Zeitstempel; vergleichen Sie eine srt-, vtt- oder verbose_json-Datei, um zu sehen, wo die Abweichungen liegen",
+		"only in the machine transcript":  "nur im maschinellen Transkript",
+		"only in the official transcript": "nur im offiziellen Transkript",
+		"❌ Error writing diff: %v\n":      "❌ Fehler beim Schreiben des Vergleichs: %v\n",
+		"💾 Diff saved to: %s\n":           "💾 Vergleich gespeichert unter: %s\n",
+
+		// Tracing
+		"unknown trace exporter %q, use %s, %s or %s":                                       "unbekannter Trace-Exporter %q, verwenden Sie %s, %s oder %s",
+		"⚠️  Ignoring %s of OTEL_TRACES_EXPORTER, pindar exports to one of %s or %s only\n": "⚠️  %s aus OTEL_TRACES_EXPORTER wird ignoriert, pindar exportiert nur zu einem von %s oder %s\n",
+
+		// Config validation
+		"line %d, column %d: %v":                  "Zeile %d, Spalte %d: %v",
+		"; remove the comma after the last entry": "; entfernen Sie das Komma nach dem letzten Eintrag",
+		"an object":                        "ein Objekt",
+		"a list":                           "eine Liste",
+		"; wrap it in [ ]":                 "; setzen Sie den Wert in [ ]",
+		"a string":                         "ein String",
+		"; put the value in quotes":        "; setzen Sie den Wert in Anführungszeichen",
+		"true or false":                    "true oder false",
+		"; remove the quotes":              "; entfernen Sie die Anführungszeichen",
+		"a number":                         "eine Zahl",
+		"line %d: %s must be %s, not %s%s": "Zeile %d: %s muss %s sein, nicht %s%s",
+		"line %d: %s is no longer supported, rename it to %s": "Zeile %d: %s wird nicht mehr unterstützt, benennen Sie es in %s um",
+		"line %d: unknown key %s, did you mean %s?":           "Zeile %d: unbekannter Schlüssel %s, meinten Sie %s?",
+		"line %d: unknown key %s, known keys are %s":          "Zeile %d: unbekannter Schlüssel %s, bekannte Schlüssel sind %s",
+
+		// URL lists
+		"pass either an audio file, --manifest or --url-list": "Gib entweder eine Audiodatei, --manifest oder --url-list an",
+		"line %d: %q is not an http or https URL":             "Zeile %d: %q ist keine http- oder https-URL",
+		"the URL list has no URLs":                            "die URL-Liste enthält keine URLs",
+		"no data for %s":                                      "seit %s keine Daten",
+		"❌ Error reading URL list: %v\n":                      "❌ Fehler beim Lesen der URL-Liste: %v\n",
+		"\n✅ Transcribed all %d URLs of the list\n":           "\n✅ Alle %d URLs der Liste transkribiert\n",
+
+		// Speaker turns
+		"Speaker 1": "Sprecher 1",
+		"Speaker 2": "Sprecher 2",
+		"--turns can't be combined with --split-call or --tracks, which label the speakers already": "--turns kann nicht mit --split-call oder --tracks kombiniert werden, die die Sprecher bereits zuordnen",
+		" Detecting speaker turns from the audio...":                                                " Erkenne Sprecherwechsel im Audio...",
+		"❌ Error detecting speaker turns: %v\n":                                                     "❌ Fehler beim Erkennen der Sprecherwechsel: %v\n",
+		" Found %d speaker turns\n":                                                                 " %d Redebeiträge gefunden\n",
+		" Pairing interview questions and answers by the speaker turns...":                          " Ordne Fragen und Antworten des Interviews nach den Sprecherwechseln zu...",
+
+		// Digest
+		"%s has no segments":                         "%s hat keine Segmente",
+		"--quotes can't be negative":                 "--quotes darf nicht negativ sein",
+		"❌ Error reading folder: %v\n":               "❌ Fehler beim Lesen des Ordners: %v\n",
+		"❌ No recordings found in %s\n":              "❌ Keine Aufnahmen in %s gefunden\n",
+		"❌ Error running digest: %v\n":               "❌ Fehler beim Erstellen des Digests: %v\n",
+		" Reading the transcript %s\n":               " Lese das Transkript %s\n",
+		" Summarizing with %s...\n":                  " Fasse mit %s zusammen...\n",
+		"❌ Error writing digest: %v\n":               "❌ Fehler beim Schreiben des Digests: %v\n",
+		"\n💾 Digest of %d recordings saved to: %s\n": "\n💾 Digest von %d Aufnahmen gespeichert unter: %s\n",
+	},
+}
+
+// supportedUILanguages lists the languages available for --ui-lang
+func supportedUILanguages() []string {
+	languages := []string{"en"}
+	for language := range translations {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// setUILanguage selects the language for the CLI's own messages
+func setUILanguage(language string) error {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" || language == "en" {
+		uiLanguage = "en"
+		return nil
+	}
+	if _, ok := translations[language]; !ok {
+		return fmt.Errorf("unsupported UI language %q, available: %s", language, strings.Join(supportedUILanguages(), ", "))
+	}
+	uiLanguage = language
+	return nil
+}
+
+// tr translates a CLI message into the UI language, falling back to English
+func tr(message string) string {
+	if translated, ok := translations[uiLanguage][message]; ok {
+		return translated
+	}
+	return message
+}
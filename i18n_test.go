@@ -0,0 +1,103 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var formatVerbPattern = regexp.MustCompile(`%%|%[-+# 0-9.]*[a-zA-Z]`)
+
+// translatedMessages collects the literal messages passed to tr() in the non-test sources
+func translatedMessages(t *testing.T) map[string]bool {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatalf("Failed to list source files: %v", err)
+	}
+
+	messages := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", file, err)
+		}
+
+		ast.Inspect(parsed, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 1 {
+				return true
+			}
+			if ident, ok := call.Fun.(*ast.Ident); !ok || ident.Name != "tr" {
+				return true
+			}
+			if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				message, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					t.Fatalf("Failed to unquote %s: %v", lit.Value, err)
+				}
+				messages[message] = true
+			}
+			return true
+		})
+	}
+	return messages
+}
+
+func TestTranslationsComplete(t *testing.T) {
+	messages := translatedMessages(t)
+	if len(messages) == 0 {
+		t.Fatal("No translated messages found")
+	}
+
+	for language, catalog := range translations {
+		for message := range messages {
+			translated, ok := catalog[message]
+			if !ok {
+				t.Errorf("[%s] missing translation for %q", language, message)
+				continue
+			}
+			expectedVerbs := formatVerbPattern.FindAllString(message, -1)
+			actualVerbs := formatVerbPattern.FindAllString(translated, -1)
+			if !reflect.DeepEqual(expectedVerbs, actualVerbs) {
+				t.Errorf("[%s] format verbs differ for %q: expected %v, got %v", language, message, expectedVerbs, actualVerbs)
+			}
+		}
+		for message := range catalog {
+			if !messages[message] {
+				t.Errorf("[%s] unused translation for %q", language, message)
+			}
+		}
+	}
+}
+
+func TestSetUILanguage(t *testing.T) {
+	defer setUILanguage("en")
+
+	if err := setUILanguage("DE"); err != nil {
+		t.Fatalf("setUILanguage(DE) failed: %v", err)
+	}
+	if result := tr(" Starting transcription..."); result != " Transkription wird gestartet..." {
+		t.Errorf("Expected German message, got %q", result)
+	}
+	if result := tr("message without translation"); result != "message without translation" {
+		t.Errorf("Expected English fallback, got %q", result)
+	}
+
+	if err := setUILanguage("xx"); err == nil {
+		t.Error("Expected an error for an unsupported UI language")
+	}
+
+	if err := setUILanguage(""); err != nil || tr(" Starting transcription...") != " Starting transcription..." {
+		t.Error("Expected English messages after resetting the UI language")
+	}
+}
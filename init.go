@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// InitArgs defines the arguments of the init subcommand
+type InitArgs struct {
+	APIKey         string `arg:"--api-key" help:"OpenAI API key to save (prompted for if there is none yet and OPENAI_API_KEY isn't set)"`
+	Model          string `arg:"--model" help:"Default model for transcriptions without --model (default: gpt-4o-transcribe)"`
+	Format         string `arg:"--format" help:"Default output format for transcriptions without --format (default: text)"`
+	Org            string `arg:"--org" help:"OpenAI organization ID to bill usage to"`
+	Project        string `arg:"--project" help:"OpenAI project ID to bill usage to"`
+	FFmpegPath     string `arg:"--ffmpeg-path" help:"ffmpeg binary to check and save in the config"`
+	NonInteractive bool   `arg:"--non-interactive" help:"Never ask, only use the options and the environment (the default without a terminal)"`
+}
+
+// initPrompter asks the setup questions, or answers them with their defaults
+// when there is no one to ask
+type initPrompter struct {
+	interactive bool
+	reader      *bufio.Reader
+}
+
+// ask asks a question and returns the answer, or the default for an empty
+// answer and in non-interactive mode
+func (p *initPrompter) ask(question, defaultAnswer string) (string, error) {
+	if !p.interactive {
+		return defaultAnswer, nil
+	}
+	uiPrintf("%s [%s]: ", question, defaultAnswer)
+	answer, err := p.reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	if answer = strings.TrimSpace(answer); answer != "" {
+		return answer, nil
+	}
+	return defaultAnswer, nil
+}
+
+// validateInitFormat checks a default output format; formats that need a
+// source file, like html with its audio player, work as well
+func validateInitFormat(format string) error {
+	if !slices.Contains(outputFormats, format) {
+		return fmt.Errorf(tr("unknown format %q, use one of: %s"), format, strings.Join(outputFormats, ", "))
+	}
+	return nil
+}
+
+// setupAPIKey decides which key init saves. A key in the environment isn't
+// copied to the config, since it's already available to every run.
+func setupAPIKey(args InitArgs, config *Config, prompter *initPrompter) error {
+	switch {
+	case args.APIKey != "":
+		config.OpenAIAPIKey = args.APIKey
+		uiPrintln(tr("✅ API key: saving the key given with --api-key"))
+	case os.Getenv("OPENAI_API_KEY") != "":
+		uiPrintln(tr("✅ API key: using OPENAI_API_KEY from the environment, it isn't saved"))
+	case config.OpenAIAPIKey != "":
+		uiPrintln(tr("✅ API key: keeping the key in the config file"))
+	case prompter.interactive:
+		apiKey, err := promptForAPIKey()
+		if err != nil {
+			return err
+		}
+		config.OpenAIAPIKey = apiKey
+	default:
+		uiPrintln(tr("⚠️  API key: none found; pass --api-key or set OPENAI_API_KEY before transcribing"))
+	}
+	return nil
+}
+
+// runInit sets up pindar on a new machine: the API key, the default model
+// and format, and ffmpeg. It asks for what isn't given unless there is no
+// terminal or --non-interactive is passed, so package managers and
+// provisioning scripts can run it too.
+func runInit(argv []string) {
+	var args InitArgs
+	parseSubcommand("init", &args, argv)
+
+	config, err := loadConfig()
+	if err != nil {
+		uiPrintf(tr(" Error loading config: %v\n"), err)
+		os.Exit(1)
+	}
+	prompter := &initPrompter{
+		interactive: !args.NonInteractive && term.IsTerminal(int(os.Stdin.Fd())),
+		reader:      bufio.NewReader(os.Stdin),
+	}
+
+	if err := setupAPIKey(args, config, prompter); err != nil {
+		uiPrintf(tr("❌ Error reading API key: %v\n"), err)
+		os.Exit(1)
+	}
+
+	if config.Model, err = prompter.ask(tr("Default model"), cmp.Or(args.Model, config.Model, defaultModel)); err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if config.Format, err = prompter.ask(tr("Default output format"), cmp.Or(args.Format, config.Format, "text")); err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if err := validateInitFormat(config.Format); err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	config.Organization = cmp.Or(args.Org, config.Organization)
+	config.Project = cmp.Or(args.Project, config.Project)
+
+	if args.FFmpegPath != "" {
+		if config.FFmpeg == nil {
+			config.FFmpeg = &FFmpegConfig{}
+		}
+		config.FFmpeg.Path = args.FFmpegPath
+	}
+	if err := configureFFmpeg("", config.FFmpeg); err != nil {
+		uiPrintf(tr("❌ Invalid ffmpeg configuration: %v\n"), err)
+		os.Exit(1)
+	}
+	printCheck(checkTool("ffmpeg", ffmpegBinary, checkWarning, tr("Install ffmpeg, run \"pindar deps install-ffmpeg\", or point --ffmpeg-path at it")))
+
+	if err := saveConfig(config); err != nil {
+		uiPrintf(tr("❌ Error saving config: %v\n"), err)
+		os.Exit(1)
+	}
+	path, _ := getConfigFilePath()
+	uiPrintf(tr("💾 Config saved to: %s\n"), path)
+	uiPrintln(tr("💡 Run \"pindar doctor\" to check the key and the rest of the setup."))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestInitPrompterAsk(t *testing.T) {
+	prompter := &initPrompter{interactive: true, reader: bufio.NewReader(strings.NewReader("whisper-1\n\n"))}
+	if answer, err := prompter.ask("Default model", defaultModel); err != nil || answer != "whisper-1" {
+		t.Errorf("Expected the answer, got %q (%v)", answer, err)
+	}
+	if answer, err := prompter.ask("Default output format", "text"); err != nil || answer != "text" {
+		t.Errorf("Expected the default for an empty answer, got %q (%v)", answer, err)
+	}
+	if _, err := prompter.ask("Default output format", "text"); err == nil {
+		t.Error("Expected an error once the input ends")
+	}
+
+	prompter = &initPrompter{reader: bufio.NewReader(strings.NewReader("whisper-1\n"))}
+	if answer, _ := prompter.ask("Default model", defaultModel); answer != defaultModel {
+		t.Errorf("Expected the default without asking, got %q", answer)
+	}
+}
+
+func TestValidateInitFormat(t *testing.T) {
+	if err := validateInitFormat("srt"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := validateInitFormat("docx"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
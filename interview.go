@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// Speaker roles assigned to segments with --interview
+const (
+	roleInterviewer = "interviewer"
+	roleInterviewee = "interviewee"
+)
+
+// segmentRole is the speaker role the analysis model assigns to one segment
+type segmentRole struct {
+	Segment int    `json:"segment"`
+	Role    string `json:"role"`
+}
+
+// interviewTurn is a run of consecutive segments spoken by the same person
+type interviewTurn struct {
+	Role  string
+	Start float64
+	Text  string
+}
+
+// QAPair is an interviewer question with the interviewee's answer
+type QAPair struct {
+	Start    float64
+	Question string
+	Answer   string
+}
+
+// attributeSpeakers asks the analysis model who speaks each segment of a two-person interview.
+// pindar has no acoustic diarization, so without --turns the speakers are inferred from the text.
+func attributeSpeakers(ctx context.Context, client openai.Client, model string, segments []Segment) ([]string, error) {
+	instructions := `You identify speakers in two-person interview transcripts. The user sends numbered transcript ` +
+		`segments. Return JSON of the form {"segments": [{"segment": ..., "role": ...}]} with one entry per segment, ` +
+		`where "role" is "` + roleInterviewer + `" for the person asking the questions and "` + roleInterviewee +
+		`" for the person answering them.`
+
+	roles := make([]string, len(segments))
+	for start := 0; start < len(segments); start += analysisBatchSize {
+		end := min(start+analysisBatchSize, len(segments))
+
+		var answer struct {
+			Segments []segmentRole `json:"segments"`
+		}
+		if err := chatJSON(ctx, client, model, instructions, numberedSegments(segments[start:end]), &answer); err != nil {
+			return nil, err
+		}
+		for _, r := range answer.Segments {
+			role := strings.ToLower(strings.TrimSpace(r.Role))
+			if r.Segment >= 0 && r.Segment < end-start && (role == roleInterviewer || role == roleInterviewee) {
+				roles[start+r.Segment] = role
+			}
+		}
+	}
+	return roles, nil
+}
+
+// speakerRoles takes the roles from the speakers labeled with --turns. The
+// interviewer is named first, as in --speakers.
+func speakerRoles(segments []Segment, interviewer string) []string {
+	roles := make([]string, len(segments))
+	for i, segment := range segments {
+		roles[i] = roleInterviewee
+		if segment.Speaker == interviewer {
+			roles[i] = roleInterviewer
+		}
+	}
+	return roles
+}
+
+// groupTurns joins consecutive segments of the same role into turns. Segments
+// without a role are attributed to the previous speaker.
+func groupTurns(segments []Segment, roles []string) []interviewTurn {
+	var turns []interviewTurn
+	for i, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
+			continue
+		}
+		role := roles[i]
+		if len(turns) > 0 && (role == "" || role == turns[len(turns)-1].Role) {
+			turns[len(turns)-1].Text += " " + text
+			continue
+		}
+		if role == "" {
+			role = roleInterviewer
+		}
+		turns = append(turns, interviewTurn{Role: role, Start: segment.Start, Text: text})
+	}
+	return turns
+}
+
+// pairQuestions pairs each interviewer turn with the interviewee turn following
+// it. An answer before the first question stands on its own.
+func pairQuestions(turns []interviewTurn) []QAPair {
+	var pairs []QAPair
+	for _, turn := range turns {
+		if turn.Role == roleInterviewee && len(pairs) > 0 && pairs[len(pairs)-1].Answer == "" {
+			pairs[len(pairs)-1].Answer = turn.Text
+			continue
+		}
+		pair := QAPair{Start: turn.Start}
+		if turn.Role == roleInterviewer {
+			pair.Question = turn.Text
+		} else {
+			pair.Answer = turn.Text
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+// renderInterview formats Q&A pairs as Markdown. With the names of both
+// speakers, interviewer first, they label the turns instead of Q and A.
+func renderInterview(title string, pairs []QAPair, speakers []string) string {
+	question, answer := "Q", "A"
+	if len(speakers) >= 2 {
+		question, answer = speakers[0], speakers[1]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Interview: %s\n", title)
+	for _, pair := range pairs {
+		fmt.Fprintf(&b, "\n## [%s]\n\n", formatTimestamp(pair.Start))
+		if pair.Question != "" {
+			fmt.Fprintf(&b, "**%s:** %s\n", question, pair.Question)
+		}
+		if pair.Question != "" && pair.Answer != "" {
+			b.WriteString("\n")
+		}
+		if pair.Answer != "" {
+			fmt.Fprintf(&b, "**%s:** %s\n", answer, pair.Answer)
+		}
+	}
+	return b.String()
+}
+
+// saveInterview pairs the questions and answers of an interview and writes them
+// to a Markdown sidecar file
+func saveInterview(ctx context.Context, client openai.Client, args Args, originalFile string, transcript *Transcript) error {
+	var roles []string
+	if args.Turns {
+		uiPrintln(tr(" Pairing interview questions and answers by the speaker turns..."))
+		roles = speakerRoles(transcript.Segments, turnSpeakerNames(args.Speakers)[0])
+	} else {
+		uiPrintf(tr(" Pairing interview questions and answers with %s...\n"), args.AnalysisModel)
+		var err error
+		if roles, err = attributeSpeakers(ctx, client, args.AnalysisModel, transcript.Segments); err != nil {
+			return err
+		}
+	}
+	pairs := pairQuestions(groupTurns(transcript.Segments, roles))
+
+	interviewFile := sidecarFileName(args, originalFile, ".qa.md")
+	if err := os.WriteFile(interviewFile, []byte(rtlMarkdown(renderInterview(filepath.Base(originalFile), pairs, args.Speakers), transcript.Language)), 0644); err != nil {
+		return fmt.Errorf("failed to write Q&A file: %w", err)
+	}
+	uiPrintf(tr("💾 %d questions and answers saved to: %s\n"), len(pairs), interviewFile)
+	return nil
+}
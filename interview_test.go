@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupTurnsAndPairQuestions(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, Text: " Thanks for having me."},
+		{Start: 2, Text: " Welcome to the show."},
+		{Start: 4, Text: " How did you start?"},
+		{Start: 6, Text: " By accident, really."},
+		{Start: 9, Text: " I was a chemist."},
+		{Start: 12, Text: " And today?"},
+	}
+	roles := []string{roleInterviewee, roleInterviewer, "", roleInterviewee, roleInterviewee, roleInterviewer}
+
+	expected := []QAPair{
+		{Start: 0, Answer: "Thanks for having me."},
+		{Start: 2, Question: "Welcome to the show. How did you start?", Answer: "By accident, really. I was a chemist."},
+		{Start: 12, Question: "And today?"},
+	}
+	result := pairQuestions(groupTurns(segments, roles))
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestRenderInterview(t *testing.T) {
+	pairs := []QAPair{{Start: 65, Question: "How did you start?", Answer: "By accident."}}
+	expected := "# Interview: talk.mp3\n\n## [00:01:05]\n\n**Q:** How did you start?\n\n**A:** By accident.\n"
+	result := renderInterview("talk.mp3", pairs, nil)
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+
+	expected = "# Interview: talk.mp3\n\n## [00:01:05]\n\n**Aoife:** How did you start?\n\n**Siobhán:** By accident.\n"
+	result = renderInterview("talk.mp3", pairs, []string{"Aoife", "Siobhán"})
+	if result != expected {
+		t.Errorf("Expected the speaker names as labels %q, got %q", expected, result)
+	}
+}
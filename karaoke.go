@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// assHeader is the script header of ASS output. The primary colour (yellow) is
+// the highlight a word gets once it is sung, the secondary colour (white) the
+// colour before.
+const assHeader = `[Script Info]
+ScriptType: v4.00+
+PlayResX: 1920
+PlayResY: 1080
+WrapStyle: 0
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,Arial,64,&H0000FFFF,&H00FFFFFF,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,3,0,2,40,40,60,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+`
+
+// segmentWords assigns each word to the segment containing its midpoint. Words
+// outside all segments go to the nearest preceding one.
+func segmentWords(segments []Segment, words []Word) [][]Word {
+	lines := make([][]Word, len(segments))
+	if len(segments) == 0 {
+		return lines
+	}
+
+	current := 0
+	for _, word := range words {
+		mid := (word.Start + word.End) / 2
+		for current+1 < len(segments) && mid >= segments[current+1].Start {
+			current++
+		}
+		lines[current] = append(lines[current], word)
+	}
+	return lines
+}
+
+// wordMatchLookahead is how many word timestamps ahead alignWords looks for a
+// word of the text, which skips the words a rewrite replaced or removed
+const wordMatchLookahead = 4
+
+// alignWords times the words of a segment's text with the segment's word
+// timestamps. The timestamps only lend their timing, so text rewritten after
+// transcription, like the placeholders of --anonymize, appears as rewritten.
+// Words without a timestamp of their own share the time of the words skipped
+// for them.
+func alignWords(text string, words []Word) []Word {
+	if len(words) == 0 {
+		return nil
+	}
+	fields := strings.Fields(text)
+	aligned := make([]Word, len(fields))
+	next, pending := 0, 0
+	// place spreads the fields from pending up to end evenly over a time span
+	place := func(end int, from, to float64) {
+		step := (to - from) / float64(max(end-pending, 1))
+		for i := pending; i < end; i++ {
+			n := float64(i - pending)
+			aligned[i] = Word{Word: fields[i], Start: from + step*n, End: from + step*(n+1)}
+		}
+		pending = end
+	}
+	previousEnd := func(fallback float64) float64 {
+		if pending > 0 {
+			return aligned[pending-1].End
+		}
+		return fallback
+	}
+
+	for i, field := range fields {
+		match := -1
+		for k := next; k < min(next+wordMatchLookahead, len(words)); k++ {
+			if normalizeWord(field) == normalizeWord(words[k].Word) {
+				match = k
+				break
+			}
+		}
+		if match < 0 {
+			continue
+		}
+		if match > next {
+			place(i, words[next].Start, words[match-1].End)
+		} else {
+			place(i, previousEnd(words[match].Start), words[match].Start)
+		}
+		aligned[i] = Word{Word: field, Start: words[match].Start, End: words[match].End}
+		next, pending = match+1, i+1
+	}
+	if pending < len(fields) {
+		if next < len(words) {
+			place(len(fields), words[next].Start, words[len(words)-1].End)
+		} else {
+			end := previousEnd(words[len(words)-1].End)
+			place(len(fields), end, end)
+		}
+	}
+	return aligned
+}
+
+// centiseconds rounds seconds to whole hundredths, the resolution of ASS and LRC
+func centiseconds(seconds float64) int {
+	return int(math.Round(max(seconds, 0) * 100))
+}
+
+// formatASSTime renders seconds as H:MM:SS.cc
+func formatASSTime(seconds float64) string {
+	cs := centiseconds(seconds)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
+
+// formatLRCTime renders seconds as MM:SS.cc
+func formatLRCTime(seconds float64) string {
+	cs := centiseconds(seconds)
+	return fmt.Sprintf("%02d:%02d.%02d", cs/6000, cs/100%60, cs%100)
+}
+
+// escapeASS keeps text from being read as ASS override tags or line breaks
+func escapeASS(text string) string {
+	return strings.NewReplacer("{", "(", "}", ")", "\n", " ", `\`, "/").Replace(text)
+}
+
+// renderASS produces Advanced SubStation Alpha subtitles with a \k karaoke
+// highlight per word. Lines without word timestamps are shown without highlight.
+func renderASS(segments []Segment, words []Word) string {
+	var b strings.Builder
+	b.WriteString(assHeader)
+
+	for i, line := range segmentWords(segments, words) {
+		segment := segments[i]
+		line = alignWords(segment.Text, line)
+		start, end := segment.Start, segment.End
+		var text strings.Builder
+		if len(line) == 0 {
+			text.WriteString(escapeASS(strings.TrimSpace(segment.Text)))
+		} else {
+			start, end = min(start, line[0].Start), max(end, line[len(line)-1].End)
+			// Each word is highlighted until the next one starts, so pauses are
+			// spent on the word before them
+			if gap := centiseconds(line[0].Start) - centiseconds(start); gap > 0 {
+				fmt.Fprintf(&text, `{\k%d}`, gap)
+			}
+			for j, word := range line {
+				next := word.End
+				if j+1 < len(line) {
+					next = line[j+1].Start
+				}
+				if j > 0 {
+					text.WriteString(" ")
+				}
+				fmt.Fprintf(&text, `{\k%d}%s`, centiseconds(next)-centiseconds(word.Start), escapeASS(word.Word))
+			}
+		}
+		fmt.Fprintf(&b, "Dialogue: 0,%s,%s,Default,,0,0,0,,%s\n", formatASSTime(start), formatASSTime(end), text.String())
+	}
+	return b.String()
+}
+
+// renderLRC produces enhanced LRC lyrics with a <mm:ss.cc> timestamp before each
+// word and one after the last word of every line
+func renderLRC(segments []Segment, words []Word) string {
+	var b strings.Builder
+	for i, line := range segmentWords(segments, words) {
+		segment := segments[i]
+		line = alignWords(segment.Text, line)
+		if len(line) == 0 {
+			fmt.Fprintf(&b, "[%s]%s\n", formatLRCTime(segment.Start), strings.TrimSpace(segment.Text))
+			continue
+		}
+
+		fmt.Fprintf(&b, "[%s]", formatLRCTime(line[0].Start))
+		for j, word := range line {
+			if j > 0 {
+				b.WriteString(" ")
+			}
+			fmt.Fprintf(&b, "<%s>%s", formatLRCTime(word.Start), word.Word)
+		}
+		fmt.Fprintf(&b, " <%s>\n", formatLRCTime(line[len(line)-1].End))
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func karaokeTestData() ([]Segment, []Word) {
+	segments := []Segment{
+		{Start: 0.5, End: 2.0, Text: " Hello world"},
+		{Start: 2.5, End: 4.0, Text: " Sing along"},
+		{Start: 4.0, End: 5.0, Text: " (instrumental)"},
+	}
+	words := []Word{
+		{Word: "Hello", Start: 0.8, End: 1.2},
+		{Word: "world", Start: 1.3, End: 1.9},
+		{Word: "Sing", Start: 2.5, End: 2.9},
+		{Word: "along", Start: 3.0, End: 3.8},
+	}
+	return segments, words
+}
+
+func TestSegmentWords(t *testing.T) {
+	segments, words := karaokeTestData()
+	lines := segmentWords(segments, words)
+	if len(lines) != 3 || len(lines[0]) != 2 || len(lines[1]) != 2 || len(lines[2]) != 0 {
+		t.Errorf("Unexpected word assignment %v", lines)
+	}
+}
+
+func TestRenderASS(t *testing.T) {
+	segments, words := karaokeTestData()
+	result := renderASS(segments, words)
+
+	if !strings.HasPrefix(result, "[Script Info]\n") {
+		t.Errorf("Expected the ASS header, got %q", result)
+	}
+	expected := "Dialogue: 0,0:00:00.50,0:00:02.00,Default,,0,0,0,,{\\k30}{\\k50}Hello {\\k60}world\n" +
+		"Dialogue: 0,0:00:02.50,0:00:04.00,Default,,0,0,0,,{\\k50}Sing {\\k80}along\n" +
+		"Dialogue: 0,0:00:04.00,0:00:05.00,Default,,0,0,0,,(instrumental)\n"
+	if !strings.HasSuffix(result, expected) {
+		t.Errorf("Expected events %q, got %q", expected, result)
+	}
+}
+
+func TestRenderLRC(t *testing.T) {
+	segments, words := karaokeTestData()
+	expected := "[00:00.80]<00:00.80>Hello <00:01.30>world <00:01.90>\n" +
+		"[00:02.50]<00:02.50>Sing <00:03.00>along <00:03.80>\n" +
+		"[00:04.00](instrumental)\n"
+	result := renderLRC(segments, words)
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestRenderKaraokeRewrittenText(t *testing.T) {
+	segments := []Segment{{Start: 0, End: 3, Text: " Hi [PERSON_1], welcome"}}
+	words := []Word{
+		{Word: "Hi", Start: 0.0, End: 0.4},
+		{Word: "Anna", Start: 0.5, End: 1.0},
+		{Word: "welcome", Start: 1.2, End: 2.0},
+	}
+	expected := "[00:00.00]<00:00.00>Hi <00:00.50>[PERSON_1], <00:01.20>welcome <00:02.00>\n"
+	if result := renderLRC(segments, words); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+	if result := renderASS(segments, words); strings.Contains(result, "Anna") {
+		t.Errorf("Expected the text instead of the words, got %q", result)
+	}
+}
+
+func TestAlignWordsWithoutTimestamps(t *testing.T) {
+	words := []Word{{Word: "one", Start: 1, End: 2}, {Word: "two", Start: 2, End: 4}}
+	aligned := alignWords("1 2", words)
+	if len(aligned) != 2 || aligned[0].Start != 1 || aligned[0].End != 2.5 || aligned[1].Start != 2.5 || aligned[1].End != 4 {
+		t.Errorf("Expected the words to share the time of the timestamps, got %v", aligned)
+	}
+	if aligned := alignWords("text", nil); aligned != nil {
+		t.Errorf("Expected no words without timestamps, got %v", aligned)
+	}
+}
+
+func TestFormatASSTime(t *testing.T) {
+	tests := []struct {
+		seconds  float64
+		expected string
+	}{
+		{0, "0:00:00.00"},
+		{61.234, "0:01:01.23"},
+		{3725.999, "1:02:06.00"},
+	}
+
+	for _, tc := range tests {
+		result := formatASSTime(tc.seconds)
+		if result != tc.expected {
+			t.Errorf("formatASSTime(%v) = %s, expected %s", tc.seconds, result, tc.expected)
+		}
+	}
+}
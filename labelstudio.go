@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// labelStudioTask is a Label Studio task with the transcript as prediction
+type labelStudioTask struct {
+	Data struct {
+		Audio string `json:"audio"`
+	} `json:"data"`
+	Predictions []labelStudioPrediction `json:"predictions"`
+}
+
+// labelStudioPrediction is a set of pre-filled regions
+type labelStudioPrediction struct {
+	ModelVersion string              `json:"model_version"`
+	Result       []labelStudioResult `json:"result"`
+}
+
+// labelStudioResult is one annotation of a region; a region's label and its
+// text are separate results sharing the region ID
+type labelStudioResult struct {
+	ID       string         `json:"id"`
+	FromName string         `json:"from_name"`
+	ToName   string         `json:"to_name"`
+	Type     string         `json:"type"`
+	Value    map[string]any `json:"value"`
+}
+
+// buildLabelStudioTask turns the segments into a task with one region per segment
+func buildLabelStudioTask(segments []Segment, audioURL, model string) labelStudioTask {
+	task := labelStudioTask{}
+	task.Data.Audio = audioURL
+
+	prediction := labelStudioPrediction{ModelVersion: model, Result: []labelStudioResult{}}
+	for i, segment := range segments {
+		id := fmt.Sprintf("segment%d", i+1)
+		prediction.Result = append(prediction.Result,
+			labelStudioResult{ID: id, FromName: "labels", ToName: "audio", Type: "labels",
+				Value: map[string]any{"start": segment.Start, "end": segment.End, "labels": []string{"Speech"}}},
+			labelStudioResult{ID: id, FromName: "transcription", ToName: "audio", Type: "textarea",
+				Value: map[string]any{"start": segment.Start, "end": segment.End, "text": []string{strings.TrimSpace(segment.Text)}}},
+		)
+	}
+	task.Predictions = []labelStudioPrediction{prediction}
+	return task
+}
+
+// saveLabelStudioTask writes the transcript as Label Studio task JSON next to the output
+func saveLabelStudioTask(args Args, originalFile string, transcript *Transcript, merge MergeOptions) error {
+	audioURL := args.AudioURL
+	if audioURL == "" {
+		audioURL = filepath.Base(originalFile)
+	}
+
+	task := buildLabelStudioTask(mergeSegments(transcript.Segments, merge), audioURL, args.Model)
+	data, err := json.MarshalIndent([]labelStudioTask{task}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal Label Studio task: %w", err)
+	}
+
+	taskFile := sidecarFileName(args, originalFile, ".label-studio.json")
+	if err := os.WriteFile(taskFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write Label Studio task: %w", err)
+	}
+	uiPrintf(tr("💾 Label Studio task saved to: %s\n"), taskFile)
+	return nil
+}
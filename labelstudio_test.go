@@ -0,0 +1,22 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildLabelStudioTask(t *testing.T) {
+	segments := []Segment{{Start: 0.5, End: 2.25, Text: " Hello there."}}
+	task := buildLabelStudioTask(segments, "https://example.com/a.mp3", "whisper-1")
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `{"data":{"audio":"https://example.com/a.mp3"},"predictions":[{"model_version":"whisper-1","result":[` +
+		`{"id":"segment1","from_name":"labels","to_name":"audio","type":"labels","value":{"end":2.25,"labels":["Speech"],"start":0.5}},` +
+		`{"id":"segment1","from_name":"transcription","to_name":"audio","type":"textarea","value":{"end":2.25,"start":0.5,"text":["Hello there."]}}]}]}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
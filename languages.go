@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// supportedLanguages maps the ISO-639-1 codes accepted by the transcription models
+// to their English names
+var supportedLanguages = map[string]string{
+	"af": "afrikaans", "am": "amharic", "ar": "arabic", "as": "assamese", "az": "azerbaijani",
+	"ba": "bashkir", "be": "belarusian", "bg": "bulgarian", "bn": "bengali", "bo": "tibetan",
+	"br": "breton", "bs": "bosnian", "ca": "catalan", "cs": "czech", "cy": "welsh",
+	"da": "danish", "de": "german", "el": "greek", "en": "english", "es": "spanish",
+	"et": "estonian", "eu": "basque", "fa": "persian", "fi": "finnish", "fo": "faroese",
+	"fr": "french", "gl": "galician", "gu": "gujarati", "ha": "hausa", "he": "hebrew",
+	"hi": "hindi", "hr": "croatian", "ht": "haitian creole", "hu": "hungarian", "hy": "armenian",
+	"id": "indonesian", "is": "icelandic", "it": "italian", "ja": "japanese", "jw": "javanese",
+	"ka": "georgian", "kk": "kazakh", "km": "khmer", "kn": "kannada", "ko": "korean",
+	"la": "latin", "lb": "luxembourgish", "ln": "lingala", "lo": "lao", "lt": "lithuanian",
+	"lv": "latvian", "mg": "malagasy", "mi": "maori", "mk": "macedonian", "ml": "malayalam",
+	"mn": "mongolian", "mr": "marathi", "ms": "malay", "mt": "maltese", "my": "myanmar",
+	"ne": "nepali", "nl": "dutch", "nn": "nynorsk", "no": "norwegian", "oc": "occitan",
+	"pa": "punjabi", "pl": "polish", "ps": "pashto", "pt": "portuguese", "ro": "romanian",
+	"ru": "russian", "sa": "sanskrit", "sd": "sindhi", "si": "sinhala", "sk": "slovak",
+	"sl": "slovenian", "sn": "shona", "so": "somali", "sq": "albanian", "sr": "serbian",
+	"su": "sundanese", "sv": "swedish", "sw": "swahili", "ta": "tamil", "te": "telugu",
+	"tg": "tajik", "th": "thai", "tk": "turkmen", "tl": "tagalog", "tr": "turkish",
+	"tt": "tatar", "uk": "ukrainian", "ur": "urdu", "uz": "uzbek", "vi": "vietnamese",
+	"yi": "yiddish", "yo": "yoruba", "zh": "chinese",
+}
+
+// languageAliases maps alternative and native language names to ISO-639-1 codes
+var languageAliases = map[string]string{
+	"burmese": "my", "castilian": "es", "farsi": "fa", "filipino": "tl", "flemish": "nl",
+	"haitian": "ht", "jv": "jw", "letzeburgesch": "lb", "mandarin": "zh", "moldavian": "ro",
+	"moldovan": "ro", "panjabi": "pa", "pushto": "ps", "sinhalese": "si", "valencian": "ca",
+	"deutsch": "de", "français": "fr", "francais": "fr", "español": "es", "espanol": "es",
+	"italiano": "it", "português": "pt", "portugues": "pt", "nederlands": "nl", "polski": "pl",
+	"svenska": "sv", "dansk": "da", "norsk": "no", "suomi": "fi", "türkçe": "tr", "turkce": "tr",
+	"русский": "ru", "українська": "uk", "日本語": "ja", "中文": "zh", "한국어": "ko",
+}
+
+// normalizeLanguage validates a --language value and resolves names, aliases and
+// locale tags (en-US, pt_BR) to the ISO-639-1 code the API expects
+func normalizeLanguage(input string) (string, error) {
+	value := strings.ToLower(strings.TrimSpace(input))
+	if value == "" || value == "auto" {
+		return "", nil
+	}
+
+	if _, ok := supportedLanguages[value]; ok {
+		return value, nil
+	}
+	if code, ok := languageAliases[value]; ok {
+		return code, nil
+	}
+	for code, name := range supportedLanguages {
+		if name == value {
+			return code, nil
+		}
+	}
+
+	// Locale tags like en-US or pt_BR carry the language as their first part
+	if i := strings.IndexAny(value, "-_"); i > 0 {
+		if code, err := normalizeLanguage(value[:i]); err == nil && code != "" {
+			return code, nil
+		}
+	}
+
+	if suggestions := suggestLanguages(value); len(suggestions) > 0 {
+		return "", fmt.Errorf(tr("unknown language %q, did you mean %s?"), input, strings.Join(suggestions, tr(" or ")))
+	}
+	return "", fmt.Errorf(tr("unknown language %q, use an ISO-639-1 code like \"en\" or a name like \"german\""), input)
+}
+
+// languageName returns the English name for a language code
+func languageName(code string) string {
+	return supportedLanguages[code]
+}
+
+// suggestLanguages lists the closest language names for a misspelled input
+func suggestLanguages(value string) []string {
+	type candidate struct {
+		label    string
+		distance int
+	}
+
+	var candidates []candidate
+	for code, name := range supportedLanguages {
+		distance := levenshtein(value, name)
+		// Allow roughly one typo per four characters
+		if distance <= 1+len(name)/4 {
+			candidates = append(candidates, candidate{fmt.Sprintf("%s (%s)", name, code), distance})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].label < candidates[j].label
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < 3; i++ {
+		suggestions = append(suggestions, candidates[i].label)
+	}
+	return suggestions
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"auto", ""},
+		{"de", "de"},
+		{"DE", "de"},
+		{"german", "de"},
+		{"German", "de"},
+		{"deutsch", "de"},
+		{"en-US", "en"},
+		{"pt_BR", "pt"},
+		{"farsi", "fa"},
+		{"haitian creole", "ht"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			result, err := normalizeLanguage(tc.input)
+			if err != nil {
+				t.Fatalf("normalizeLanguage(%q) failed: %v", tc.input, err)
+			}
+			if result != tc.expected {
+				t.Errorf("normalizeLanguage(%q) = %q, expected %q", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeLanguageSuggestions(t *testing.T) {
+	_, err := normalizeLanguage("germna")
+	if err == nil {
+		t.Fatal("Expected an error for a misspelled language")
+	}
+	if !strings.Contains(err.Error(), "german (de)") {
+		t.Errorf("Expected a suggestion for german, got: %v", err)
+	}
+
+	_, err = normalizeLanguage("klingon")
+	if err == nil {
+		t.Fatal("Expected an error for an unsupported language")
+	}
+	if !strings.Contains(err.Error(), "ISO-639-1") {
+		t.Errorf("Expected a generic hint, got: %v", err)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"german", "german", 0},
+		{"germna", "german", 2},
+		{"frnch", "french", 1},
+		{"", "abc", 3},
+	}
+
+	for _, tc := range tests {
+		if result := levenshtein(tc.a, tc.b); result != tc.expected {
+			t.Errorf("levenshtein(%q, %q) = %d, expected %d", tc.a, tc.b, result, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListenArgs defines the arguments of the listen subcommand
+type ListenArgs struct {
+	Device      string `arg:"--device" help:"Capture device to record (default: the loopback device of the system output)"`
+	InputFormat string `arg:"--input-format" help:"ffmpeg input format of the device (default: pulse on Linux, avfoundation on macOS, dshow on Windows)"`
+	Follow      string `arg:"--follow" help:"Transcribe a recording that is still being written, e.g. by OBS, instead of a device"`
+	Idle        int    `arg:"--idle" default:"10" help:"With --follow, seconds without new data after which the recording counts as finished"`
+	Chunk       int    `arg:"--chunk" default:"15" help:"Seconds of audio per transcription request; shorter chunks appear sooner, longer ones are more accurate"`
+	Output      string `arg:"--output,-o" help:"File to save the final transcript to (default: pindar-listen-<date>-<time>.txt)"`
+	Model       string `arg:"--model" default:"gpt-4o-transcribe" help:"OpenAI model to use for transcription"`
+	Language    string `arg:"--language" help:"Language of the audio as ISO-639-1 code or name (optional)"`
+	Prompt      string `arg:"--prompt" help:"Optional text to guide the model's style"`
+	APIKey      string `arg:"--api-key" env:"OPENAI_API_KEY" help:"OpenAI API key"`
+	Org         string `arg:"--org,env:OPENAI_ORG_ID" help:"OpenAI organization ID to bill usage to"`
+	Project     string `arg:"--project,env:OPENAI_PROJECT_ID" help:"OpenAI project ID to bill usage to"`
+	FFmpegPath  string `arg:"--ffmpeg-path,env:PINDAR_FFMPEG" help:"ffmpeg binary used for recording"`
+}
+
+// listenChunkPattern names the chunks ffmpeg's segment muxer records
+const listenChunkPattern = "chunk_%05d.flac"
+
+// listenContextChars is how much of the previous text is sent as prompt, so
+// words cut at a chunk boundary are continued sensibly
+const listenContextChars = 200
+
+// loopbackDevice returns the ffmpeg input format and device that capture what
+// the system plays. Linux records the monitor of the default PulseAudio or
+// PipeWire sink; macOS and Windows have no built-in loopback, so this is the
+// device of the usual virtual cable (BlackHole) or the Stereo Mix input.
+func loopbackDevice(goos string) (format, device string) {
+	switch goos {
+	case "darwin":
+		return "avfoundation", ":BlackHole 2ch"
+	case "windows":
+		return "dshow", "audio=Stereo Mix"
+	default:
+		return "pulse", "@DEFAULT_MONITOR@"
+	}
+}
+
+// followOptions are the ffmpeg input options reading a file that is still
+// being written: at its end ffmpeg waits for more data, and gives up once
+// none was added for idle seconds
+func followOptions(idle int) []string {
+	return []string{"-hide_banner", "-follow", "1", "-rw_timeout", strconv.Itoa(idle * 1_000_000)}
+}
+
+// completedChunks returns the chunk files from number next on that ffmpeg has
+// finished: all that exist once it has exited, otherwise those followed by the next one
+func completedChunks(dir string, next int, exited bool) []string {
+	var chunks []string
+	for number := next; ; number++ {
+		path := filepath.Join(dir, fmt.Sprintf(listenChunkPattern, number))
+		if !fileExists(path) {
+			return chunks
+		}
+		if !exited && !fileExists(filepath.Join(dir, fmt.Sprintf(listenChunkPattern, number+1))) {
+			return chunks
+		}
+		chunks = append(chunks, path)
+	}
+}
+
+// listenPrompt continues the user's prompt with the end of the text so far
+func listenPrompt(prompt string, texts []string) string {
+	previous := []rune(strings.Join(texts, " "))
+	if len(previous) > listenContextChars {
+		previous = previous[len(previous)-listenContextChars:]
+	}
+	prompt, _ = trimPrompt(strings.TrimSpace(prompt+" "+string(previous)), maxPromptTokens)
+	return prompt
+}
+
+// runListen transcribes whatever the system plays, or a growing recording
+// with --follow, in chunks, printing the text as it comes in, and saves the
+// whole transcript when stopped with Ctrl+C or when the recording ends
+func runListen(argv []string) {
+	var args ListenArgs
+	parser := parseSubcommand("listen", &args, argv)
+	if args.Chunk < 2 {
+		parser.Fail(tr("--chunk must be at least 2 seconds"))
+	}
+	if args.Follow != "" && args.Idle < 1 {
+		parser.Fail(tr("--idle must be at least 1 second"))
+	}
+	language, err := normalizeLanguage(args.Language)
+	if err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		uiPrintf(tr(" Error loading config: %v\n"), err)
+		os.Exit(1)
+	}
+	if err := configureFFmpeg(args.FFmpegPath, config.FFmpeg); err != nil {
+		uiPrintf(tr("❌ Invalid ffmpeg configuration: %v\n"), err)
+		os.Exit(1)
+	}
+	apiKey, err := getAPIKey(args.APIKey)
+	if err != nil {
+		uiPrintf(tr(" Error getting API key: %v\n"), err)
+		os.Exit(1)
+	}
+	client, err := newClient(providerOpenAI, apiKey, accountOptions(args.Org, args.Project, config)...)
+	if err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	format, device := loopbackDevice(runtime.GOOS)
+	if args.InputFormat != "" {
+		format = args.InputFormat
+	}
+	if args.Device != "" {
+		device = args.Device
+	}
+	output := args.Output
+	switch {
+	case output != "":
+	case args.Follow != "":
+		output = fitFileName(fileStem(args.Follow), ".txt")
+	default:
+		output = "pindar-listen-" + time.Now().Format("2006-01-02-150405") + ".txt"
+	}
+
+	tmpDir, err := os.MkdirTemp("", "pindar_listen")
+	if err != nil {
+		uiPrintf(tr("❌ Error recording: %v\n"), err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Mono 16 kHz FLAC is lossless and small, and every chunk is a complete file
+	chunking := []string{"-vn", "-ac", "1", "-ar", "16000", "-c:a", "flac",
+		"-f", "segment", "-segment_time", strconv.Itoa(args.Chunk), "-reset_timestamps", "1",
+		filepath.Join(tmpDir, listenChunkPattern)}
+	var cmd *exec.Cmd
+	if args.Follow != "" {
+		if !fileExists(args.Follow) {
+			uiPrintf(tr("❌ %s does not exist, start the recording first\n"), args.Follow)
+			os.Exit(1)
+		}
+		cmd, err = ffmpegCommand(followOptions(args.Idle), args.Follow, chunking...)
+	} else {
+		cmd, err = ffmpegDeviceCommand(format, device, chunking...)
+	}
+	if err != nil {
+		uiPrintf(tr("❌ Error recording: %v\n"), err)
+		os.Exit(1)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		uiPrintf(tr("❌ Error recording: %v\n"), err)
+		os.Exit(1)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	// The first Ctrl+C stops recording and transcribes the rest, a second one quits
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt)
+	interrupted := signals.Done()
+	if args.Follow != "" {
+		uiPrintf(tr(" Following %s, transcribing every %d seconds until nothing is added for %d seconds. Press Ctrl+C to stop.\n"), args.Follow, args.Chunk, args.Idle)
+	} else {
+		uiPrintf(tr(" Listening to %s (%s), transcribing every %d seconds. Press Ctrl+C to stop.\n"), device, format, args.Chunk)
+	}
+
+	ctx := context.Background()
+	transcribeArgs := Args{Model: args.Model, Language: language}
+	var texts []string
+	next := 0
+	transcribeChunks := func(exited bool) {
+		for _, chunk := range completedChunks(tmpDir, next, exited) {
+			transcribeArgs.Prompt = listenPrompt(args.Prompt, texts)
+			transcript, err := transcribeFile(ctx, client, transcribeArgs, chunk, filepath.Base(chunk))
+			if err != nil {
+				uiPrintf(tr("⚠️  Could not transcribe %s: %v\n"), formatTimestamp(float64(next*args.Chunk)), err)
+			} else if text := strings.TrimSpace(transcript.Text); text != "" {
+				fmt.Printf("[%s] %s\n", formatTimestamp(float64(next*args.Chunk)), text)
+				texts = append(texts, text)
+			}
+			os.Remove(chunk)
+			next++
+		}
+	}
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	stopped := false
+	for {
+		select {
+		case <-interrupted:
+			interrupted, stopped = nil, true
+			stopSignals()
+			uiPrintln(tr("\n Stopping, transcribing the rest..."))
+			// ffmpeg quits on q, finishing the last chunk; Ctrl+C in a terminal also reaches it directly
+			io.WriteString(stdin, "q")
+			stdin.Close()
+		case err := <-exited:
+			// A followed recording that stops growing ends ffmpeg with a timeout,
+			// so only a run that recorded nothing failed
+			if err != nil && !stopped && next == 0 && len(completedChunks(tmpDir, next, true)) == 0 {
+				if args.Follow != "" {
+					uiPrintf(tr("❌ Error reading %s: %v\n%s"), args.Follow, err, lastLines(stderr.String(), 5))
+					os.Exit(1)
+				}
+				uiPrintf(tr("❌ Error recording from %s: %v\n%s"), device, err, lastLines(stderr.String(), 5))
+				uiPrintln(tr(" Select the loopback device of your system with --device and --input-format, see \"Live Transcription\" in the README"))
+				os.Exit(1)
+			}
+			if args.Follow != "" && !stopped {
+				uiPrintln(tr("\n The recording has ended, transcribing the rest..."))
+			}
+			transcribeChunks(true)
+			stopSignals()
+			saveListenTranscript(output, texts)
+			return
+		case <-ticker.C:
+			transcribeChunks(false)
+		}
+	}
+}
+
+// saveListenTranscript writes the text of all chunks as the final transcript
+func saveListenTranscript(output string, texts []string) {
+	if len(texts) == 0 {
+		uiPrintln(tr("Nothing was transcribed."))
+		return
+	}
+	if err := os.WriteFile(output, []byte(strings.Join(texts, "\n")+"\n"), 0644); err != nil {
+		uiPrintf(tr("❌ Error writing output file: %v\n"), err)
+		os.Exit(1)
+	}
+	uiPrintf(tr("💾 Transcription saved to: %s\n"), output)
+}
+
+// lastLines returns the last n lines of ffmpeg's output, where it explains errors
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
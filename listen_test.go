@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoopbackDevice(t *testing.T) {
+	tests := map[string][2]string{
+		"linux":   {"pulse", "@DEFAULT_MONITOR@"},
+		"darwin":  {"avfoundation", ":BlackHole 2ch"},
+		"windows": {"dshow", "audio=Stereo Mix"},
+	}
+	for goos, expected := range tests {
+		if format, device := loopbackDevice(goos); format != expected[0] || device != expected[1] {
+			t.Errorf("loopbackDevice(%q) = %q, %q, expected %q, %q", goos, format, device, expected[0], expected[1])
+		}
+	}
+}
+
+func TestFollowOptions(t *testing.T) {
+	options := strings.Join(followOptions(10), " ")
+	if expected := "-hide_banner -follow 1 -rw_timeout 10000000"; options != expected {
+		t.Errorf("Expected %q, got %q", expected, options)
+	}
+}
+
+func TestCompletedChunks(t *testing.T) {
+	dir := t.TempDir()
+	for number := 2; number <= 4; number++ {
+		os.WriteFile(filepath.Join(dir, fmt.Sprintf(listenChunkPattern, number)), nil, 0644)
+	}
+
+	if chunks := completedChunks(dir, 2, false); len(chunks) != 2 || filepath.Base(chunks[1]) != "chunk_00003.flac" {
+		t.Errorf("Expected the chunks before the one being recorded, got %v", chunks)
+	}
+	if chunks := completedChunks(dir, 2, true); len(chunks) != 3 {
+		t.Errorf("Expected all chunks after ffmpeg exited, got %v", chunks)
+	}
+	if chunks := completedChunks(dir, 5, true); len(chunks) != 0 {
+		t.Errorf("Expected no chunks past the last one, got %v", chunks)
+	}
+}
+
+func TestListenPrompt(t *testing.T) {
+	if prompt := listenPrompt("", nil); prompt != "" {
+		t.Errorf("Expected an empty prompt, got %q", prompt)
+	}
+	if prompt := listenPrompt("Quarterly call.", []string{"Welcome everyone.", "Let's start"}); prompt != "Quarterly call. Welcome everyone. Let's start" {
+		t.Errorf("Expected the prompt followed by the previous text, got %q", prompt)
+	}
+
+	prompt := listenPrompt("", []string{strings.Repeat("a", 300) + "end"})
+	if len(prompt) != listenContextChars || !strings.HasSuffix(prompt, "end") {
+		t.Errorf("Expected the last %d characters of the text, got %d ending in %q", listenContextChars, len(prompt), prompt[len(prompt)-3:])
+	}
+}
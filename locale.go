@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Spaces of French typography, which must not break a line
+const (
+	noBreakSpace       = "\u00a0"
+	narrowNoBreakSpace = "\u202f"
+)
+
+// localeConventions are the typographic rules --locale applies to a language
+type localeConventions struct {
+	OpenQuote  string
+	CloseQuote string
+	// QuoteSpace goes inside the quotation marks
+	QuoteSpace       string
+	DecimalSeparator string
+	// ThousandsSeparator groups the digits of numbers with a thousands separator
+	ThousandsSeparator string
+	// PunctuationSpace goes before ; ! and ?, and ColonSpace before :
+	PunctuationSpace string
+	ColonSpace       string
+}
+
+// localeConventionsByLanguage are the languages --locale knows the conventions
+// of; English is written as the models write it
+var localeConventionsByLanguage = map[string]localeConventions{
+	"de": {OpenQuote: "„", CloseQuote: "“", DecimalSeparator: ",", ThousandsSeparator: "."},
+	"fr": {
+		OpenQuote: "«", CloseQuote: "»", QuoteSpace: noBreakSpace,
+		DecimalSeparator: ",", ThousandsSeparator: narrowNoBreakSpace,
+		PunctuationSpace: narrowNoBreakSpace, ColonSpace: noBreakSpace,
+	},
+	"es": {OpenQuote: "«", CloseQuote: "»", DecimalSeparator: ",", ThousandsSeparator: "."},
+	"it": {OpenQuote: "«", CloseQuote: "»", DecimalSeparator: ",", ThousandsSeparator: "."},
+}
+
+// localeConventionsFor returns the conventions of a language code
+func localeConventionsFor(code string) (localeConventions, error) {
+	conventions, ok := localeConventionsByLanguage[code]
+	if !ok {
+		codes := make([]string, 0, len(localeConventionsByLanguage))
+		for code := range localeConventionsByLanguage {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		return localeConventions{}, fmt.Errorf(tr("no typographic conventions for %s, only for: %s"), languageName(code), strings.Join(codes, ", "))
+	}
+	return conventions, nil
+}
+
+// englishNumber matches numbers written with English separators: a decimal
+// point, or commas between groups of three digits
+var englishNumber = regexp.MustCompile(`\d+(?:[.,]\d+)+`)
+
+// localizeNumber rewrites a number from English to the locale's separators.
+// Numbers that can't be told apart from the locale's own way of writing them,
+// like 1.500, and other dotted digits like dates and versions are left alone.
+func localizeNumber(number string, c localeConventions) string {
+	integer, fraction, hasFraction := strings.Cut(number, ".")
+	if strings.Contains(fraction, ".") || hasFraction && len(fraction) == 3 && !strings.Contains(integer, ",") {
+		return number
+	}
+	groups := strings.Split(integer, ",")
+	for i, group := range groups {
+		if i > 0 && len(group) != 3 || i == 0 && len(groups) > 1 && len(group) > 3 {
+			return number
+		}
+	}
+	if len(groups) == 1 && !hasFraction {
+		return number
+	}
+
+	localized := strings.Join(groups, c.ThousandsSeparator)
+	if hasFraction {
+		localized += c.DecimalSeparator + fraction
+	}
+	return localized
+}
+
+// localizeQuotes replaces straight double quotes with the locale's quotation
+// marks. A quote after a space, an opening bracket or at the start opens a
+// quotation, any other closes it.
+func localizeQuotes(text string, c localeConventions) string {
+	var b strings.Builder
+	previous := ' '
+	for i, r := range text {
+		if r != '"' {
+			b.WriteRune(r)
+			previous = r
+			continue
+		}
+		if unicode.IsSpace(previous) || strings.ContainsRune("([{", previous) {
+			b.WriteString(c.OpenQuote)
+			next, _ := utf8.DecodeRuneInString(text[i+1:])
+			if c.QuoteSpace != "" && !unicode.IsSpace(next) {
+				b.WriteString(c.QuoteSpace)
+			}
+		} else {
+			if c.QuoteSpace != "" {
+				trimmed := strings.TrimRight(b.String(), " ")
+				b.Reset()
+				b.WriteString(trimmed + c.QuoteSpace)
+			}
+			b.WriteString(c.CloseQuote)
+		}
+		previous = r
+	}
+	return b.String()
+}
+
+// punctuationBefore matches ; : ! and ? at the end of a word, with the space
+// the model may have put before them
+var punctuationBefore = regexp.MustCompile(`([^\s\x{00a0}\x{202f}])[ ]?([;:!?]+)(\s|$)`)
+
+// localizePunctuation puts the locale's space before ; : ! and ?. Colons
+// within a word, like in times and URLs, are left alone.
+func localizePunctuation(text string, c localeConventions) string {
+	if c.PunctuationSpace == "" {
+		return text
+	}
+	return punctuationBefore.ReplaceAllStringFunc(text, func(match string) string {
+		parts := punctuationBefore.FindStringSubmatch(match)
+		space := c.PunctuationSpace
+		if strings.HasPrefix(parts[2], ":") {
+			space = c.ColonSpace
+		}
+		return parts[1] + space + parts[2] + parts[3]
+	})
+}
+
+// localizeText applies the conventions of a locale to a text
+func localizeText(text string, c localeConventions) string {
+	text = englishNumber.ReplaceAllStringFunc(text, func(number string) string { return localizeNumber(number, c) })
+	text = localizeQuotes(text, c)
+	return localizePunctuation(text, c)
+}
+
+// applyLocale applies the conventions of a locale to the text and segments of a transcript
+func applyLocale(transcript *Transcript, c localeConventions) {
+	transcript.Text = localizeText(transcript.Text, c)
+	for i := range transcript.Segments {
+		segment := &transcript.Segments[i]
+		localized := localizeText(strings.TrimLeft(segment.Text, " "), c)
+		if strings.HasPrefix(segment.Text, " ") {
+			localized = " " + localized
+		}
+		setSegmentText(segment, localized)
+	}
+	syncWordsWithText(transcript)
+}
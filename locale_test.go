@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestLocalizeNumber(t *testing.T) {
+	de := localeConventionsByLanguage["de"]
+	tests := map[string]string{
+		"2.5":        "2,5",
+		"1,000":      "1.000",
+		"1,234.56":   "1.234,56",
+		"1.500":      "1.500",
+		"3,5":        "3,5",
+		"15.10.2026": "15.10.2026",
+		"1234,567":   "1234,567",
+	}
+	for number, expected := range tests {
+		if got := localizeNumber(number, de); got != expected {
+			t.Errorf("localizeNumber(%q) = %q, expected %q", number, got, expected)
+		}
+	}
+	if got := localizeNumber("12,500.75", localeConventionsByLanguage["fr"]); got != "12\u202f500,75" {
+		t.Errorf("Expected a narrow no-break space as French thousands separator, got %q", got)
+	}
+}
+
+func TestLocalizeText(t *testing.T) {
+	tests := []struct {
+		language string
+		text     string
+		expected string
+	}{
+		{"de", `Er sagte "Hallo" und zahlte 2.5 Euro.`, "Er sagte „Hallo“ und zahlte 2,5 Euro."},
+		{"de", `("Zitat")`, "(„Zitat“)"},
+		{"fr", `Il a dit "bonjour" : vraiment ?`, "Il a dit «\u00a0bonjour\u00a0»\u00a0: vraiment\u202f?"},
+		{"fr", "Quoi?! Rendez-vous à 10:30; voir https://example.com.", "Quoi\u202f?! Rendez-vous à 10:30\u202f; voir https://example.com."},
+		{"it", `Ha detto "ciao".`, "Ha detto «ciao»."},
+	}
+	for _, test := range tests {
+		if got := localizeText(test.text, localeConventionsByLanguage[test.language]); got != test.expected {
+			t.Errorf("localizeText(%s, %q) = %q, expected %q", test.language, test.text, got, test.expected)
+		}
+	}
+}
+
+func TestApplyLocale(t *testing.T) {
+	transcript := &Transcript{Text: `"Ja" 1.5`, Segments: []Segment{{Text: ` "Ja" 1.5`}}}
+	applyLocale(transcript, localeConventionsByLanguage["de"])
+	if transcript.Text != "„Ja“ 1,5" || transcript.Segments[0].Text != " „Ja“ 1,5" {
+		t.Errorf("Expected text and segments to be localized, got %q and %q", transcript.Text, transcript.Segments[0].Text)
+	}
+	if _, err := localeConventionsFor("en"); err == nil {
+		t.Error("Expected English to have no conventions")
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// spokenMacro is a macro from the config file: a spoken phrase and the text it
+// is replaced with
+type spokenMacro struct {
+	Phrase  string
+	Text    string
+	pattern *regexp.Regexp
+}
+
+// phraseWords splits a phrase into its words, ignoring case and punctuation
+func phraseWords(phrase string) []string {
+	return strings.FieldsFunc(strings.ToLower(phrase), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	})
+}
+
+// compileMacros prepares the macros of the config file. Longer phrases come
+// first, so a macro whose phrase contains another one's still matches.
+func compileMacros(macros map[string]string) ([]spokenMacro, error) {
+	var compiled []spokenMacro
+	for phrase, text := range macros {
+		words := phraseWords(phrase)
+		if len(words) == 0 {
+			return nil, fmt.Errorf(tr("the macro %q has no words to listen for"), phrase)
+		}
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		// The phrase may be separated by punctuation and followed by the punctuation
+		// the model guessed (group 1), which the text replaces as well
+		pattern := `(?i)` + strings.Join(words, `[^\p{L}\p{N}]+`) + `([.,;:!?]*)`
+		compiled = append(compiled, spokenMacro{Phrase: phrase, Text: text, pattern: regexp.MustCompile(pattern)})
+	}
+	sort.Slice(compiled, func(i, j int) bool {
+		if len(compiled[i].Phrase) != len(compiled[j].Phrase) {
+			return len(compiled[i].Phrase) > len(compiled[j].Phrase)
+		}
+		return compiled[i].Phrase < compiled[j].Phrase
+	})
+	return compiled, nil
+}
+
+// expandMacros replaces every spoken macro phrase in the text with the
+// macro's text and returns the number of replacements
+func expandMacros(text string, macros []spokenMacro) (string, int) {
+	count := 0
+	for _, macro := range macros {
+		var b strings.Builder
+		last := 0
+		for start := 0; start < len(text); {
+			m := macro.pattern.FindStringSubmatchIndex(text[start:])
+			if m == nil {
+				break
+			}
+			from, wordsEnd, to := start+m[0], start+m[2], start+m[1]
+			// Only whole words count. The boundaries are checked instead of
+			// matched, so a phrase spoken twice in a row expands twice.
+			if !isWordBoundary(text, from, wordsEnd) {
+				_, size := utf8.DecodeRuneInString(text[from:])
+				start = from + size
+				continue
+			}
+			b.WriteString(text[last:from])
+			b.WriteString(macro.Text)
+			last, start = to, to
+			count++
+		}
+		if last > 0 {
+			b.WriteString(text[last:])
+			text = b.String()
+		}
+	}
+	return text, count
+}
+
+// applyMacros expands the macros in the text and segments of a transcript and
+// returns the number of macros expanded in the text
+func applyMacros(transcript *Transcript, macros []spokenMacro) int {
+	text, count := expandMacros(transcript.Text, macros)
+	transcript.Text = text
+	for i := range transcript.Segments {
+		expanded, _ := expandMacros(transcript.Segments[i].Text, macros)
+		setSegmentText(&transcript.Segments[i], expanded)
+	}
+	syncWordsWithText(transcript)
+	return count
+}
@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestExpandMacros(t *testing.T) {
+	macros, err := compileMacros(map[string]string{
+		"insert signature block":       "Jane Doe\nAttorney at Law",
+		"insert signature block short": "J. D.",
+		"insert date":                  "15 October 2026",
+		"Grußformel":                   "Mit freundlichen Grüßen",
+	})
+	if err != nil {
+		t.Fatalf("compileMacros() failed: %v", err)
+	}
+
+	tests := []struct {
+		text     string
+		expected string
+		count    int
+	}{
+		{"Best regards,\n\nInsert signature block.", "Best regards,\n\nJane Doe\nAttorney at Law", 1},
+		{"Dated insert, date. Signed insert signature block short", "Dated 15 October 2026 Signed J. D.", 2},
+		{"We reinsert dates here.", "We reinsert dates here.", 0},
+		{"Grußformel. Ende", "Mit freundlichen Grüßen Ende", 1},
+		{"insert date and insert date", "15 October 2026 and 15 October 2026", 2},
+		{"insert date insert date.", "15 October 2026 15 October 2026", 2},
+		{"Insert date, insert date, done", "15 October 2026 15 October 2026 done", 2},
+	}
+	for _, test := range tests {
+		result, count := expandMacros(test.text, macros)
+		if result != test.expected || count != test.count {
+			t.Errorf("expandMacros(%q) = %q (%d), expected %q (%d)", test.text, result, count, test.expected, test.count)
+		}
+	}
+}
+
+func TestApplyMacros(t *testing.T) {
+	macros, _ := compileMacros(map[string]string{"insert date": "today"})
+	transcript := &Transcript{Text: "Insert date.", Segments: []Segment{{Text: " Insert date."}}}
+	if count := applyMacros(transcript, macros); count != 1 || transcript.Text != "today" || transcript.Segments[0].Text != " today" {
+		t.Errorf("Expected text and segments to be expanded, got %q and %q", transcript.Text, transcript.Segments[0].Text)
+	}
+}
+
+func TestCompileMacrosRejectsEmptyPhrase(t *testing.T) {
+	if _, err := compileMacros(map[string]string{" ... ": "text"}); err == nil {
+		t.Error("Expected an error for a phrase without words")
+	}
+}
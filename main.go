@@ -2,53 +2,164 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alexflint/go-arg"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
 	"github.com/openai/openai-go/packages/param"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Args defines the command line arguments for the transcription tool
 type Args struct {
-	File        string  `arg:"positional,required" help:"Path to the audio file to transcribe"`
-	Model       string  `arg:"--model" default:"gpt-4o-transcribe" help:"OpenAI model to use for transcription"`
-	Language    string  `arg:"--language" help:"Language of the audio file (optional)"`
+	File        string  `arg:"positional" help:"Path to the audio file to transcribe"`
+	Model       string  `arg:"--model" help:"OpenAI model to use for transcription (default: chosen by the routing rules in the config file, else the one set with pindar init, else gpt-4o-transcribe)"`
+	Language    string  `arg:"--language" help:"Language of the audio file as ISO-639-1 code or name, e.g. de or german (optional)"`
 	Prompt      string  `arg:"--prompt" help:"Optional text to guide the model's style or continue a previous audio segment"`
-	Format      string  `arg:"--format" default:"text" help:"Output format: text, srt, verbose_json, or vtt"`
-	OutputDir   string  `arg:"--output-dir,-o" help:"Directory to save the transcription output (defaults to current directory)"`
+	Format      string  `arg:"--format" help:"Output format: text, srt, vtt, ttml, scc, verbose_json, csv, ass, lrc, html, epub, or audacity-labels (default: text, or the one set with pindar init)"`
+	OutputDir   string  `arg:"--output-dir,-o" help:"Directory to save the transcription output, created if missing; ~ and {year}, {month}, {day}, {date} are expanded (defaults to current directory)"`
 	OutputExt   string  `arg:"--output-ext" help:"Extension for the output file (defaults to .txt for text, or appropriate extension for other formats)"`
+	OutputName  string  `arg:"--output-name" help:"Name of the output file without extension (defaults to the name of the audio file)"`
+	NoCreateDir bool    `arg:"--no-create-dirs" help:"Fail instead of creating an --output-dir that doesn't exist"`
+	Manifest    string  `arg:"--manifest" help:"CSV file with a row per file to transcribe (columns: file, language, prompt, output)"`
+	URLList     string  `arg:"--url-list" help:"Text file with one http(s) URL per line, e.g. podcast episodes, each downloaded and transcribed into a file named after the URL or the server's file name"`
+	Session     string  `arg:"--session" help:"Directory of a studio session whose takes (e.g. take_03_vocal.wav) are transcribed into one log ordered by take number"`
+	Queue       string  `arg:"--queue" help:"Seal the audio file and options into an encrypted job bundle in this directory or s3://bucket/prefix instead of transcribing, for pindar drain on a connected machine"`
 	APIKey      string  `arg:"--api-key" env:"OPENAI_API_KEY" help:"OpenAI API key (can also be set via OPENAI_API_KEY environment variable)"`
+	Org         string  `arg:"--org,env:OPENAI_ORG_ID" help:"OpenAI organization ID to bill usage to (overrides organization in the config file)"`
+	Project     string  `arg:"--project,env:OPENAI_PROJECT_ID" help:"OpenAI project ID to bill usage to (overrides project in the config file)"`
 	Temperature float64 `arg:"--temperature" default:"0" help:"Sampling temperature between 0 and 1 (higher is more random)"`
+	Fallback    string  `arg:"--temperature-fallback" help:"Temperatures to transcribe again at, in order, while the result looks unreliable (avg logprob below -1 or compression ratio above 2.4), e.g. 0.2,0.4,0.6,0.8,1.0, or whisper for that ladder"`
+	Yes         bool    `arg:"--yes,-y" help:"Transcribe videos larger than 1 GB without asking for confirmation"`
+	Track       int     `arg:"--track" help:"Audio track to transcribe for files with multiple audio tracks (1-based, prompts if omitted)"`
+	Chapters    string  `arg:"--chapters" default:"auto" help:"Transcribe per chapter: auto (m4b/m4a audiobooks), always, or never"`
+	Telephony   string  `arg:"--telephony" default:"auto" help:"Phone-call preset (300-3400 Hz band-pass, upsampling to 16 kHz): auto (8 kHz μ-law/a-law recordings), always, or never"`
+	SplitCall   bool    `arg:"--split-call" help:"Transcribe the channels of a stereo call recording separately and label them with the first two --speakers (default: Agent and Customer)"`
+	Turns       bool    `arg:"--turns" help:"Label the speakers of a two-party call or interview from the audio alone, offline: by the louder channel of a stereo recording, or by loudness and pauses in mono (names: the first two --speakers)"`
+	Dictation   bool    `arg:"--dictation" help:"Turn spoken commands like \"comma\", \"period\" and \"new paragraph\" into punctuation and line breaks (English and German)"`
+	Locale      string  `arg:"--locale" help:"Apply the quotation marks, number separators and punctuation spacing of a language (de, fr, es, it), or of the transcript's language with auto"`
+	BestOf      int     `arg:"--best-of" default:"1" help:"Transcribe N times at increasing temperatures and keep the most confident result"`
+	Accessible  bool    `arg:"--accessible" help:"Screen-reader-friendly output without emoji and box-drawing characters"`
+	CI          bool    `arg:"--ci,env:PINDAR_CI" help:"Plain output for build logs: no emoji or box-drawing characters, and a timestamp on every line"`
+	Trace       string  `arg:"--trace-exporter" help:"Export OpenTelemetry spans of the conversion, API requests and rendering: otlp (to OTEL_EXPORTER_OTLP_ENDPOINT), console (to stderr), or none"`
+	Summary     string  `arg:"--summary" help:"Write a JSON summary of a --manifest, --url-list or --session run to this file (files, minutes of audio, estimated cost, failures)"`
+	FailOnWarns bool    `arg:"--fail-on-warnings" help:"Exit with status 1 if any warning was printed, even though the transcript was saved"`
+	UILang      string  `arg:"--ui-lang,env:PINDAR_UI_LANG" help:"Language of pindar's own messages: en or de"`
+	Provider    string  `arg:"--provider" default:"openai" help:"Transcription provider: openai, or fake to replay canned responses without an API key"`
+	DataPolicy  string  `arg:"--data-policy" default:"default" help:"Required data handling: default or zero-retention (fails if the provider can't honor it)"`
+	FFmpegPath  string  `arg:"--ffmpeg-path,env:PINDAR_FFMPEG" help:"ffmpeg binary used for conversion (ffprobe is taken from the same directory if present)"`
+	PostHook    string  `arg:"--post-hook" help:"Shell command run after each transcript, receiving it as JSON on stdin"`
+	Script      string  `arg:"--script" help:"Lua script whose transform(transcript) function edits the segments before anything is saved (e.g. to rename speakers or drop sections)"`
+
+	RefineBelow  *float64 `arg:"--refine-below" help:"Re-transcribe segments whose avg_logprob is below this threshold (e.g. -0.7)"`
+	RefineModel  string   `arg:"--refine-model" default:"gpt-4o-transcribe" help:"Model used to re-transcribe low-confidence segments"`
+	RefinePrompt string   `arg:"--refine-prompt" help:"Prompt for re-transcribing low-confidence segments (preceding text is appended as context)"`
+
+	Hallucinations       string `arg:"--hallucinations" default:"flag" help:"Segments that look made up (repetition loops, text during silence, phrases like \"Thanks for watching!\"): flag them with a warning, strip them, or off"`
+	HallucinationRetries int    `arg:"--hallucination-retries" help:"Transcribe likely hallucinated segments again up to N times, at higher temperatures and without the prompt, and keep the first clean result"`
+
+	MergePause       float64 `arg:"--merge-pause" help:"Merge verbose_json segments separated by pauses shorter than this many seconds"`
+	MergeSentences   bool    `arg:"--merge-sentences" help:"Merge verbose_json segments until a sentence boundary is reached"`
+	MergeMaxDuration float64 `arg:"--merge-max-duration" help:"Maximum length in seconds of a merged verbose_json segment"`
+
+	RecordCassette string `arg:"--record-cassette" help:"Record the API responses to this file for replaying them later (the API key is not recorded)"`
+	ReplayCassette string `arg:"--replay-cassette" help:"Answer API requests from a file written by --record-cassette instead of calling the API"`
+
+	Anonymize     bool     `arg:"--anonymize" help:"Replace person names with placeholders and save the names to an encrypted mapping file"`
+	Entities      bool     `arg:"--entities" help:"Save the people, organizations, places and dates mentioned, with timestamps, to a JSON sidecar file"`
+	TagSegments   bool     `arg:"--tag-segments" help:"Tag each segment with its sentiment and topics in verbose_json and csv output"`
+	Topics        []string `arg:"--topics" help:"Topic labels --tag-segments may choose from (default: any topic)"`
+	Minutes       bool     `arg:"--meeting-minutes" help:"Save decisions, action items and open questions as Markdown next to the transcript"`
+	Interview     bool     `arg:"--interview" help:"Save a two-person interview as Markdown question and answer pairs next to the transcript"`
+	Tracks        []string `arg:"--tracks" help:"Recordings of a multitrack recorder with one speaker each, as name=file separated by commas, transcribed into one transcript labeled by speaker"`
+	Speakers      []string `arg:"--speakers" help:"Names of the people speaking, added to the prompt so they are spelled right and used as labels in --interview output (interviewer first)"`
+	LabelStudio   bool     `arg:"--label-studio" help:"Save a Label Studio task with one pre-filled transcription region per segment next to the transcript"`
+	Anki          bool     `arg:"--anki" help:"Save an Anki deck with a card per sentence, its audio snippet on the front and its text on the back, next to the transcript"`
+	AnkiTranslate string   `arg:"--anki-translate" help:"Language to translate the sentences of --anki cards into, shown below the text"`
+	Bilingual     string   `arg:"--bilingual" help:"Language to translate the transcript into, saved side by side with the original as a Markdown table next to the transcript"`
+	KeepRaw       bool     `arg:"--keep-raw" help:"Save the provider's responses and the transcript compressed next to it, to render other formats later without transcribing again"`
+	QAScorecard   string   `arg:"--qa-scorecard" help:"YAML file with criteria to score a call against (required phrases, prohibited phrases), saved as a Markdown report next to the transcript"`
+	AudioURL      string   `arg:"--audio-url" help:"URL of the audio file as Label Studio and html output load it (default: the file name)"`
+	EmbedAudio    bool     `arg:"--embed-audio" help:"Embed the audio in html output so the page works on its own"`
+	AnalysisModel string   `arg:"--analysis-model" default:"gpt-4o-mini" help:"Chat model used to analyze the transcript (e.g. to find names for --anonymize)"`
+}
+
+// subcommands are dispatched on the first argument instead of transcribing a file
+var subcommands = map[string]func(argv []string){
+	"deanonymize":        runDeanonymize,
+	"import-corrections": runImportCorrections,
+	"advise":             runAdvise,
+	"deps":               runDeps,
+	"doctor":             runDoctor,
+	"listen":             runListen,
+	"export-state":       runExportState,
+	"import-state":       runImportState,
+	"render":             runRender,
+	"init":               runInit,
+	"usage":              runUsage,
+	"drain":              runDrain,
+	"diff":               runDiff,
+	"digest":             runDigest,
+}
+
+// parseSubcommand parses the arguments of a subcommand, printing usage and
+// exiting on --help or invalid arguments
+func parseSubcommand(name string, dest any, argv []string) *arg.Parser {
+	parser, err := arg.NewParser(arg.Config{Program: "pindar " + name}, dest)
+	if err != nil {
+		panic(err)
+	}
+	parser.MustParse(argv)
+	return parser
+}
+
+// wantsSegments reports whether the transcription has to be requested with segments
+func (a Args) wantsSegments() bool {
+	return needsSegments(a.Format) || a.RefineBelow != nil || a.Entities || a.TagSegments || a.Interview || a.Turns || a.LabelStudio || a.Anki || a.Bilingual != "" || a.SplitCall || len(a.Tracks) > 0 || a.QAScorecard != "" || a.Script != "" || a.HallucinationRetries > 0 && a.Hallucinations != hallucinationsOff
 }
 
 func printHeader() {
-	fmt.Println("  Pindar - Audio Transcription CLI")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	uiPrintln(tr("  Pindar - Audio Transcription CLI"))
+	printRule()
 }
 
 func printParameters(args Args, audioFile string) {
-	fmt.Println("\n  Transcription Parameters:")
-	fmt.Printf("   File:        %s\n", audioFile)
-	fmt.Printf("   Model:       %s\n", args.Model)
+	uiPrintln(tr("\n  Transcription Parameters:"))
+	uiPrintf(tr("   File:        %s\n"), audioFile)
+	uiPrintf(tr("   Model:       %s\n"), args.Model)
 	if args.Language != "" {
-		fmt.Printf("   Language:    %s\n", args.Language)
+		uiPrintf(tr("   Language:    %s (%s)\n"), args.Language, languageName(args.Language))
 	} else {
-		fmt.Printf("   Language:    auto-detect\n")
+		uiPrint(tr("   Language:    auto-detect\n"))
 	}
-	fmt.Printf("   Format:      %s\n", args.Format)
+	uiPrintf(tr("   Format:      %s\n"), args.Format)
 	if args.Temperature != 0 {
-		fmt.Printf("   Temperature: %.1f\n", args.Temperature)
+		uiPrintf(tr("   Temperature: %.1f\n"), args.Temperature)
 	}
 	if args.Prompt != "" {
-		fmt.Printf("   Prompt:      %s\n", args.Prompt)
+		uiPrintf(tr("   Prompt:      %s\n"), args.Prompt)
+	}
+	if args.BestOf > 1 {
+		uiPrintf(tr("   Best of:     %d runs\n"), args.BestOf)
+	}
+	if args.Fallback != "" {
+		uiPrintf(tr("   Fallback:    %s\n"), args.Fallback)
+	}
+	if args.DataPolicy != dataPolicyDefault {
+		uiPrintf(tr("   Data policy: %s\n"), describeDataPolicy(args.DataPolicy))
+	}
+	if args.RefineBelow != nil {
+		uiPrintf(tr("   Refine:      below %.2f with %s\n"), *args.RefineBelow, args.RefineModel)
 	}
-	fmt.Println()
+	uiPrintln("")
 }
 
 func getFileExtension(filename string) string {
@@ -67,89 +178,827 @@ func isFormatSupported(ext string) bool {
 	return supportedFormats[strings.ToLower(ext)]
 }
 
-func convertToMP4(inputPath string) (string, error) {
+// convertToMP4 converts the input to an AAC .mp4 audio file, applying the ffmpeg
+// audio filter if one is given. A track greater than
+// zero selects that (1-based) audio track instead of ffmpeg's default stream.
+// The returned function removes the file and its temporary directory.
+func convertToMP4(ctx context.Context, inputPath string, track int, filter string) (string, func(), error) {
 	// Create a temporary directory for the converted file
 	tmpDir, err := os.MkdirTemp("", "pindar_convert")
 	if err != nil {
-		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+		return "", nil, fmt.Errorf("failed to create temporary directory: %w", err)
 	}
 
 	// Generate output file path
-	baseName := filepath.Base(inputPath)
-	nameWithoutExt := strings.TrimSuffix(baseName, filepath.Ext(baseName))
-	outputPath := filepath.Join(tmpDir, nameWithoutExt+"_converted.mp4")
-
-	// Check if ffmpeg is available
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		return "", fmt.Errorf("ffmpeg is required for audio format conversion but was not found in PATH. Please install ffmpeg")
-	}
+	outputPath := filepath.Join(tmpDir, fitFileName(fileStem(inputPath), "_converted.mp4"))
 
 	// Run ffmpeg conversion with hidden output
-	cmd := exec.Command("ffmpeg", "-i", inputPath, "-c:a", "aac", "-b:a", "128k", "-y", outputPath)
+	var output []string
+	if track > 0 {
+		output = append(output, "-map", fmt.Sprintf("0:a:%d", track-1))
+	}
+	if filter != "" {
+		output = append(output, "-af", filter)
+	}
+	output = append(output, "-vn", "-c:a", "aac", "-b:a", "128k", "-y", ffmpegPath(outputPath))
+	cmd, err := ffmpegCommand(nil, inputPath, output...)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return "", nil, err
+	}
 
 	// Capture output to hide it
 	var stderr strings.Builder
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("ffmpeg conversion failed: %w\nOutput: %s", err, stderr.String())
+	_, span := tracer.Start(ctx, "convert", trace.WithAttributes(attribute.String("pindar.file", filepath.Base(inputPath))))
+	err = cmd.Run()
+	endSpan(span, err)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return "", nil, fmt.Errorf("ffmpeg conversion failed: %w\nOutput: %s", err, stderr.String())
 	}
 
-	return outputPath, nil
+	return outputPath, func() { os.RemoveAll(tmpDir) }, nil
+}
+
+// extractAudioSlice writes the audio between start and end (in seconds) of the
+// input to an AAC .mp4 file at outputPath
+func extractAudioSlice(ctx context.Context, inputPath, outputPath string, start, end float64, track int) error {
+	output := []string{"-t", strconv.FormatFloat(end-start, 'f', 3, 64)}
+	if track > 0 {
+		output = append(output, "-map", fmt.Sprintf("0:a:%d", track-1))
+	}
+	output = append(output, "-vn", "-c:a", "aac", "-b:a", "128k", "-y", ffmpegPath(outputPath))
+	cmd, err := ffmpegCommand([]string{"-ss", strconv.FormatFloat(start, 'f', 3, 64)}, inputPath, output...)
+	if err != nil {
+		return err
+	}
+
+	// Capture output to hide it
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	_, span := tracer.Start(ctx, "extract", trace.WithAttributes(attribute.Float64("pindar.start", start), attribute.Float64("pindar.end", end)))
+	err = cmd.Run()
+	endSpan(span, err)
+	if err != nil {
+		return fmt.Errorf("ffmpeg extraction failed: %w\nOutput: %s", err, stderr.String())
+	}
+	return nil
 }
 
 func main() {
+	// Subcommands don't take the options of a transcription, so their output
+	// mode and language come from the environment
+	ciOutput, _ = strconv.ParseBool(os.Getenv("PINDAR_CI"))
+	if err := setUILanguage(os.Getenv("PINDAR_UI_LANG")); err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			run(os.Args[2:])
+			return
+		}
+	}
+
 	var args Args
-	arg.MustParse(&args)
+	parser := arg.MustParse(&args)
+	accessibleOutput = args.Accessible
+	ciOutput = args.CI
+
+	if err := setUILanguage(args.UILang); err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	// Without --format, the format set with pindar init applies; a config
+	// file that can't be read is reported later
+	if args.Format == "" {
+		args.Format = "text"
+		if config, err := loadConfig(); err == nil && config.Format != "" {
+			args.Format = config.Format
+		}
+	}
+
+	switch {
+	case args.Manifest != "" && args.File != "":
+		parser.Fail(tr("pass either an audio file or --manifest, not both"))
+	case args.URLList != "" && (args.File != "" || args.Manifest != ""):
+		parser.Fail(tr("pass either an audio file, --manifest or --url-list"))
+	case args.Session != "" && (args.File != "" || args.Manifest != "" || args.URLList != ""):
+		parser.Fail(tr("pass either an audio file, --manifest, --url-list or --session"))
+	case args.Session != "" && sessionSidecarFlag(args) != "":
+		parser.Fail(fmt.Sprintf(tr("%s saves a file per recording, which --session doesn't keep; transcribe the takes with --manifest instead"), sessionSidecarFlag(args)))
+	case len(args.Tracks) > 0 && (args.File != "" || args.Manifest != "" || args.URLList != "" || args.Session != ""):
+		parser.Fail(tr("pass either an audio file or --tracks, not both"))
+	case len(args.Tracks) > 0 && args.SplitCall:
+		parser.Fail(tr("--split-call and --tracks can't be combined"))
+	case args.Turns && (args.SplitCall || len(args.Tracks) > 0):
+		parser.Fail(tr("--turns can't be combined with --split-call or --tracks, which label the speakers already"))
+	case args.Summary != "" && args.Manifest == "" && args.URLList == "" && args.Session == "":
+		parser.Fail(tr("--summary only applies to --manifest, --url-list and --session runs"))
+	case !isAutoMode(args.Chapters):
+		parser.Fail(fmt.Sprintf(tr("%s must be auto, always or never, not %q"), "--chapters", args.Chapters))
+	case !isAutoMode(args.Telephony):
+		parser.Fail(fmt.Sprintf(tr("%s must be auto, always or never, not %q"), "--telephony", args.Telephony))
+	case args.Manifest != "":
+		runManifest(args, os.Args[1:])
+		return
+	case args.URLList != "":
+		runURLList(args, os.Args[1:])
+		return
+	case args.Session != "":
+		runSession(args, os.Args[1:])
+		return
+	case args.Queue != "" && args.File == "":
+		parser.Fail(tr("--queue takes a single audio file"))
+	case args.File == "" && len(args.Tracks) == 0:
+		parser.Fail(tr("audio file is required"))
+	}
+
+	// The first track stands in for the audio file, e.g. in the output names,
+	// and the speakers' names are spelled as given
+	var speakerTracks []speakerTrack
+	if len(args.Tracks) > 0 {
+		tracks, err := parseSpeakerTracks(args.Tracks)
+		if err != nil {
+			parser.Fail(err.Error())
+		}
+		speakerTracks = tracks
+		args.File = tracks[0].Path
+		if len(args.Speakers) == 0 {
+			args.Speakers = speakerTrackNames(tracks)
+		}
+	}
 
 	printHeader()
 
-	// Get API key using priority order: CLI arg → env var → config file → prompt user
-	apiKey, err := getAPIKey(args.APIKey)
+	// A recording's options file overrides the command line
+	fileOptions, fileOptionsPath, err := loadFileOptions(args.File)
+	if err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if fileOptions != nil {
+		uiPrintf(tr(" Using the options in %s\n"), fileOptionsPath)
+		applyFileOptions(&args, *fileOptions)
+	}
+
+	// Fail fast on unknown languages instead of sending them to the API
+	language, err := normalizeLanguage(args.Language)
+	if err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	args.Language = language
+	if args.AnkiTranslate, err = normalizeLanguage(args.AnkiTranslate); err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if args.Bilingual, err = normalizeLanguage(args.Bilingual); err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	args.Topics = normalizeTopics(args.Topics)
+	if err := validateHallucinationMode(args.Hallucinations); err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := parseFallbackTemperatures(args.Fallback); err != nil {
+		parser.Fail(err.Error())
+	}
+	if args.Locale != "" && args.Locale != "auto" {
+		if args.Locale, err = normalizeLanguage(args.Locale); err == nil {
+			_, err = localeConventionsFor(args.Locale)
+		}
+		if err != nil {
+			uiPrintf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// A field device only queues the recording, the transcription happens
+	// where pindar drain runs
+	if args.Queue != "" {
+		queuedOptions := ""
+		if fileOptions != nil {
+			queuedOptions = fileOptionsPath
+		}
+		if err := enqueue(args, os.Args[1:], queuedOptions); err != nil {
+			uiPrintf(tr("❌ Error queueing %s: %v\n"), args.File, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if args.Trace == "" {
+		var ignored []string
+		args.Trace, ignored = envTraceExporter(os.Getenv("OTEL_TRACES_EXPORTER"))
+		if len(ignored) > 0 {
+			uiPrintf(tr("⚠️  Ignoring %s of OTEL_TRACES_EXPORTER, pindar exports to one of %s or %s only\n"), strings.Join(ignored, ", "), traceExporterOTLP, traceExporterConsole)
+		}
+	}
+	shutdownTracing, err := setupTracing(context.Background(), args.Trace)
+	if err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
+	if args.OutputDir != "" {
+		if args.OutputDir, err = expandOutputDir(args.OutputDir, time.Now()); err == nil {
+			err = ensureOutputDir(args.OutputDir, !args.NoCreateDir)
+		}
+		if err != nil {
+			uiPrintf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var scorecardRules *ScorecardRules
+	if args.QAScorecard != "" {
+		if scorecardRules, err = loadScorecardRules(args.QAScorecard); err != nil {
+			uiPrintf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		if scorecardRules.usesSpeakers() && !args.SplitCall && !args.Turns && len(args.Tracks) == 0 {
+			uiPrintln(tr("⚠️  Scorecard criteria limited to a speaker only match with --split-call, --tracks or --turns"))
+		}
+	}
+
+	var script *transcriptScript
+	if args.Script != "" {
+		if script, err = loadTranscriptScript(args.Script); err != nil {
+			uiPrintf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		defer script.close()
+	}
+
+	config, err := loadConfig()
 	if err != nil {
-		fmt.Printf(" Error getting API key: %v\n", err)
+		uiPrintf(tr(" Error loading config: %v\n"), err)
 		os.Exit(1)
 	}
 
+	if err := configureFFmpeg(args.FFmpegPath, config.FFmpeg); err != nil {
+		uiPrintf(tr("❌ Invalid ffmpeg configuration: %v\n"), err)
+		os.Exit(1)
+	}
+
+	macros, err := compileMacros(config.Macros)
+	if err != nil {
+		uiPrintf(tr("❌ Invalid macro configuration: %v\n"), err)
+		os.Exit(1)
+	}
+
+	// Refuse to upload anything if the requested data policy can't be honored
+	if err := checkDataPolicy(args.DataPolicy, config); err != nil {
+		uiPrintf(tr("❌ Data policy error: %v\n"), err)
+		os.Exit(1)
+	}
+
+	// Without an explicit --model, pick one by audio duration from the config's routing rules
+	if args.Model == "" {
+		args.Model, err = selectRoutedModel(config.Routing, config.Model, args.File)
+		if err != nil {
+			uiPrintf(tr("❌ Invalid routing configuration: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	// Get API key using priority order: CLI arg → env var → config file → prompt user
+	var apiKey string
+	if args.Provider != providerFake && args.ReplayCassette == "" {
+		apiKey, err = getAPIKey(args.APIKey)
+		if err != nil {
+			uiPrintf(tr(" Error getting API key: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	// Ask for the mapping passphrase before spending time on the transcription
+	var mappingPassphrase string
+	if args.Anonymize {
+		mappingPassphrase, err = readPassphrase(true)
+		if err != nil {
+			uiPrintf(tr("❌ Error reading passphrase: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
 	// Create OpenAI client
-	client := openai.NewClient(
-		option.WithAPIKey(apiKey),
-	)
+	cassette, err := cassetteOptions(args.RecordCassette, args.ReplayCassette, apiKey)
+	if err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	if args.Trace != "" && args.Trace != traceExporterNone {
+		cassette = append([]option.RequestOption{option.WithMiddleware(traceMiddleware)}, cassette...)
+	}
+	// The recorder wraps the cassette, so replayed responses are kept as well
+	var raw *rawRecorder
+	if args.KeepRaw {
+		raw = &rawRecorder{}
+		cassette = append([]option.RequestOption{option.WithMiddleware(raw.middleware)}, cassette...)
+		if args.Anonymize {
+			uiPrintln(tr("⚠️  The responses kept by --keep-raw contain the names --anonymize replaces"))
+		}
+	}
+	client, err := newClient(args.Provider, apiKey, append(cassette, accountOptions(args.Org, args.Project, config)...)...)
+	if err != nil {
+		uiPrintf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	// Keep the prompt within the model's limit instead of letting the API reject it
+	args.Prompt = promptWithSpeakers(args.Prompt, args.Speakers)
+	// The example of dictated text is only given in a known language, so it
+	// doesn't sway the language detection
+	if args.Dictation && args.Language != "" {
+		language, err := dictationLanguageFor(args.Language)
+		if err != nil {
+			uiPrintf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		args.Prompt = promptWithDictation(args.Prompt, language)
+	}
+	if trimmedPrompt, trimmed := trimPrompt(args.Prompt, maxPromptTokens); trimmed {
+		uiPrintf(tr("⚠️  Prompt is about %d tokens, more than the %d-token limit. Keeping only its last %d characters.\n"),
+			estimateTokens(args.Prompt), maxPromptTokens, len([]rune(trimmedPrompt)))
+		args.Prompt = trimmedPrompt
+	}
+
+	// Create a context for the requests, with a span covering the whole run
+	ctx, span := tracer.Start(traceParentContext(), "pindar", trace.WithAttributes(
+		attribute.String("pindar.file", filepath.Base(args.File)),
+		attribute.String("pindar.provider", args.Provider),
+		attribute.String("pindar.model", args.Model),
+		attribute.String("pindar.format", args.Format),
+	))
+	defer span.End()
 
-	// Check if format is supported, convert if necessary
 	originalFile := args.File
 	ext := getFileExtension(args.File)
-	if !isFormatSupported(ext) {
-		fmt.Printf(" Converting .%s to .mp4 format...\n", ext)
-		convertedFile, err := convertToMP4(args.File)
+	track, err := selectAudioTrack(args.File, args.Track)
+	if err != nil {
+		uiPrintf(tr(" Error selecting audio track: %v\n"), err)
+		os.Exit(1)
+	}
+
+	// Only the audio of a multi-GB video is extracted and uploaded, after confirmation
+	largeVideo := largeVideoSize(originalFile)
+	if largeVideo > 0 {
+		if err := confirmLargeVideo(originalFile, largeVideo, args.Yes); err != nil {
+			uiPrintf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Audiobooks with chapters are transcribed chapter by chapter
+	var chapters []chapter
+	if len(speakerTracks) == 0 && shouldSplitChapters(args.Chapters, originalFile) {
+		chapters, err = probeChapters(originalFile)
+		if err != nil {
+			uiPrintf(tr("⚠️  Could not read chapters, transcribing as a single file: %v\n"), err)
+		}
+	}
+
+	// Timestamped formats need verbose_json, which the gpt-4o models don't support
+	if args.wantsSegments() && !modelSupportsTimestamps(args.Model) {
+		switch {
+		case needsSegments(args.Format):
+			uiPrintf(tr("⚠️  Note: %s output requires timestamps, which %s does not provide. Using whisper-1 instead.\n"), args.Format, args.Model)
+		case args.RefineBelow != nil:
+			uiPrintf(tr("⚠️  Note: Refining low-confidence segments requires timestamps, which %s does not provide. Using whisper-1 instead.\n"), args.Model)
+		default:
+			uiPrintf(tr("⚠️  Note: The requested outputs require timestamps, which %s does not provide. Using whisper-1 instead.\n"), args.Model)
+		}
+		args.Model = "whisper-1"
+	}
+
+	var transcript *Transcript
+	var chapterTranscripts []*Transcript
+	if len(chapters) > 1 {
+		printParameters(args, originalFile)
+		uiPrintf(tr(" Found %d chapters, transcribing each chapter separately...\n"), len(chapters))
+
+		chapterTranscripts, err = transcribeChapters(ctx, client, args, originalFile, track, chapters)
+		if err != nil {
+			printAPIError(err)
+			os.Exit(1)
+		}
+		transcript = combineChapterTranscripts(chapters, chapterTranscripts)
+	} else if len(speakerTracks) > 0 {
+		printParameters(args, originalFile)
+		uiPrintf(tr(" Transcribing the tracks of %d speakers separately...\n"), len(speakerTracks))
+
+		transcript, err = transcribeSpeakerTracks(ctx, client, args, speakerTracks, track)
+		if err != nil {
+			printAPIError(err)
+			os.Exit(1)
+		}
+	} else if args.SplitCall {
+		printParameters(args, originalFile)
+		uiPrintln(tr(" Transcribing both sides of the call separately..."))
+
+		transcript, err = transcribeCallLegs(ctx, client, args, originalFile, track, shouldApplyTelephony(args.Telephony, originalFile, track))
 		if err != nil {
-			fmt.Printf(" Error converting audio file: %v\n", err)
+			printAPIError(err)
+			os.Exit(1)
+		}
+	} else {
+		// Check if format is supported, convert if necessary
+		uploadName := ""
+		if track == 0 && largeVideo == 0 {
+			uploadName = resolveUploadName(args.File)
+		}
+		// Phone recordings are filtered and upsampled even if they could be uploaded as they are
+		filter := ""
+		if shouldApplyTelephony(args.Telephony, args.File, track) {
+			filter = telephonyFilter
+		}
+		if uploadName == "" || filter != "" {
+			switch {
+			case filter != "":
+				uiPrintln(tr(" Applying the phone-call preset (band-pass filter, upsampling to 16 kHz)..."))
+			case largeVideo > 0:
+				uiPrintf(tr(" Extracting the audio of the %s video...\n"), formatSize(largeVideo))
+			case track > 0:
+				uiPrintf(tr(" Extracting audio track %d from .%s to .mp4 format...\n"), track, ext)
+			default:
+				uiPrintf(tr(" Converting .%s to .mp4 format...\n"), ext)
+			}
+			convertedFile, cleanup, err := convertToMP4(ctx, args.File, track, filter)
+			if err != nil {
+				uiPrintf(tr(" Error converting audio file: %v\n"), err)
+				os.Exit(1)
+			}
+			defer cleanup() // Clean up converted file
+			if info, err := os.Stat(convertedFile); err == nil && largeVideo > 0 {
+				uiPrintf(tr(" Extracted %s of audio from the %s video\n"), formatSize(info.Size()), formatSize(largeVideo))
+			}
+			args.File = convertedFile
+			uploadName = filepath.Base(convertedFile)
+		} else if getFileExtension(uploadName) != ext {
+			uiPrintf(tr(" Uploading .%s directly as .%s (no conversion needed)\n"), ext, getFileExtension(uploadName))
+		}
+
+		// Print transcription parameters
+		printParameters(args, originalFile)
+
+		// Start transcription
+		uiPrintln(tr(" Starting transcription..."))
+
+		transcript, err = transcribe(ctx, client, args, args.File, uploadName)
+		if err != nil {
+			printAPIError(err)
 			os.Exit(1)
 		}
-		defer os.Remove(convertedFile) // Clean up converted file
-		args.File = convertedFile
 	}
 
-	// Print transcription parameters
-	printParameters(args, originalFile)
+	uiPrintln(tr("✅ Transcription completed successfully!"))
 
-	// Validate the audio file
-	file, err := os.Open(args.File)
+	// Hallucinations are looked for in the model's output, before anything edits it
+	if args.Hallucinations != hallucinationsOff {
+		checked := []*Transcript{transcript}
+		if len(chapterTranscripts) > 0 {
+			checked = chapterTranscripts
+		}
+		for _, t := range checked {
+			checkHallucinations(t, args.Hallucinations)
+		}
+		if len(chapterTranscripts) > 0 {
+			transcript = combineChapterTranscripts(chapters, chapterTranscripts)
+		}
+	}
+
+	// Without --language the commands are read in the detected language
+	if args.Dictation {
+		code := args.Language
+		if code == "" {
+			code, _ = normalizeLanguage(transcript.Language)
+		}
+		if language, err := dictationLanguageFor(code); err != nil {
+			uiPrintf(tr("⚠️  %v, leaving them as spoken\n"), err)
+		} else if len(chapterTranscripts) > 0 {
+			for _, chapterTranscript := range chapterTranscripts {
+				applyDictation(chapterTranscript, language)
+			}
+			transcript = combineChapterTranscripts(chapters, chapterTranscripts)
+		} else {
+			applyDictation(transcript, language)
+		}
+	}
+
+	// Macros expand after the dictation commands, so their text stays as written
+	if len(macros) > 0 {
+		expanded := 0
+		if len(chapterTranscripts) > 0 {
+			for _, chapterTranscript := range chapterTranscripts {
+				expanded += applyMacros(chapterTranscript, macros)
+			}
+			transcript = combineChapterTranscripts(chapters, chapterTranscripts)
+		} else {
+			expanded = applyMacros(transcript, macros)
+		}
+		if expanded > 0 {
+			uiPrintf(tr(" Expanded %d spoken macros\n"), expanded)
+		}
+	}
+
+	// With auto, languages without conventions of their own, like English, stay as they are
+	if args.Locale != "" {
+		code := args.Locale
+		if code == "auto" {
+			if code = args.Language; code == "" {
+				code, _ = normalizeLanguage(transcript.Language)
+			}
+		}
+		if conventions, err := localeConventionsFor(code); err == nil {
+			localized := []*Transcript{transcript}
+			if len(chapterTranscripts) > 0 {
+				localized = chapterTranscripts
+			}
+			for _, t := range localized {
+				applyLocale(t, conventions)
+			}
+			if len(chapterTranscripts) > 0 {
+				transcript = combineChapterTranscripts(chapters, chapterTranscripts)
+			}
+		}
+	}
+
+	// Speakers are labeled before the script runs, so it can rename them
+	if args.Turns {
+		uiPrintln(tr(" Detecting speaker turns from the audio..."))
+		turns, err := detectSpeakerTurns(ctx, originalFile, track)
+		if err != nil {
+			uiPrintf(tr("❌ Error detecting speaker turns: %v\n"), err)
+			os.Exit(1)
+		}
+		names := turnSpeakerNames(args.Speakers)
+		if len(chapterTranscripts) > 0 {
+			for i, chapterTranscript := range chapterTranscripts {
+				labelTurns(chapterTranscript, turns, chapters[i].Start, names)
+			}
+			transcript = combineChapterTranscripts(chapters, chapterTranscripts)
+		} else {
+			labelTurns(transcript, turns, 0, names)
+		}
+		uiPrintf(tr(" Found %d speaker turns\n"), len(turns))
+	}
+
+	// The script sees the final text, and every output its result
+	if script != nil {
+		transformed := []*Transcript{transcript}
+		if len(chapterTranscripts) > 0 {
+			transformed = chapterTranscripts
+		}
+		for _, t := range transformed {
+			if err := script.apply(t); err != nil {
+				uiPrintf(tr("❌ Error running script: %v\n"), err)
+				os.Exit(1)
+			}
+		}
+		if len(chapterTranscripts) > 0 {
+			transcript = combineChapterTranscripts(chapters, chapterTranscripts)
+		}
+	}
+
+	if args.Anonymize {
+		if err := anonymize(ctx, client, args, originalFile, mappingPassphrase, transcript, chapterTranscripts); err != nil {
+			uiPrintf(tr("❌ Error anonymizing transcription: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	// Chapters are tagged one by one so their own files get the tags too
+	if args.TagSegments {
+		if !needsSegments(args.Format) {
+			uiPrintf(tr("⚠️  Segment tags are only included in verbose_json and csv output, not in %s\n"), args.Format)
+		}
+		tagged := []*Transcript{transcript}
+		if len(chapterTranscripts) > 0 {
+			tagged = chapterTranscripts
+		}
+		if err := tagTranscripts(ctx, client, args, tagged); err != nil {
+			uiPrintf(tr("❌ Error tagging segments: %v\n"), err)
+			os.Exit(1)
+		}
+		if len(chapterTranscripts) > 0 {
+			transcript = combineChapterTranscripts(chapters, chapterTranscripts)
+		}
+	}
+
+	// Extract entities after anonymizing so the sidecar doesn't reveal the names
+	if args.Entities {
+		if err := saveEntities(ctx, client, args, originalFile, transcript); err != nil {
+			uiPrintf(tr("❌ Error extracting entities: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	if args.Minutes {
+		if err := saveMinutes(ctx, client, args, originalFile, transcript); err != nil {
+			uiPrintf(tr("❌ Error writing meeting minutes: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	if args.Interview {
+		if err := saveInterview(ctx, client, args, originalFile, transcript); err != nil {
+			uiPrintf(tr("❌ Error pairing interview questions and answers: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	mergeOptions := MergeOptions{
+		MaxPause:    args.MergePause,
+		Sentences:   args.MergeSentences,
+		MaxDuration: args.MergeMaxDuration,
+	}
+
+	if args.LabelStudio {
+		if err := saveLabelStudioTask(args, originalFile, transcript, mergeOptions); err != nil {
+			uiPrintf(tr("❌ Error writing Label Studio task: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	if args.Anki {
+		if err := saveAnkiDeck(ctx, client, args, originalFile, track, transcript); err != nil {
+			uiPrintf(tr("❌ Error creating Anki deck: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	if args.Bilingual != "" {
+		if err := saveBilingual(ctx, client, args, originalFile, transcript, mergeOptions); err != nil {
+			uiPrintf(tr("❌ Error creating bilingual transcript: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	if scorecardRules != nil {
+		if err := saveScorecard(args, originalFile, scorecardRules, transcript); err != nil {
+			uiPrintf(tr("❌ Error scoring the call: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	// Determine output file path; an e-book can't be printed, so it is always saved
+	outputFile := ""
+	if args.OutputDir != "" || args.OutputExt != "" || args.Format == "epub" {
+		outputFile = determineOutputFileName(args, originalFile)
+	}
+
+	// The html page plays the audio next to the transcript
+	page := htmlPage{Title: filepath.Base(originalFile)}
+	if args.Format == "html" {
+		if page.Audio, err = htmlAudioSource(args, originalFile, outputFile); err != nil {
+			uiPrintf(tr("❌ Error rendering transcription: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	for _, t := range append([]*Transcript{transcript}, chapterTranscripts...) {
+		t.Provider, t.Model = args.Provider, args.Model
+	}
+
+	// Handle response - we always get JSON from the API to avoid parsing issues
+	_, renderSpan := tracer.Start(ctx, "render")
+	var transcriptionText string
+	if args.Format == "epub" {
+		// The chapters go into the book instead of files of their own
+		book := epubChapters(fileStem(originalFile), transcript, chapters, chapterTranscripts)
+		transcriptionText, err = renderEPUB(fileStem(originalFile), transcript.Language, book, time.Now())
+		chapterTranscripts = nil
+	} else {
+		transcriptionText, err = renderTranscript(transcript, args.Format, mergeOptions, page)
+	}
+	endSpan(renderSpan, err)
 	if err != nil {
-		fmt.Printf(" Error opening audio file: %v\n", err)
+		uiPrintf(tr("❌ Error rendering transcription: %v\n"), err)
 		os.Exit(1)
 	}
-	defer file.Close()
 
-	// Start transcription
-	fmt.Println(" Starting transcription...")
+	// Print response to stdout or save to file
+	if outputFile != "" {
+		// Each chapter additionally gets its own file next to the combined one
+		for i, chapterTranscript := range chapterTranscripts {
+			chapterPage := htmlPage{Title: chapters[i].Title, Audio: page.Audio, Offset: chapters[i].Start}
+			text, err := renderTranscript(chapterTranscript, args.Format, mergeOptions, chapterPage)
+			if err != nil {
+				uiPrintf(tr("❌ Error rendering chapter transcription: %v\n"), err)
+				os.Exit(1)
+			}
+			chapterFile := chapterOutputFileName(outputFile, i+1)
+			if err := os.WriteFile(chapterFile, []byte(text), 0644); err != nil {
+				uiPrintf(tr("❌ Error writing chapter file: %v\n"), err)
+				os.Exit(1)
+			}
+		}
+		if len(chapterTranscripts) > 0 {
+			uiPrintf(tr("💾 %d chapter transcriptions saved next to the combined file\n"), len(chapterTranscripts))
+		}
+
+		err = os.WriteFile(outputFile, []byte(transcriptionText), 0644)
+		if err != nil {
+			uiPrintf(tr("❌ Error writing output file: %v\n"), err)
+			os.Exit(1)
+		}
+		uiPrintf(tr("💾 Transcription saved to: %s\n"), outputFile)
+	} else {
+		// Output to stdout with nice formatting
+		uiPrintln(tr("\n📝 Transcription:"))
+		printRule()
+		fmt.Println(transcriptionText)
+		printRule()
+		if accessibleOutput || ciOutput {
+			uiPrintln(tr("End of transcription."))
+		}
+	}
+
+	if raw != nil {
+		archive := rawArchive{Result: newResult(transcript, transcript.Segments), Responses: raw.responses}
+		rawFile := sidecarFileName(args, originalFile, rawArchiveSuffix)
+		if err := writeRawArchive(rawFile, archive); err != nil {
+			uiPrintf(tr("❌ Error saving raw responses: %v\n"), err)
+			os.Exit(1)
+		}
+		uiPrintf(tr("💾 Raw responses saved to: %s\n"), rawFile)
+	}
+
+	if args.PostHook != "" {
+		payload := hookPayload{Input: originalFile, Output: outputFile, Format: args.Format, Result: newResult(transcript, transcript.Segments)}
+		if err := runPostHook(args.PostHook, payload); err != nil {
+			uiPrintf(tr("❌ Post-hook failed: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+
+	seconds := audioSeconds(transcript, originalFile)
+	if err := writeJobReport(args, seconds); err != nil {
+		uiPrintf(tr("⚠️  Could not write job report: %v\n"), err)
+	}
+	if config.UsageLog {
+		if err := recordUsage(UsageRecord{At: time.Now(), Provider: args.Provider, Model: args.Model, AudioSeconds: seconds}); err != nil {
+			uiPrintf(tr("⚠️  Could not record usage: %v\n"), err)
+		}
+	}
+
+	if args.FailOnWarns {
+		if warnings := warningCount(); warnings > 0 {
+			uiPrintf(tr("❌ Failing because of %d warnings (--fail-on-warnings)\n"), warnings)
+			os.Exit(1)
+		}
+	}
+}
+
+// transcribe runs the full transcription of a prepared audio file: the best-of
+// runs followed by the optional refinement pass
+func transcribe(ctx context.Context, client openai.Client, args Args, path, uploadName string) (*Transcript, error) {
+	transcript, err := transcribeWithFallback(ctx, client, args, path, uploadName)
+	if err != nil {
+		return nil, err
+	}
 
-	// Reset file pointer to beginning
-	file.Seek(0, 0)
+	if args.RefineBelow != nil {
+		if err := refineTranscript(ctx, client, args, path, transcript); err != nil {
+			return nil, fmt.Errorf("refinement failed: %w", err)
+		}
+	}
+
+	if args.HallucinationRetries > 0 && args.Hallucinations != hallucinationsOff {
+		if err := retryHallucinations(ctx, client, args, path, transcript); err != nil {
+			return nil, fmt.Errorf("retrying hallucinations failed: %w", err)
+		}
+	}
+	return transcript, nil
+}
+
+// transcribeFile uploads a single prepared audio file and returns its transcript
+func transcribeFile(ctx context.Context, client openai.Client, args Args, path, uploadName string) (*Transcript, error) {
+	ctx, span := tracer.Start(ctx, "transcribe", trace.WithAttributes(
+		attribute.String("pindar.model", args.Model),
+		attribute.Float64("pindar.temperature", args.Temperature),
+	))
+	defer span.End()
+
+	// Validate the audio file
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer file.Close()
 
 	// Create the transcription params with required parameters
 	params := openai.AudioTranscriptionNewParams{
-		File:  file,
+		File:  openai.File(file, uploadFileName(uploadName), ""),
 		Model: openai.AudioModel(args.Model),
 	}
 
@@ -164,89 +1013,66 @@ func main() {
 	// Set response format - always use JSON to avoid plain text parsing issues
 	// We'll handle the user's desired format in post-processing
 	params.ResponseFormat = openai.AudioResponseFormatJSON
+	// The fallback judges whisper-1's results by their segments
+	if args.wantsSegments() || args.Fallback != "" && modelSupportsTimestamps(args.Model) {
+		params.ResponseFormat = openai.AudioResponseFormatVerboseJSON
+		params.TimestampGranularities = []string{"segment"}
+		if needsWords(args.Format) {
+			params.TimestampGranularities = append(params.TimestampGranularities, "word")
+		}
+	}
 
 	if args.Temperature != 0 {
 		params.Temperature = param.NewOpt(args.Temperature)
 	}
 
-	// Create a context for the request
-	ctx := context.Background()
+	// The gpt-4o models report confidence as token logprobs instead of segments
+	if (args.BestOf > 1 || args.Fallback != "") && !args.wantsSegments() && !modelSupportsTimestamps(args.Model) {
+		params.Include = []openai.TranscriptionInclude{openai.TranscriptionIncludeLogprobs}
+	}
 
-	// Send the transcription request
-	response, err := client.Audio.Transcriptions.New(ctx, params)
+	// Send the transcription request with a timeout that grows with the audio
+	timeout := transcriptionTimeout(path)
+	progress := &requestProgress{}
+	stop := progress.report(timeout)
+	response, err := client.Audio.Transcriptions.New(ctx, params,
+		option.WithRequestTimeout(timeout), option.WithMiddleware(progress.middleware))
+	stop()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("transcription timed out after %s: %w", timeout.Round(time.Second), err)
+	}
 	if err != nil {
-		// Handle specific error cases gracefully
-		errStr := err.Error()
-
-		if strings.Contains(errStr, "longer than 1500 seconds") || strings.Contains(errStr, "maximum for this model") {
-			fmt.Printf("❌ Audio file too long: The audio duration exceeds the 25-minute limit for this model.\n")
-			fmt.Printf("💡 Suggestions:\n")
-			fmt.Printf("   • Split the audio into shorter segments (< 25 minutes each)\n")
-			fmt.Printf("   • Use audio editing software to create multiple files\n")
-			fmt.Printf("   • Consider using a different transcription service for longer files\n")
-		} else if strings.Contains(errStr, "invalid_api_key") || strings.Contains(errStr, "Incorrect API key") {
-			fmt.Printf("❌ API Key Error: Invalid or missing OpenAI API key.\n")
-			fmt.Printf("💡 Please check your API key and try again.\n")
-		} else if strings.Contains(errStr, "quota") || strings.Contains(errStr, "rate_limit") {
-			fmt.Printf("❌ Rate Limit/Quota Error: API usage limit reached.\n")
-			fmt.Printf("💡 Please wait a moment and try again, or check your OpenAI account billing.\n")
-		} else {
-			fmt.Printf("❌ Error calling OpenAI API: %v\n", err)
-		}
-		os.Exit(1)
+		return nil, err
 	}
+	return transcriptFromResponse(response)
+}
 
-	fmt.Println("✅ Transcription completed successfully!")
-
-	// Handle response - we always get JSON format from API to avoid parsing issues
-	var transcriptionText string
+// printAPIError explains common API failures with actionable suggestions
+func printAPIError(err error) {
+	// Handle specific error cases gracefully
+	errStr := err.Error()
 
-	switch args.Format {
-	case "text", "":
-		// User wants plain text - just use the text field
-		transcriptionText = response.Text
-	case "verbose_json":
-		// User wants verbose JSON - we need to note that we're using standard JSON
-		// since we forced JSON format, this is what we get
-		transcriptionText = response.Text
-	case "srt", "vtt":
-		// For SRT and VTT, we only get plain text from the API
-		// The user would need to use a different service for timestamp formatting
-		// For now, return the text with a note
-		transcriptionText = response.Text
-		fmt.Printf("⚠️  Note: SRT/VTT formats require timestamps. Using text output instead.\n")
-	default:
-		transcriptionText = response.Text
-	}
-
-	// Determine output file path
-	outputFile := ""
-	if args.OutputDir != "" || args.OutputExt != "" {
-		outputFile = determineOutputFileName(args, originalFile)
-	}
-
-	// Print response to stdout or save to file
-	if outputFile != "" {
-		err = os.WriteFile(outputFile, []byte(transcriptionText), 0644)
-		if err != nil {
-			fmt.Printf("❌ Error writing output file: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Printf("💾 Transcription saved to: %s\n", outputFile)
+	if strings.Contains(errStr, "longer than 1500 seconds") || strings.Contains(errStr, "maximum for this model") {
+		uiPrint(tr("❌ Audio file too long: The audio duration exceeds the 25-minute limit for this model.\n"))
+		uiPrint(tr("💡 Suggestions:\n"))
+		uiPrint(tr("   • Split the audio into shorter segments (< 25 minutes each)\n"))
+		uiPrint(tr("   • Use audio editing software to create multiple files\n"))
+		uiPrint(tr("   • Consider using a different transcription service for longer files\n"))
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		uiPrintf(tr("❌ Timeout: %v\n"), err)
+		uiPrint(tr("💡 The time limit grows with the audio duration. Check your connection, or split the recording into shorter files.\n"))
+	} else if strings.Contains(errStr, "invalid_api_key") || strings.Contains(errStr, "Incorrect API key") {
+		uiPrint(tr("❌ API Key Error: Invalid or missing OpenAI API key.\n"))
+		uiPrint(tr("💡 Please check your API key and try again.\n"))
+	} else if strings.Contains(errStr, "quota") || strings.Contains(errStr, "rate_limit") {
+		uiPrint(tr("❌ Rate Limit/Quota Error: API usage limit reached.\n"))
+		uiPrint(tr("💡 Please wait a moment and try again, or check your OpenAI account billing.\n"))
 	} else {
-		// Output to stdout with nice formatting
-		fmt.Println("\n📝 Transcription:")
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-		fmt.Printf("%s\n", transcriptionText)
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		uiPrintf(tr("❌ Error calling OpenAI API: %v\n"), err)
 	}
 }
 
 func determineOutputFileName(args Args, originalFile string) string {
-	base := filepath.Base(originalFile)
-	ext := filepath.Ext(base)
-	nameWithoutExt := base[:len(base)-len(ext)]
-
 	var outputExt string
 	if args.OutputExt != "" {
 		outputExt = args.OutputExt
@@ -260,12 +1086,42 @@ func determineOutputFileName(args Args, originalFile string) string {
 			outputExt = ".srt"
 		case "vtt":
 			outputExt = ".vtt"
+		case "ttml":
+			outputExt = ".ttml"
+		case "scc":
+			outputExt = ".scc"
 		case "verbose_json":
 			outputExt = ".json"
+		case "csv":
+			outputExt = ".csv"
+		case "ass":
+			outputExt = ".ass"
+		case "lrc":
+			outputExt = ".lrc"
+		case "html":
+			outputExt = ".html"
+		case "epub":
+			outputExt = ".epub"
+		case "audacity-labels":
+			outputExt = ".labels.txt"
 		default:
 			outputExt = ".txt"
 		}
 	}
 
-	return filepath.Join(args.OutputDir, nameWithoutExt+outputExt)
+	return filepath.Join(args.OutputDir, fitFileName(outputStem(args, originalFile), outputExt))
+}
+
+// sidecarFileName returns the path of an additional output file like the
+// entities or the name mapping, placed in the output directory
+func sidecarFileName(args Args, originalFile, suffix string) string {
+	return filepath.Join(args.OutputDir, fitFileName(outputStem(args, originalFile), suffix))
+}
+
+// outputStem returns the name of the output files without extension
+func outputStem(args Args, originalFile string) string {
+	if args.OutputName != "" {
+		return args.OutputName
+	}
+	return fileStem(originalFile)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"io"
 	"os"
 	"path/filepath"
@@ -69,6 +70,22 @@ func TestDetermineOutputFileName(t *testing.T) {
 			},
 			expected: "audio.json",
 		},
+		{
+			name: "CSV format",
+			args: Args{
+				File:   "/path/to/audio.mp3",
+				Format: "csv",
+			},
+			expected: "audio.csv",
+		},
+		{
+			name: "Audacity labels format",
+			args: Args{
+				File:   "/path/to/audio.mp3",
+				Format: "audacity-labels",
+			},
+			expected: "audio.labels.txt",
+		},
 		{
 			name: "Custom extension",
 			args: Args{
@@ -255,7 +272,10 @@ func TestConvertToMP4_FFmpegNotFound(t *testing.T) {
 	defer os.Remove(unsupportedFile)
 
 	// Test conversion (this will likely fail unless ffmpeg is installed)
-	_, err = convertToMP4(unsupportedFile)
+	_, cleanup, err := convertToMP4(context.Background(), unsupportedFile, 0, "")
+	if err == nil {
+		cleanup()
+	}
 	
 	// We expect either success (if ffmpeg is available) or a specific error
 	if err != nil && !strings.Contains(err.Error(), "ffmpeg not found") && !strings.Contains(err.Error(), "ffmpeg conversion failed") {
@@ -263,6 +283,22 @@ func TestConvertToMP4_FFmpegNotFound(t *testing.T) {
 	}
 }
 
+func TestConvertToMP4RemovesTempDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+	input := filepath.Join(t.TempDir(), "broken.aiff")
+	if err := os.WriteFile(input, []byte("not audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := convertToMP4(context.Background(), input, 0, ""); err == nil {
+		t.Fatal("Expected converting a broken file to fail")
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 0 {
+		t.Errorf("Expected a failed conversion to leave no temporary files, got %d", len(entries))
+	}
+}
+
 func TestResponseFormatHandling(t *testing.T) {
 	tests := []struct {
 		name           string
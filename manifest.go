@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// manifestColumns are the columns of a --manifest CSV; only file is required
+var manifestColumns = []string{"file", "language", "prompt", "output"}
+
+// manifestJob is one row of a --manifest CSV
+type manifestJob struct {
+	Line     int
+	Input    string
+	Language string
+	Prompt   string
+	Output   string
+}
+
+// readManifest parses a manifest CSV with a header row. Columns are matched by
+// name in any order, rows without a file are skipped, and relative paths are
+// resolved against dir.
+func readManifest(r io.Reader, dir string) ([]manifestJob, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["file"]; !ok {
+		return nil, fmt.Errorf(tr("the manifest has no file column (columns: %s)"), strings.Join(manifestColumns, ", "))
+	}
+
+	var jobs []manifestJob
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		job := manifestJob{Line: line, Input: field("file"), Prompt: field("prompt"), Output: field("output")}
+		if job.Input == "" {
+			continue
+		}
+		if !isURL(job.Input) && !filepath.IsAbs(job.Input) {
+			job.Input = filepath.Join(dir, job.Input)
+		}
+		if job.Language, err = normalizeLanguage(field("language")); err != nil {
+			return nil, fmt.Errorf(tr("line %d: %w"), line, err)
+		}
+		jobs = append(jobs, job)
+	}
+	if len(jobs) == 0 {
+		return nil, errors.New(tr("the manifest lists no files"))
+	}
+	return jobs, nil
+}
+
+// isURL reports whether a manifest input is an http(s) URL instead of a path
+func isURL(input string) bool {
+	return strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://")
+}
+
+// downloadStallTimeout is how long a download may wait for the server to
+// answer or send more data before it fails, so a server that never responds
+// doesn't stall the batch. Long downloads that keep receiving data go on.
+var downloadStallTimeout = time.Minute
+
+// stallReader postpones the timer of a download with every read that
+// receives data
+type stallReader struct {
+	io.Reader
+	timer *time.Timer
+}
+
+func (r stallReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.timer.Reset(downloadStallTimeout)
+	}
+	return n, err
+}
+
+// downloadInput downloads a manifest URL into dir, named after the file name in
+// the Content-Disposition header or else the URL's last path element
+func downloadInput(rawURL, dir string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s: %w", rawURL, err)
+	}
+	name := path.Base(parsed.Path)
+	if name == "/" || name == "." {
+		name = "download"
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	timer := time.AfterFunc(downloadStallTimeout, func() {
+		cancel(fmt.Errorf(tr("no data for %s"), downloadStallTimeout))
+	})
+	defer timer.Stop()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s: %w", rawURL, err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", rawURL, downloadError(ctx, err))
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", rawURL, res.Status)
+	}
+	// Servers of recordings behind download links name the file in the header
+	if _, params, err := mime.ParseMediaType(res.Header.Get("Content-Disposition")); err == nil {
+		if filename := filepath.Base(filepath.FromSlash(params["filename"])); params["filename"] != "" && filename != "." && filename != ".." {
+			name = filename
+		}
+	}
+
+	dest := filepath.Join(dir, fitFileName(fileStem(name), filepath.Ext(name)))
+	f, err := os.Create(dest)
+	if err != nil {
+		return "", fmt.Errorf("failed to create download file: %w", err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, stallReader{Reader: res.Body, timer: timer}); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", rawURL, downloadError(ctx, err))
+	}
+	return dest, nil
+}
+
+// downloadError returns why a download was cancelled, instead of the bare
+// "context canceled" of the request
+func downloadError(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); cause != nil {
+		return cause
+	}
+	return err
+}
+
+// withoutFlag removes a flag and its value from command line arguments
+func withoutFlag(argv []string, name string) []string {
+	var kept []string
+	for i := 0; i < len(argv); i++ {
+		switch {
+		case argv[i] == name:
+			i++ // skip the value
+		case strings.HasPrefix(argv[i], name+"="):
+		default:
+			kept = append(kept, argv[i])
+		}
+	}
+	return kept
+}
+
+// manifestJobArgs returns the arguments for transcribing one job: the options
+// of the manifest run, overridden by the row's settings
+func manifestJobArgs(options []string, job manifestJob, input string) []string {
+	argv := append([]string{}, options...)
+	if job.Language != "" {
+		argv = append(argv, "--language", job.Language)
+	}
+	if job.Prompt != "" {
+		argv = append(argv, "--prompt", job.Prompt)
+	}
+	if job.Output != "" {
+		argv = append(argv, "--output-name", job.Output)
+	}
+	return append(argv, "--", input)
+}
+
+// runManifest transcribes every file of a manifest with a separate pindar
+// process, so a failing file doesn't stop the others
+func runManifest(args Args, argv []string) {
+	f, err := os.Open(args.Manifest)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading manifest: %v\n"), err)
+		os.Exit(1)
+	}
+	jobs, err := readManifest(f, filepath.Dir(args.Manifest))
+	f.Close()
+	if err != nil {
+		uiPrintf(tr("❌ Error reading manifest: %v\n"), err)
+		os.Exit(1)
+	}
+
+	runJobs(args, withoutFlag(argv, "--manifest"), jobs)
+	uiPrintf(tr("\n✅ Transcribed all %d files of the manifest\n"), len(jobs))
+}
+
+// runJobs transcribes the jobs of a manifest or URL list, each with the
+// options of the run in a separate pindar process. URLs are downloaded
+// first; downloads with the same name get numbered output names so they
+// don't overwrite each other's transcripts.
+func runJobs(args Args, options []string, jobs []manifestJob) {
+	executable, err := os.Executable()
+	if err != nil {
+		uiPrintf(tr("❌ Error running manifest: %v\n"), err)
+		os.Exit(1)
+	}
+	tmpDir, err := os.MkdirTemp("", "pindar_manifest")
+	if err != nil {
+		uiPrintf(tr("❌ Error running manifest: %v\n"), err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Ask for the API key once instead of in every process
+	env := os.Environ()
+	if args.Provider != providerFake && args.ReplayCassette == "" {
+		apiKey, err := getAPIKey(args.APIKey)
+		if err != nil {
+			uiPrintf(tr(" Error getting API key: %v\n"), err)
+			os.Exit(1)
+		}
+		env = append(env, "OPENAI_API_KEY="+apiKey)
+	}
+
+	options = withoutFlag(withoutFlag(options, "--api-key"), "--summary")
+	downloads := map[string]int{}
+	var summary batchSummary
+	for i, job := range jobs {
+		uiPrintf("\n[%d/%d] %s\n", i+1, len(jobs), job.Input)
+
+		input := job.Input
+		if isURL(input) {
+			if input, err = downloadInput(job.Input, tmpDir); err != nil {
+				uiPrintf("❌ %v\n", err)
+				summary.addFailure(job.Input, err.Error())
+				continue
+			}
+			if job.Output == "" {
+				job.Output = numberedName(downloads, fileStem(input))
+			}
+		}
+
+		cmd := exec.Command(executable, manifestJobArgs(options, job, input)...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		cmd.Env = env
+		if report, elapsed, err := runBatchJob(cmd, tmpDir); err != nil {
+			summary.addFailure(job.Input, err.Error())
+		} else {
+			summary.addTranscribed(report, elapsed)
+		}
+		if input != job.Input {
+			os.Remove(input)
+		}
+	}
+
+	finishBatch(&summary, args.Summary)
+}
+
+// numberedName returns name the first time, and name-2, name-3 and so on
+// when it was seen before
+func numberedName(seen map[string]int, name string) string {
+	seen[name]++
+	if seen[name] == 1 {
+		return name
+	}
+	return fmt.Sprintf("%s-%d", name, seen[name])
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadManifest(t *testing.T) {
+	csv := "\ufeffOutput, File ,language,prompt\n" +
+		"intro,talks/intro.mp3,German,\"Pindar, Whisper\"\n" +
+		",,,\n" +
+		",https://example.com/audio/q&a.m4a,,\n" +
+		",/abs/b.wav,,\n"
+	jobs, err := readManifest(strings.NewReader(csv), "jobs")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []manifestJob{
+		{Line: 2, Input: filepath.Join("jobs", "talks/intro.mp3"), Language: "de", Prompt: "Pindar, Whisper", Output: "intro"},
+		{Line: 4, Input: "https://example.com/audio/q&a.m4a"},
+		{Line: 5, Input: "/abs/b.wav"},
+	}
+	if !slices.Equal(jobs, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, jobs)
+	}
+}
+
+func TestReadManifestErrors(t *testing.T) {
+	tests := map[string]string{
+		"no file column":   "path,language\na.mp3,de\n",
+		"no files":         "file,language\n,de\n",
+		"invalid language": "file,language\na.mp3,klingon\n",
+		"empty":            "",
+	}
+	for name, csv := range tests {
+		if _, err := readManifest(strings.NewReader(csv), "."); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestWithoutFlag(t *testing.T) {
+	argv := []string{"--manifest", "jobs.csv", "--format", "srt", "--manifest=other.csv", "--api-key", "sk-1"}
+	got := withoutFlag(withoutFlag(argv, "--manifest"), "--api-key")
+	if expected := []string{"--format", "srt"}; !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestManifestJobArgs(t *testing.T) {
+	job := manifestJob{Language: "de", Prompt: "Namen", Output: "intro"}
+	got := manifestJobArgs([]string{"--format", "srt", "--language", "en"}, job, "-talk.mp3")
+	expected := []string{"--format", "srt", "--language", "en", "--language", "de", "--prompt", "Namen", "--output-name", "intro", "--", "-talk.mp3"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestDownloadInput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/media/episode 1.mp3" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path, err := downloadInput(server.URL+"/media/episode%201.mp3?token=abc", dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path != filepath.Join(dir, "episode 1.mp3") {
+		t.Errorf("Expected the file to be named after the URL path, got %s", path)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "audio" {
+		t.Errorf("Expected the downloaded content, got %q", content)
+	}
+
+	if _, err := downloadInput(server.URL+"/missing.mp3", dir); err == nil {
+		t.Error("Expected an error for a failed download")
+	}
+}
+
+func TestDownloadInputStalled(t *testing.T) {
+	defer func(timeout time.Duration) { downloadStallTimeout = timeout }(downloadStallTimeout)
+	downloadStallTimeout = 50 * time.Millisecond
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow.mp3" {
+			// Keeps sending, just slower than the timeout allows in total
+			for range 4 {
+				w.Write([]byte("audio"))
+				w.(http.Flusher).Flush()
+				time.Sleep(20 * time.Millisecond)
+			}
+			return
+		}
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	dir := t.TempDir()
+	if _, err := downloadInput(server.URL+"/stalled.mp3", dir); err == nil || !strings.Contains(err.Error(), "no data") {
+		t.Errorf("Expected a stalled download to fail, got %v", err)
+	}
+	path, err := downloadInput(server.URL+"/slow.mp3", dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != strings.Repeat("audio", 4) {
+		t.Errorf("Expected a download that keeps receiving data to finish, got %q", content)
+	}
+}
+
+func TestDownloadInputContentDisposition(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="../Team Call 2024-05-01.m4a"`)
+		w.Write([]byte("audio"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path, err := downloadInput(server.URL+"/recordings/download?id=42", dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path != filepath.Join(dir, "Team Call 2024-05-01.m4a") {
+		t.Errorf("Expected the file to be named after the header, got %s", path)
+	}
+}
+
+func TestNumberedName(t *testing.T) {
+	seen := map[string]int{}
+	var got []string
+	for _, name := range []string{"audio", "intro", "audio", "audio"} {
+		got = append(got, numberedName(seen, name))
+	}
+	if expected := []string{"audio", "intro", "audio-2", "audio-3"}; !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestOutputNameOverride(t *testing.T) {
+	args := Args{OutputDir: "out", OutputName: "Episode 1", Format: "srt"}
+	if got := determineOutputFileName(args, "in/recording.mp3"); got != filepath.Join("out", "Episode 1.srt") {
+		t.Errorf("Expected the output name to replace the input name, got %s", got)
+	}
+	if got := sidecarFileName(args, "in/recording.mp3", ".minutes.md"); got != filepath.Join("out", "Episode 1.minutes.md") {
+		t.Errorf("Expected sidecars to follow the output name, got %s", got)
+	}
+}
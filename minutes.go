@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// MeetingMinutes are the structured minutes extracted with --meeting-minutes
+type MeetingMinutes struct {
+	Summary       string       `json:"summary"`
+	Decisions     []string     `json:"decisions"`
+	ActionItems   []ActionItem `json:"action_items"`
+	OpenQuestions []string     `json:"open_questions"`
+}
+
+// ActionItem is a task agreed on in a meeting
+type ActionItem struct {
+	Task  string `json:"task"`
+	Owner string `json:"owner"`
+	Due   string `json:"due"`
+}
+
+// extractMinutes asks the analysis model for the minutes of a meeting transcript
+func extractMinutes(ctx context.Context, client openai.Client, model, text string) (*MeetingMinutes, error) {
+	instructions := `You write meeting minutes from transcripts. Return JSON of the form ` +
+		`{"summary": ..., "decisions": [...], "action_items": [{"task": ..., "owner": ..., "due": ...}], "open_questions": [...]}. ` +
+		`"summary" is two or three sentences. Only list decisions that were actually made, action items someone ` +
+		`agreed to do and questions that were left unanswered. Use an empty string for an unknown owner or due date. ` +
+		`Write in the language of the transcript.`
+
+	var minutes MeetingMinutes
+	if err := chatJSON(ctx, client, model, instructions, text, &minutes); err != nil {
+		return nil, err
+	}
+	return &minutes, nil
+}
+
+// renderMinutes formats meeting minutes as Markdown
+func renderMinutes(title string, minutes *MeetingMinutes) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Meeting minutes: %s\n", title)
+
+	if summary := strings.TrimSpace(minutes.Summary); summary != "" {
+		fmt.Fprintf(&b, "\n## Summary\n\n%s\n", summary)
+	}
+
+	writeList := func(heading string, items []string) {
+		fmt.Fprintf(&b, "\n## %s\n\n", heading)
+		if len(items) == 0 {
+			b.WriteString("_None_\n")
+		}
+		for _, item := range items {
+			fmt.Fprintf(&b, "- %s\n", strings.TrimSpace(item))
+		}
+	}
+
+	writeList("Decisions", minutes.Decisions)
+
+	var actions []string
+	for _, item := range minutes.ActionItems {
+		action := "[ ] " + strings.TrimSpace(item.Task)
+		if owner := strings.TrimSpace(item.Owner); owner != "" {
+			action += " — **" + owner + "**"
+		}
+		if due := strings.TrimSpace(item.Due); due != "" {
+			action += " (due: " + due + ")"
+		}
+		actions = append(actions, action)
+	}
+	writeList("Action items", actions)
+
+	writeList("Open questions", minutes.OpenQuestions)
+	return b.String()
+}
+
+// saveMinutes extracts the meeting minutes and writes them to a Markdown sidecar file
+func saveMinutes(ctx context.Context, client openai.Client, args Args, originalFile string, transcript *Transcript) error {
+	uiPrintf(tr(" Writing meeting minutes with %s...\n"), args.AnalysisModel)
+	minutes, err := extractMinutes(ctx, client, args.AnalysisModel, transcript.Text)
+	if err != nil {
+		return err
+	}
+
+	minutesFile := sidecarFileName(args, originalFile, ".minutes.md")
+	if err := os.WriteFile(minutesFile, []byte(rtlMarkdown(renderMinutes(filepath.Base(originalFile), minutes), transcript.Language)), 0644); err != nil {
+		return fmt.Errorf("failed to write minutes file: %w", err)
+	}
+	uiPrintf(tr("💾 Meeting minutes saved to: %s\n"), minutesFile)
+	return nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRenderMinutes(t *testing.T) {
+	minutes := &MeetingMinutes{
+		Summary:   "The team planned the release.",
+		Decisions: []string{"Ship on Friday"},
+		ActionItems: []ActionItem{
+			{Task: "Write release notes", Owner: "Anna", Due: "Thursday"},
+			{Task: "Update the website"},
+		},
+	}
+
+	expected := "# Meeting minutes: standup.mp3\n" +
+		"\n## Summary\n\nThe team planned the release.\n" +
+		"\n## Decisions\n\n- Ship on Friday\n" +
+		"\n## Action items\n\n- [ ] Write release notes — **Anna** (due: Thursday)\n- [ ] Update the website\n" +
+		"\n## Open questions\n\n_None_\n"
+	result := renderMinutes("standup.mp3", minutes)
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// speakerTrack is the recording of one speaker from a multitrack recorder
+type speakerTrack struct {
+	Name string
+	Path string
+}
+
+// parseSpeakerTracks parses --tracks values of the form name=file. Several
+// tracks may be given in one value, separated by commas.
+func parseSpeakerTracks(values []string) ([]speakerTrack, error) {
+	var tracks []speakerTrack
+	seen := map[string]bool{}
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			name, path, ok := strings.Cut(item, "=")
+			name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+			if !ok || name == "" || path == "" {
+				return nil, fmt.Errorf(tr("invalid track %q, expected name=file"), item)
+			}
+			if seen[strings.ToLower(name)] {
+				return nil, fmt.Errorf(tr("%s has more than one track"), name)
+			}
+			seen[strings.ToLower(name)] = true
+			tracks = append(tracks, speakerTrack{Name: name, Path: path})
+		}
+	}
+	if len(tracks) < 2 {
+		return nil, errors.New(tr("--tracks needs the recordings of at least two speakers"))
+	}
+	return tracks, nil
+}
+
+// speakerTrackNames returns the speakers of the tracks in order
+func speakerTrackNames(tracks []speakerTrack) []string {
+	names := make([]string, len(tracks))
+	for i, track := range tracks {
+		names[i] = track.Name
+	}
+	return names
+}
+
+// transcribeSpeakerTracks transcribes the recording of each speaker and
+// interleaves them into one transcript labeled by speaker like a split call.
+// The tracks have to start at the same time, as those of a multitrack
+// recorder do, for their timestamps to line up.
+func transcribeSpeakerTracks(ctx context.Context, client openai.Client, args Args, tracks []speakerTrack, track int) (*Transcript, error) {
+	legs := make([]*Transcript, len(tracks))
+	for i, speaker := range tracks {
+		uiPrintf(" [%d/%d] %s\n", i+1, len(tracks), speaker.Name)
+		path, uploadName := speaker.Path, ""
+		if track == 0 {
+			uploadName = resolveUploadName(speaker.Path)
+		}
+		filter := ""
+		if shouldApplyTelephony(args.Telephony, speaker.Path, track) {
+			filter = telephonyFilter
+		}
+		if uploadName == "" || filter != "" {
+			converted, cleanup, err := convertToMP4(ctx, speaker.Path, track, filter)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", speaker.Name, err)
+			}
+			defer cleanup()
+			path, uploadName = converted, filepath.Base(converted)
+		}
+
+		var err error
+		if legs[i], err = transcribe(ctx, client, args, path, uploadName); err != nil {
+			return nil, fmt.Errorf("%s: %w", speaker.Name, err)
+		}
+	}
+	return combineCallLegs(legs, speakerTrackNames(tracks)), nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSpeakerTracks(t *testing.T) {
+	tracks, err := parseSpeakerTracks([]string{"alice=track1.wav, bob = track2.wav", "Ciarán=mics/track 3.wav"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []speakerTrack{{"alice", "track1.wav"}, {"bob", "track2.wav"}, {"Ciarán", "mics/track 3.wav"}}
+	if !slices.Equal(tracks, expected) {
+		t.Errorf("Expected %v, got %v", expected, tracks)
+	}
+	if names := speakerTrackNames(tracks); !slices.Equal(names, []string{"alice", "bob", "Ciarán"}) {
+		t.Errorf("Unexpected names %v", names)
+	}
+}
+
+func TestParseSpeakerTracksErrors(t *testing.T) {
+	tests := map[string][]string{
+		"no name":        {"=a.wav,bob=b.wav"},
+		"no file":        {"alice=,bob=b.wav"},
+		"no separator":   {"a.wav,b.wav"},
+		"same speaker":   {"alice=a.wav", "Alice=b.wav"},
+		"a single track": {"alice=a.wav"},
+		"trailing comma": {"alice=a.wav,bob=b.wav,"},
+	}
+	for name, values := range tests {
+		if _, err := parseSpeakerTracks(values); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
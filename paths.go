@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxFileNameBytes is the longest file name common file systems accept
+const maxFileNameBytes = 255
+
+// maxUploadStemBytes limits the name sent to the API; it only needs to be recognizable
+const maxUploadStemBytes = 64
+
+// fileStem returns the file name of path without its extension. Names that
+// are only an extension, like ".mp3", are returned whole.
+func fileStem(path string) string {
+	base := filepath.Base(path)
+	if stem := strings.TrimSuffix(base, filepath.Ext(base)); stem != "" {
+		return stem
+	}
+	return base
+}
+
+// fitFileName shortens stem so that stem+suffix fits in a file name, cutting
+// at a character boundary so multi-byte characters and emoji stay intact
+func fitFileName(stem, suffix string) string {
+	limit := maxFileNameBytes - len(suffix)
+	if len(stem) <= limit {
+		return stem + suffix
+	}
+	return truncateUTF8(stem, max(limit, 0)) + suffix
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// ffmpegPath marks a path as a local file for ffmpeg and ffprobe, which would
+// otherwise read "a:b.mp3" as a protocol and "-x.mp3" as an option
+func ffmpegPath(path string) string {
+	return "file:" + path
+}
+
+// uploadFileName returns a plain ASCII name for the upload. The API detects
+// the format by the extension, which is kept; other characters like spaces,
+// quotes and emoji are replaced since they don't survive every multipart parser.
+func uploadFileName(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+	if strings.IndexFunc(ext[min(1, len(ext)):], func(r rune) bool { return !isASCIIAlnum(r) }) != -1 {
+		ext = ""
+	}
+
+	var b strings.Builder
+	underscore := false
+	for _, r := range sccTransliterations.Replace(stem) {
+		if isASCIIAlnum(r) || r == '-' || r == '.' {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+
+	safe := strings.Trim(truncateUTF8(b.String(), maxUploadStemBytes), "_.-")
+	if safe == "" {
+		safe = "audio"
+	}
+	return safe + ext
+}
+
+// isASCIIAlnum reports whether r is an ASCII letter or digit
+func isASCIIAlnum(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// outputDirPlaceholder matches the date placeholders of --output-dir
+var outputDirPlaceholder = regexp.MustCompile(`\{[^{}/\\]*\}`)
+
+// expandOutputDir expands a leading ~ to the home directory and replaces
+// {year}, {month}, {day} and {date} with the current date, so transcripts
+// can be sorted into e.g. ~/transcripts/{year}/{month}
+func expandOutputDir(dir string, now time.Time) (string, error) {
+	if dir == "~" || strings.HasPrefix(dir, "~/") || strings.HasPrefix(dir, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+
+	var unknown string
+	dir = outputDirPlaceholder.ReplaceAllStringFunc(dir, func(placeholder string) string {
+		switch placeholder {
+		case "{year}":
+			return now.Format("2006")
+		case "{month}":
+			return now.Format("01")
+		case "{day}":
+			return now.Format("02")
+		case "{date}":
+			return now.Format("2006-01-02")
+		}
+		unknown = placeholder
+		return placeholder
+	})
+	if unknown != "" {
+		return "", fmt.Errorf(tr("unknown placeholder %s in --output-dir, use {year}, {month}, {day} or {date}"), unknown)
+	}
+	return dir, nil
+}
+
+// ensureOutputDir creates the output directory unless it exists or create is false
+func ensureOutputDir(dir string, create bool) error {
+	info, err := os.Stat(dir)
+	switch {
+	case err == nil && !info.IsDir():
+		return fmt.Errorf(tr("the output directory %s is a file"), dir)
+	case err == nil:
+		return nil
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	case !create:
+		return fmt.Errorf(tr("the output directory %s does not exist (leave out --no-create-dirs to create it)"), dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	uiPrintf(tr(" Created output directory %s\n"), dir)
+	return nil
+}
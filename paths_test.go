@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestFileStem(t *testing.T) {
+	tests := map[string]string{
+		"dir/my talk.mp3":    "my talk",
+		"Interview 🎙️.m4a":   "Interview 🎙️",
+		"archive.tar.gz":     "archive.tar",
+		".mp3":               ".mp3",
+		"no-extension":       "no-extension",
+		"dir with space/a.b": "a",
+	}
+	for path, expected := range tests {
+		if got := fileStem(path); got != expected {
+			t.Errorf("fileStem(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+}
+
+func TestFitFileName(t *testing.T) {
+	if got := fitFileName("short", ".txt"); got != "short.txt" {
+		t.Errorf("Expected a short name unchanged, got %q", got)
+	}
+
+	// 100 four-byte emoji and umlauts exceed the limit and must not be cut mid-character
+	for _, stem := range []string{strings.Repeat("🎙", 100), strings.Repeat("ü", 200), strings.Repeat("a", 300)} {
+		got := fitFileName(stem, ".entities.json")
+		if len(got) > maxFileNameBytes {
+			t.Errorf("Expected at most %d bytes, got %d", maxFileNameBytes, len(got))
+		}
+		if !utf8.ValidString(got) || !strings.HasSuffix(got, ".entities.json") {
+			t.Errorf("Expected valid UTF-8 ending in the suffix, got %q", got)
+		}
+	}
+}
+
+func TestFFmpegPath(t *testing.T) {
+	for _, path := range []string{"a:b.mp3", "-y.mp3", "my talk.mp3"} {
+		if got := ffmpegPath(path); got != "file:"+path {
+			t.Errorf("ffmpegPath(%q) = %q", path, got)
+		}
+	}
+}
+
+func TestUploadFileName(t *testing.T) {
+	tests := map[string]string{
+		"talk.mp3":               "talk.mp3",
+		"My Talk (final).M4A":    "My_Talk_final.m4a",
+		"Grüße aus Köln.mp3":     "Grusse_aus_Koln.mp3",
+		"🎙️🎙️.wav":               "audio.wav",
+		`say "hi"; rm -rf.ogg`:   "say_hi_rm_-rf.ogg",
+		"voice.mp3\u200b":        "voice",
+		"talk_converted.mp4":     "talk_converted.mp4",
+		strings.Repeat("x", 300): strings.Repeat("x", maxUploadStemBytes),
+	}
+	for name, expected := range tests {
+		if got := uploadFileName(name); got != expected {
+			t.Errorf("uploadFileName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
+
+func TestOutputNamesFitLongInputs(t *testing.T) {
+	input := filepath.Join("in", strings.Repeat("Ünïcödé ", 40)+".mp3")
+	args := Args{OutputDir: "out", Format: "srt"}
+
+	for _, name := range []string{
+		determineOutputFileName(args, input),
+		sidecarFileName(args, input, ".label-studio.json"),
+		chapterOutputFileName(determineOutputFileName(args, input), 12),
+	} {
+		if base := filepath.Base(name); len(base) > maxFileNameBytes || !utf8.ValidString(base) {
+			t.Errorf("Expected a valid file name of at most %d bytes, got %d bytes", maxFileNameBytes, len(base))
+		}
+		if filepath.Dir(name) != "out" {
+			t.Errorf("Expected the file in the output directory, got %q", name)
+		}
+	}
+}
+
+func TestExpandOutputDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	now := time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]string{
+		"out":                          "out",
+		"~":                            home,
+		"~/transcripts/{year}/{month}": filepath.Join(home, "transcripts", "2026", "03"),
+		"calls/{date}":                 "calls/2026-03-07",
+		"{year}-{month}-{day}":         "2026-03-07",
+		"~other/dir":                   "~other/dir",
+	}
+	for dir, expected := range tests {
+		if got, err := expandOutputDir(dir, now); err != nil || got != expected {
+			t.Errorf("expandOutputDir(%q) = %q, %v, expected %q", dir, got, err, expected)
+		}
+	}
+	if _, err := expandOutputDir("out/{yaer}", now); err == nil {
+		t.Error("Expected an unknown placeholder to be rejected")
+	}
+}
+
+func TestEnsureOutputDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "transcripts", "2026")
+	if err := ensureOutputDir(dir, false); err == nil {
+		t.Error("Expected a missing directory to fail with --no-create-dirs")
+	}
+	if err := ensureOutputDir(dir, true); err != nil {
+		t.Fatalf("ensureOutputDir() failed: %v", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("Expected %s to be created", dir)
+	}
+	if err := ensureOutputDir(dir, false); err != nil {
+		t.Errorf("Expected an existing directory to be accepted, got %v", err)
+	}
+
+	file := filepath.Join(dir, "notes.txt")
+	os.WriteFile(file, nil, 0644)
+	if err := ensureOutputDir(file, true); err == nil {
+		t.Error("Expected a file to be rejected as output directory")
+	}
+}
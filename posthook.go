@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// hookPayload is the JSON a --post-hook command receives on stdin
+type hookPayload struct {
+	// Input is the transcribed file
+	Input string `json:"input"`
+	// Output is the saved transcript, empty when it was printed
+	Output string `json:"output,omitempty"`
+	Format string `json:"format"`
+	Result Result `json:"result"`
+}
+
+// hookCommand returns the command running a hook through the shell, so hooks
+// can pass arguments, use pipes and name scripts relative to the working directory
+func hookCommand(goos, command string) *exec.Cmd {
+	if goos == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// hookEnvironment returns the variables a hook gets in addition to pindar's
+// environment, for scripts that only need the file names
+func hookEnvironment(payload hookPayload) []string {
+	return []string{
+		"PINDAR_INPUT=" + payload.Input,
+		"PINDAR_OUTPUT=" + payload.Output,
+		"PINDAR_FORMAT=" + payload.Format,
+	}
+}
+
+// runPostHook passes the finished transcript to the --post-hook command. Its
+// output appears among pindar's, and a non-zero exit status fails the run.
+func runPostHook(command string, payload hookPayload) error {
+	for _, path := range []*string{&payload.Input, &payload.Output} {
+		if *path != "" {
+			if abs, err := filepath.Abs(*path); err == nil {
+				*path = abs
+			}
+		}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hook payload: %w", err)
+	}
+
+	cmd := hookCommand(runtime.GOOS, command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), hookEnvironment(payload)...)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRunPostHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook command uses sh")
+	}
+	dir := t.TempDir()
+	received := filepath.Join(dir, "payload.json")
+	env := filepath.Join(dir, "env.txt")
+	payload := hookPayload{
+		Input:  filepath.Join(dir, "call.wav"),
+		Output: filepath.Join(dir, "call.srt"),
+		Format: "srt",
+		Result: newResult(&Transcript{Text: "Hello.", Model: "whisper-1", Segments: []Segment{{Start: 0, End: 1, Text: "Hello."}}}, nil),
+	}
+
+	command := "cat > " + received + " && printf %s \"$PINDAR_OUTPUT\" > " + env
+	if err := runPostHook(command, payload); err != nil {
+		t.Fatalf("runPostHook() failed: %v", err)
+	}
+	data, _ := os.ReadFile(received)
+	var got hookPayload
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Expected JSON on stdin, got %q: %v", data, err)
+	}
+	if got.Input != payload.Input || got.Format != "srt" || got.Result.Model != "whisper-1" || got.Result.SchemaVersion != resultSchemaVersion {
+		t.Errorf("Unexpected payload: %s", data)
+	}
+	if output, _ := os.ReadFile(env); string(output) != payload.Output {
+		t.Errorf("Expected PINDAR_OUTPUT %q, got %q", payload.Output, output)
+	}
+
+	if err := runPostHook("exit 3", payload); err == nil {
+		t.Error("Expected a failing hook to return an error")
+	}
+}
+
+func TestHookCommand(t *testing.T) {
+	if cmd := hookCommand("windows", "notify.bat"); cmd.Args[0] != "cmd" || cmd.Args[2] != "notify.bat" {
+		t.Errorf("Expected cmd /C on Windows, got %v", cmd.Args)
+	}
+	if cmd := hookCommand("linux", "./notify.sh --quiet"); cmd.Args[0] != "sh" || cmd.Args[2] != "./notify.sh --quiet" {
+		t.Errorf("Expected sh -c elsewhere, got %v", cmd.Args)
+	}
+}
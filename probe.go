@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// containerAliases maps extensions of containers the API accepts under a different name
+var containerAliases = map[string]string{
+	"oga":  "ogg",
+	"opus": "ogg",
+}
+
+// audioProbe holds the container and stream information reported by ffprobe
+type audioProbe struct {
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+	} `json:"format"`
+	Streams []probeStream `json:"streams"`
+}
+
+// probeStream describes a single stream inside a media container
+type probeStream struct {
+	Index     int    `json:"index"`
+	CodecType string `json:"codec_type"`
+	CodecName string `json:"codec_name"`
+	// SampleRate and Channels are only set for audio streams
+	SampleRate string `json:"sample_rate"`
+	Channels   int    `json:"channels"`
+	Tags       struct {
+		Language string `json:"language"`
+		Title    string `json:"title"`
+	} `json:"tags"`
+}
+
+// describe returns a short human readable description of the stream
+func (s probeStream) describe() string {
+	description := s.CodecName
+	if s.Tags.Language != "" && s.Tags.Language != "und" {
+		description += fmt.Sprintf(" (%s)", s.Tags.Language)
+	}
+	if s.Tags.Title != "" {
+		description += " - " + s.Tags.Title
+	}
+	return description
+}
+
+// probeAudio inspects a media file with ffprobe
+func probeAudio(path string) (*audioProbe, error) {
+	cmd, err := ffprobeCommand(path, "-v", "error",
+		"-show_entries", "format=format_name,duration:stream=index,codec_type,codec_name,sample_rate,channels:stream_tags=language,title",
+		"-of", "json")
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var probe audioProbe
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+	return &probe, nil
+}
+
+// streamsOfType returns all streams of the given codec type (audio, video, ...)
+func (p *audioProbe) streamsOfType(codecType string) []probeStream {
+	var streams []probeStream
+	for _, s := range p.Streams {
+		if s.CodecType == codecType {
+			streams = append(streams, s)
+		}
+	}
+	return streams
+}
+
+// uploadExtension returns the API-accepted extension matching the probed container
+// and codecs, or an empty string if the file has to be converted first
+func (p *audioProbe) uploadExtension() string {
+	audio := p.streamsOfType("audio")
+	if len(audio) != 1 {
+		return ""
+	}
+	codec := audio[0].CodecName
+	hasVideo := len(p.streamsOfType("video")) > 0
+
+	formats := strings.Split(p.Format.FormatName, ",")
+	has := func(name string) bool {
+		for _, f := range formats {
+			if f == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case has("ogg"):
+		if !hasVideo && (codec == "opus" || codec == "vorbis" || codec == "flac") {
+			return "ogg"
+		}
+	case has("webm"):
+		if codec == "opus" || codec == "vorbis" {
+			return "webm"
+		}
+	case has("mp4"):
+		if codec == "aac" || codec == "mp3" || codec == "alac" {
+			if hasVideo {
+				return "mp4"
+			}
+			return "m4a"
+		}
+	case has("wav"):
+		if strings.HasPrefix(codec, "pcm_") {
+			return "wav"
+		}
+	case has("mp3"):
+		if codec == "mp3" {
+			return "mp3"
+		}
+	case has("flac"):
+		if codec == "flac" {
+			return "flac"
+		}
+	}
+	return ""
+}
+
+// resolveUploadName returns the filename to present to the API for the given
+// input, or an empty string if the file needs to be converted before upload
+func resolveUploadName(path string) string {
+	base := filepath.Base(path)
+	ext := getFileExtension(path)
+	nameWithoutExt := strings.TrimSuffix(base, filepath.Ext(base))
+
+	if isFormatSupported(ext) {
+		return base
+	}
+
+	// Probe the actual container and codec. An alias like .opus only says
+	// it's Ogg, whose codec may still be one the API rejects, like Speex.
+	probe, err := probeAudio(path)
+	if err != nil {
+		// Without ffprobe the alias is the best guess
+		if alias, ok := containerAliases[ext]; ok {
+			return nameWithoutExt + "." + alias
+		}
+		return ""
+	}
+	if uploadExt := probe.uploadExtension(); uploadExt != "" {
+		return nameWithoutExt + "." + uploadExt
+	}
+	return ""
+}
+
+// selectAudioTrack determines which audio track (1-based) to transcribe. It returns
+// 0 when the file has a single audio track and no explicit selection is needed.
+func selectAudioTrack(path string, requested int) (int, error) {
+	if requested < 0 {
+		return 0, errors.New(tr("--track must be a positive track number"))
+	}
+
+	probe, err := probeAudio(path)
+	if err != nil {
+		// Without ffprobe we can't list tracks, but ffmpeg can still map the requested one
+		return requested, nil
+	}
+
+	tracks := probe.streamsOfType("audio")
+	if len(tracks) == 0 {
+		return 0, fmt.Errorf(tr("no audio track found in %s"), filepath.Base(path))
+	}
+	if requested > len(tracks) {
+		return 0, fmt.Errorf(tr("track %d requested but %s only has %d audio track(s):\n%s"),
+			requested, filepath.Base(path), len(tracks), formatTrackList(tracks))
+	}
+	if len(tracks) == 1 {
+		return 0, nil
+	}
+	if requested > 0 {
+		return requested, nil
+	}
+
+	// Multiple tracks and no explicit choice: ask if we can, otherwise use the first
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		uiPrintf(tr(" %s has %d audio tracks, using track 1 (select another with --track)\n"), filepath.Base(path), len(tracks))
+		return 1, nil
+	}
+	return promptForTrack(path, tracks)
+}
+
+// formatTrackList renders a numbered list of audio tracks
+func formatTrackList(tracks []probeStream) string {
+	var b strings.Builder
+	for i, track := range tracks {
+		fmt.Fprintf(&b, "   %d: %s\n", i+1, track.describe())
+	}
+	return b.String()
+}
+
+// promptForTrack lets the user pick one of several audio tracks interactively
+func promptForTrack(path string, tracks []probeStream) (int, error) {
+	uiPrintf(tr(" Audio tracks in %s:\n"), filepath.Base(path))
+	uiPrint(formatTrackList(tracks))
+	uiPrintf(tr(" Select track [1-%d] (default 1): "), len(tracks))
+
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return 0, fmt.Errorf("failed to read track selection: %w", err)
+	}
+
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return 1, nil
+	}
+	track, err := strconv.Atoi(input)
+	if err != nil || track < 1 || track > len(tracks) {
+		return 0, fmt.Errorf(tr("invalid track selection %q"), input)
+	}
+	return track, nil
+}
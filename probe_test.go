@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+)
+
+func newTestProbe(formatName string, streams ...probeStream) *audioProbe {
+	probe := &audioProbe{Streams: streams}
+	probe.Format.FormatName = formatName
+	return probe
+}
+
+func TestProbeUploadExtension(t *testing.T) {
+	tests := []struct {
+		name     string
+		probe    *audioProbe
+		expected string
+	}{
+		{
+			name:     "Ogg Opus",
+			probe:    newTestProbe("ogg", probeStream{CodecType: "audio", CodecName: "opus"}),
+			expected: "ogg",
+		},
+		{
+			name:     "Ogg Speex needs conversion",
+			probe:    newTestProbe("ogg", probeStream{CodecType: "audio", CodecName: "speex"}),
+			expected: "",
+		},
+		{
+			name:     "WebM Opus",
+			probe:    newTestProbe("matroska,webm", probeStream{CodecType: "audio", CodecName: "opus"}),
+			expected: "webm",
+		},
+		{
+			name:     "AAC in MP4 container",
+			probe:    newTestProbe("mov,mp4,m4a,3gp,3g2,mj2", probeStream{CodecType: "audio", CodecName: "aac"}),
+			expected: "m4a",
+		},
+		{
+			name: "AAC in MP4 container with video",
+			probe: newTestProbe("mov,mp4,m4a,3gp,3g2,mj2",
+				probeStream{CodecType: "video", CodecName: "h264"},
+				probeStream{CodecType: "audio", CodecName: "aac"}),
+			expected: "mp4",
+		},
+		{
+			name:     "PCM WAV",
+			probe:    newTestProbe("wav", probeStream{CodecType: "audio", CodecName: "pcm_s16le"}),
+			expected: "wav",
+		},
+		{
+			name:     "AIFF needs conversion",
+			probe:    newTestProbe("aiff", probeStream{CodecType: "audio", CodecName: "pcm_s16be"}),
+			expected: "",
+		},
+		{
+			name: "Multiple audio tracks need conversion",
+			probe: newTestProbe("ogg",
+				probeStream{CodecType: "audio", CodecName: "opus"},
+				probeStream{CodecType: "audio", CodecName: "opus"}),
+			expected: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := tc.probe.uploadExtension()
+			if result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestResolveUploadName(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/path/to/audio.mp3", "audio.mp3"},
+		{"/path/to/audio.opus", "audio.ogg"},
+		{"/path/to/audio.OGA", "audio.ogg"},
+		{"/path/to/voice.note.opus", "voice.note.ogg"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			result := resolveUploadName(tc.path)
+			if result != tc.expected {
+				t.Errorf("resolveUploadName(%s) = %s, expected %s", tc.path, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFormatTrackList(t *testing.T) {
+	original := probeStream{CodecType: "audio", CodecName: "aac"}
+	original.Tags.Language = "eng"
+	commentary := probeStream{CodecType: "audio", CodecName: "ac3"}
+	commentary.Tags.Language = "und"
+	commentary.Tags.Title = "Commentary"
+
+	expected := "   1: aac (eng)\n   2: ac3 - Commentary\n"
+	result := formatTrackList([]probeStream{original, commentary})
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestSelectAudioTrackNegative(t *testing.T) {
+	if _, err := selectAudioTrack("/path/to/movie.mkv", -1); err == nil {
+		t.Error("Expected an error for a negative track number")
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math"
+	"regexp"
+	"unicode/utf8"
+)
+
+// maxPromptTokens is the prompt budget of the transcription models. Whisper
+// only conditions on the last 224 tokens, longer prompts are rejected or cut
+// off mid-word.
+const maxPromptTokens = 224
+
+var promptWordPattern = regexp.MustCompile(`\S+`)
+
+// estimateTokens approximates the number of tokens in text. ASCII text averages
+// about four characters per token, other scripts need considerably more.
+func estimateTokens(text string) int {
+	var tokens float64
+	for _, r := range text {
+		switch {
+		case r < utf8.RuneSelf:
+			tokens += 0.25
+		case r < 0x800:
+			tokens += 0.5
+		default:
+			tokens++
+		}
+	}
+	return int(math.Ceil(tokens))
+}
+
+// trimPrompt shortens a prompt to fit maxTokens by dropping whole words from the
+// start, since the end of the prompt is the most relevant context for the model.
+// A last word too long to fit is cut by characters. It reports whether the
+// prompt had to be trimmed.
+func trimPrompt(prompt string, maxTokens int) (string, bool) {
+	if estimateTokens(prompt) <= maxTokens {
+		return prompt, false
+	}
+
+	words := promptWordPattern.FindAllStringIndex(prompt, -1)
+	end := len(prompt)
+	if len(words) > 0 {
+		end = words[len(words)-1][1]
+	}
+
+	start := end
+	for i := len(words) - 1; i >= 0; i-- {
+		if estimateTokens(prompt[words[i][0]:end]) > maxTokens {
+			break
+		}
+		start = words[i][0]
+	}
+	// Without spaces, as in Chinese or Japanese text or a long URL, even the
+	// last word may not fit, so the end of it is kept instead
+	if start == end {
+		for start > 0 {
+			_, size := utf8.DecodeLastRuneInString(prompt[:start])
+			if estimateTokens(prompt[start-size:end]) > maxTokens {
+				break
+			}
+			start -= size
+		}
+	}
+	return prompt[start:end], true
+}
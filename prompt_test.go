@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"hello world", 3},
+		{"größe", 2},
+		{"東京", 2},
+	}
+
+	for _, tc := range tests {
+		if result := estimateTokens(tc.text); result != tc.expected {
+			t.Errorf("estimateTokens(%q) = %d, expected %d", tc.text, result, tc.expected)
+		}
+	}
+}
+
+func TestTrimPromptShort(t *testing.T) {
+	prompt := "Glossary: Kubernetes, etcd, Raft."
+	result, trimmed := trimPrompt(prompt, maxPromptTokens)
+	if trimmed || result != prompt {
+		t.Errorf("Short prompt should be unchanged, got %q (trimmed=%v)", result, trimmed)
+	}
+}
+
+func TestTrimPromptKeepsEndAndWholeWords(t *testing.T) {
+	prompt := strings.Repeat("alpha beta gamma ", 100) + "final words"
+
+	result, trimmed := trimPrompt(prompt, 20)
+	if !trimmed {
+		t.Fatal("Expected the prompt to be trimmed")
+	}
+	if estimateTokens(result) > 20 {
+		t.Errorf("Trimmed prompt still exceeds budget: %d tokens", estimateTokens(result))
+	}
+	if !strings.HasSuffix(result, "final words") {
+		t.Errorf("Trimmed prompt should keep the end, got %q", result)
+	}
+
+	first := strings.Fields(result)[0]
+	if first != "alpha" && first != "beta" && first != "gamma" {
+		t.Errorf("Trimmed prompt should start with a whole word, got %q", first)
+	}
+}
+
+func TestTrimPromptWithoutSpaces(t *testing.T) {
+	prompt := strings.Repeat("東京都", 100) + "渋谷区"
+
+	result, trimmed := trimPrompt(prompt, 20)
+	if !trimmed {
+		t.Fatal("Expected the prompt to be trimmed")
+	}
+	if estimateTokens(result) != 20 || !strings.HasSuffix(result, "渋谷区") || !utf8.ValidString(result) {
+		t.Errorf("Expected the last 20 characters, got %q", result)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// Transcription providers selectable with --provider
+const (
+	providerOpenAI = "openai"
+	providerFake   = "fake"
+)
+
+// fakeTranscriptionResponse is the canned verbose_json answer of the fake
+// provider, with segment and word timestamps so every output format has data
+const fakeTranscriptionResponse = `{
+  "task": "transcribe",
+  "language": "english",
+  "duration": 9.5,
+  "text": "Welcome to pindar. This transcript is a canned response, so no API key is needed. Café, naïve & \"quotes\" -> test.",
+  "segments": [
+    {"id": 0, "seek": 0, "start": 0.0, "end": 2.4, "text": " Welcome to pindar.", "tokens": [50364, 5650], "temperature": 0.0, "avg_logprob": -0.12, "compression_ratio": 1.1, "no_speech_prob": 0.01},
+    {"id": 1, "seek": 240, "start": 2.6, "end": 6.8, "text": " This transcript is a canned response, so no API key is needed.", "tokens": [50494, 639], "temperature": 0.0, "avg_logprob": -0.31, "compression_ratio": 1.2, "no_speech_prob": 0.02},
+    {"id": 2, "seek": 680, "start": 7.1, "end": 9.5, "text": " Café, naïve & \"quotes\" -> test.", "tokens": [50719, 15711], "temperature": 0.0, "avg_logprob": -0.85, "compression_ratio": 1.3, "no_speech_prob": 0.05}
+  ],
+  "words": [
+    {"word": "Welcome", "start": 0.0, "end": 0.6},
+    {"word": "to", "start": 0.6, "end": 0.8},
+    {"word": "pindar", "start": 0.9, "end": 2.4},
+    {"word": "This", "start": 2.6, "end": 2.9},
+    {"word": "transcript", "start": 2.9, "end": 3.5},
+    {"word": "is", "start": 3.5, "end": 3.6},
+    {"word": "a", "start": 3.6, "end": 3.7},
+    {"word": "canned", "start": 3.7, "end": 4.1},
+    {"word": "response", "start": 4.1, "end": 4.8},
+    {"word": "so", "start": 5.0, "end": 5.2},
+    {"word": "no", "start": 5.2, "end": 5.4},
+    {"word": "API", "start": 5.4, "end": 5.8},
+    {"word": "key", "start": 5.8, "end": 6.0},
+    {"word": "is", "start": 6.0, "end": 6.2},
+    {"word": "needed", "start": 6.2, "end": 6.8},
+    {"word": "Café", "start": 7.1, "end": 7.6},
+    {"word": "naïve", "start": 7.7, "end": 8.2},
+    {"word": "quotes", "start": 8.4, "end": 9.0},
+    {"word": "test", "start": 9.1, "end": 9.5}
+  ]
+,
+  "usage": {"type": "duration", "seconds": 10}
+}`
+
+// fakeChatResponse is the canned chat completion of the fake provider; its
+// empty JSON object makes the analysis features find nothing
+const fakeChatResponse = `{
+  "id": "chatcmpl-fake",
+  "object": "chat.completion",
+  "created": 0,
+  "model": "fake",
+  "choices": [{"index": 0, "finish_reason": "stop", "message": {"role": "assistant", "content": "{}"}}]
+}`
+
+// fakeProvider answers API requests with canned responses instead of sending them
+func fakeProvider(req *http.Request, _ option.MiddlewareNext) (*http.Response, error) {
+	body := ""
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/audio/transcriptions"):
+		body = fakeTranscriptionResponse
+	case strings.HasSuffix(req.URL.Path, "/chat/completions"):
+		body = fakeChatResponse
+	default:
+		return nil, fmt.Errorf("fake provider has no response for %s", req.URL.Path)
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// accountOptions set the organization and project the requests are billed
+// to, from the options or else the config file. Without them the API bills
+// the key's default organization and project.
+func accountOptions(org, project string, config *Config) []option.RequestOption {
+	var options []option.RequestOption
+	if org = cmp.Or(org, config.Organization); org != "" {
+		options = append(options, option.WithOrganization(org))
+	}
+	if project = cmp.Or(project, config.Project); project != "" {
+		options = append(options, option.WithProject(project))
+	}
+	return options
+}
+
+// newClient creates the API client for the selected provider. The extra
+// options wrap the provider, so their middleware sees the fake responses too.
+func newClient(provider, apiKey string, extra ...option.RequestOption) (openai.Client, error) {
+	switch provider {
+	case providerOpenAI, "":
+		return openai.NewClient(append(extra, option.WithAPIKey(apiKey))...), nil
+	case providerFake:
+		return openai.NewClient(append(extra, option.WithAPIKey("fake"), option.WithMiddleware(fakeProvider))...), nil
+	default:
+		return openai.Client{}, fmt.Errorf(tr("unknown provider %q, use %s or %s"), provider, providerOpenAI, providerFake)
+	}
+}
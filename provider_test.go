@@ -0,0 +1,203 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexflint/go-arg"
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// goldenFormats are the output formats covered by the golden-file tests
+var goldenFormats = []string{"text", "verbose_json", "srt", "vtt", "ttml", "scc", "csv", "ass", "lrc", "html", "epub", "audacity-labels"}
+
+// speakerGoldenFormats are the formats with golden files for a transcript
+// with speaker labels
+var speakerGoldenFormats = []string{"srt", "vtt", "ttml", "scc", "csv", "html", "audacity-labels"}
+
+func TestFakeProviderTranscription(t *testing.T) {
+	client, err := newClient(providerFake, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	audio := filepath.Join(t.TempDir(), "audio.mp3")
+	if err := os.WriteFile(audio, []byte("not really audio"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	args := Args{Model: "whisper-1", Format: "verbose_json", BestOf: 1}
+	transcript, err := transcribeFile(context.Background(), client, args, audio, "audio.mp3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(transcript.Segments) != 3 || len(transcript.Words) != 19 {
+		t.Errorf("Expected the canned segments and words, got %d segments and %d words", len(transcript.Segments), len(transcript.Words))
+	}
+	if string(transcript.Segments[1].Extensions["seek"]) != "240" || transcript.Extensions["usage"] == nil || len(transcript.Extensions) != 1 {
+		t.Errorf("Expected seek and usage to be kept as extensions, got %v and %v", transcript.Segments[1].Extensions, transcript.Extensions)
+	}
+}
+
+func TestUnknownProvider(t *testing.T) {
+	if _, err := newClient("carrier-pigeon", ""); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+}
+
+func TestAccountOptions(t *testing.T) {
+	var headers http.Header
+	capture := option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		headers = req.Header
+		return next(req)
+	})
+	config := &Config{Organization: "org-config", Project: "proj-config"}
+	client, err := newClient(providerFake, "", append(accountOptions("org-flag", "", config), capture)...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := client.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{Model: "gpt-4o-mini"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if headers.Get("OpenAI-Organization") != "org-flag" || headers.Get("OpenAI-Project") != "proj-config" {
+		t.Errorf("Expected the flag's organization and the config's project, got %v", headers)
+	}
+
+	if options := accountOptions("", "", &Config{}); len(options) != 0 {
+		t.Errorf("Expected no options without organization and project, got %d", len(options))
+	}
+}
+
+func TestAccountFromEnvironment(t *testing.T) {
+	t.Setenv("OPENAI_ORG_ID", "org-env")
+	t.Setenv("OPENAI_PROJECT_ID", "proj-env")
+
+	var args Args
+	var listen ListenArgs
+	var digest DigestArgs
+	for _, dest := range []struct {
+		args         any
+		argv         []string
+		org, project *string
+	}{
+		{&args, []string{"talk.mp3"}, &args.Org, &args.Project},
+		{&listen, nil, &listen.Org, &listen.Project},
+		{&digest, []string{"calls"}, &digest.Org, &digest.Project},
+	} {
+		parser, err := arg.NewParser(arg.Config{}, dest.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := parser.Parse(dest.argv); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if *dest.org != "org-env" || *dest.project != "proj-env" {
+			t.Errorf("Expected the organization and project of the environment for %T, got %q and %q", dest.args, *dest.org, *dest.project)
+		}
+	}
+}
+
+// TestGoldenOutputs renders the fake provider's transcript in every output
+// format. Run "go test -run TestGoldenOutputs -update" after intended changes.
+func TestGoldenOutputs(t *testing.T) {
+	var transcript Transcript
+	if err := json.Unmarshal([]byte(fakeTranscriptionResponse), &transcript); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, format := range goldenFormats {
+		t.Run(format, func(t *testing.T) {
+			var output string
+			var err error
+			if format == "epub" {
+				book := epubChapters("recording", &transcript, nil, nil)
+				if output, err = renderEPUB("recording", transcript.Language, book, time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)); err == nil {
+					output, err = epubListing(output)
+				}
+			} else {
+				output, err = renderTranscript(&transcript, format, MergeOptions{}, htmlPage{Title: "recording.mp3", Audio: "recording.mp3"})
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			compareGolden(t, filepath.Join("testdata", "golden", format+".golden"), output)
+		})
+	}
+}
+
+// TestGoldenOutputsWithSpeakers renders the fake provider's transcript with
+// speaker labels, as --split-call, --tracks and --turns set them, in the
+// formats that show who is speaking
+func TestGoldenOutputsWithSpeakers(t *testing.T) {
+	var transcript Transcript
+	if err := json.Unmarshal([]byte(fakeTranscriptionResponse), &transcript); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := range transcript.Segments {
+		transcript.Segments[i].Speaker = []string{"Host", "Guest & Co"}[i%2]
+	}
+
+	for _, format := range speakerGoldenFormats {
+		t.Run(format, func(t *testing.T) {
+			output, err := renderTranscript(&transcript, format, MergeOptions{}, htmlPage{Title: "recording.mp3", Audio: "recording.mp3"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			compareGolden(t, filepath.Join("testdata", "golden", format+".speakers.golden"), output)
+		})
+	}
+}
+
+// epubListing lists the files of an e-book with their content, which unlike
+// the compressed archive doesn't change with the zip implementation
+func epubListing(book string) (string, error) {
+	r, err := zip.NewReader(strings.NewReader(book), int64(len(book)))
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, file := range r.File {
+		f, err := file.Open()
+		if err != nil {
+			return "", err
+		}
+		content, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "== %s (method %d, %s)\n%s\n", file.Name, file.Method, file.Modified.UTC().Format(time.RFC3339), content)
+	}
+	return b.String(), nil
+}
+
+// compareGolden compares output with a golden file, or rewrites the file
+// with -update
+func compareGolden(t *testing.T, golden, output string) {
+	t.Helper()
+	if *updateGolden {
+		if err := os.WriteFile(golden, []byte(output), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Missing golden file, run with -update: %v", err)
+	}
+	if output != string(expected) {
+		t.Errorf("Output differs from %s:\n%s", golden, output)
+	}
+}
@@ -0,0 +1,642 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// queuePassphraseEnv can hold the passphrase the bundles of a queue are sealed with
+const queuePassphraseEnv = "PINDAR_QUEUE_PASSPHRASE"
+
+// Kinds of bundles. The kind is authenticated with the archive, so a result
+// can't be passed off as a job.
+const (
+	bundleKindJob    = "job"
+	bundleKindResult = "result"
+)
+
+// Extensions of the bundle files in a queue
+const (
+	jobBundleExt    = ".pindarjob"
+	resultBundleExt = ".pindarresult"
+)
+
+// queueResultsDir is the directory of a queue that drain returns the results to
+const queueResultsDir = "results"
+
+// Files of a bundle besides the recording and the transcripts
+const (
+	bundleJobFile    = "job.json"
+	bundleResultFile = "result.json"
+	bundleAudioDir   = "audio"
+)
+
+// maxBundleFileSize guards drain against bundles that aren't pindar's
+const maxBundleFileSize = 4 << 30
+
+// DrainArgs defines the arguments of the drain subcommand
+type DrainArgs struct {
+	Queue     string `arg:"positional,required" help:"Queue directory, e.g. on a USB drive, or an S3 drop as s3://bucket/prefix"`
+	Collect   bool   `arg:"--collect" help:"Decrypt the returned results into --output-dir instead of transcribing the queued jobs"`
+	OutputDir string `arg:"--output-dir,-o" help:"Directory --collect saves the transcripts to (defaults to current directory)"`
+	Summary   string `arg:"--summary" help:"Write a JSON summary of the run to this file (files, minutes of audio, estimated cost, failures)"`
+	APIKey    string `arg:"--api-key" env:"OPENAI_API_KEY" help:"OpenAI API key (can also be set via OPENAI_API_KEY environment variable)"`
+}
+
+// queueJob is what a job bundle holds besides the recording
+type queueJob struct {
+	ID string `json:"id"`
+	// File is the name of the recording, which the transcripts are named after
+	File     string    `json:"file"`
+	Options  []string  `json:"options"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// queueResult is what a result bundle holds besides the transcripts
+type queueResult struct {
+	ID            string    `json:"id"`
+	File          string    `json:"file"`
+	TranscribedAt time.Time `json:"transcribed_at"`
+}
+
+// bundleHeader is the first line of a bundle, followed by the sealed archive
+type bundleHeader struct {
+	Version    int    `json:"version"`
+	Kind       string `json:"kind"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+}
+
+// sealBundle archives the files and seals them with AES-256-GCM using a key
+// derived from passphrase, as the name mapping of --anonymize is sealed
+func sealBundle(kind string, files []stateFile, passphrase string) ([]byte, error) {
+	var archive bytes.Buffer
+	if err := writeStateArchive(&archive, files); err != nil {
+		return nil, fmt.Errorf("failed to archive bundle: %w", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	gcm, err := mappingCipher(passphrase, salt, mappingKDFIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	header, err := json.Marshal(bundleHeader{
+		Version:    1,
+		Kind:       kind,
+		KDF:        "pbkdf2-sha256",
+		Iterations: mappingKDFIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bundle header: %w", err)
+	}
+	return gcm.Seal(append(header, '\n'), nonce, archive.Bytes(), []byte(kind)), nil
+}
+
+// openBundle returns the files of a bundle sealed by sealBundle
+func openBundle(data []byte, kind, passphrase string) ([]stateFile, error) {
+	line, sealed, ok := bytes.Cut(data, []byte("\n"))
+	var header bundleHeader
+	if !ok || json.Unmarshal(line, &header) != nil || header.Kind != kind {
+		return nil, fmt.Errorf(tr("not a pindar %s bundle"), kind)
+	}
+	if header.Version != 1 || header.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("unsupported bundle version %d", header.Version)
+	}
+
+	gcm, err := mappingCipher(passphrase, header.Salt, header.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(header.Nonce) != gcm.NonceSize() {
+		return nil, errors.New("invalid bundle nonce")
+	}
+	archive, err := gcm.Open(nil, header.Nonce, sealed, []byte(kind))
+	if err != nil {
+		return nil, errors.New(tr("wrong passphrase or corrupted bundle"))
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	defer gz.Close()
+	return readArchiveFiles(gz, maxBundleFileSize)
+}
+
+// newJobID names a job after the time it was queued. The name of the
+// recording stays sealed, so a lost drive doesn't give away who was recorded.
+func newJobID(now time.Time) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return now.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix), nil
+}
+
+// queueJobOptions returns the options a queued recording is transcribed with:
+// those of this run without the recording and the options that only apply to
+// this machine. The API key isn't sealed into the bundle.
+func queueJobOptions(argv []string, file string) []string {
+	options := argv
+	for _, name := range []string{"--queue", "--api-key", "--output-dir", "-o", "--ffmpeg-path"} {
+		options = withoutFlag(options, name)
+	}
+	for i := len(options) - 1; i >= 0; i-- {
+		if options[i] != file {
+			continue
+		}
+		start := i
+		if start > 0 && options[start-1] == "--" {
+			start--
+		}
+		return append(options[:start:start], options[i+1:]...)
+	}
+	return options
+}
+
+// drainOptions are the options a queued job may be transcribed with, and
+// whether they take a value. Anyone with the queue passphrase can queue a job,
+// for example from a lost field device, so options that run programs or read
+// files on the draining machine, like --post-hook, --script or --ffmpeg-path,
+// are refused instead of passed on.
+var drainOptions = map[string]bool{
+	"--model":                true,
+	"--language":             true,
+	"--prompt":               true,
+	"--format":               true,
+	"--temperature":          true,
+	"--temperature-fallback": true,
+	"--speakers":             true,
+	"--chapters":             true,
+	"--telephony":            true,
+	"--locale":               true,
+	"--track":                true,
+	"--provider":             true,
+	"--merge-pause":          true,
+	"--merge-max-duration":   true,
+	"--merge-sentences":      false,
+	"--dictation":            false,
+	"--yes":                  false,
+	"-y":                     false,
+}
+
+// checkDrainOptions returns an error unless every option of a job is one of
+// drainOptions
+func checkDrainOptions(options []string) error {
+	inValues := false
+	for i := 0; i < len(options); i++ {
+		if !strings.HasPrefix(options[i], "-") {
+			// Further values of a list like --speakers
+			if inValues {
+				continue
+			}
+			return fmt.Errorf(tr("unexpected argument %q in the job's options"), options[i])
+		}
+		name, _, hasValue := strings.Cut(options[i], "=")
+		takesValue, ok := drainOptions[name]
+		if !ok {
+			return fmt.Errorf(tr("%s can't be queued, drain only runs jobs with %s"), name, strings.Join(slices.Sorted(maps.Keys(drainOptions)), ", "))
+		}
+		inValues = takesValue
+		if takesValue && !hasValue {
+			if i+1 == len(options) {
+				return fmt.Errorf(tr("%s is missing its value in the job's options"), name)
+			}
+			// The value may start with a dash, like a negative number
+			i++
+		}
+	}
+	return nil
+}
+
+// queueStore is where the bundles of a queue are kept. Bundles are named by
+// slash-separated paths relative to the queue.
+type queueStore interface {
+	// list returns the bundles with the extension in a directory of the queue
+	list(dir, ext string) ([]string, error)
+	read(name string) ([]byte, error)
+	write(name string, data []byte) error
+	remove(name string) error
+}
+
+// openQueueStore returns the store of a queue directory, or of an S3 drop
+// for an s3:// URL
+func openQueueStore(location string) queueStore {
+	if strings.HasPrefix(location, "s3://") {
+		return s3Store{url: strings.TrimSuffix(location, "/")}
+	}
+	return dirStore{dir: location}
+}
+
+// dirStore keeps a queue in a directory, e.g. on removable storage
+type dirStore struct {
+	dir string
+}
+
+func (s dirStore) list(dir, ext string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, filepath.FromSlash(dir)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ext) {
+			names = append(names, path.Join(dir, entry.Name()))
+		}
+	}
+	return names, nil
+}
+
+func (s dirStore) read(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(name)))
+}
+
+// write writes the bundle under a temporary name first, so a drive pulled
+// halfway doesn't leave a truncated bundle in the queue
+func (s dirStore) write(name string, data []byte) error {
+	dest := filepath.Join(s.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create queue directory: %w", err)
+	}
+	if err := os.WriteFile(dest+".tmp", data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	return os.Rename(dest+".tmp", dest)
+}
+
+func (s dirStore) remove(name string) error {
+	return os.Remove(filepath.Join(s.dir, filepath.FromSlash(name)))
+}
+
+// s3Store keeps a queue in an S3 bucket, accessed with the AWS CLI and its
+// credentials. The bundles are sealed, so the bucket never sees a recording.
+type s3Store struct {
+	url string
+}
+
+// awsError is a failed aws command with the errors it printed
+type awsError struct {
+	command string
+	err     error
+	output  string
+}
+
+func (e *awsError) Error() string {
+	return fmt.Sprintf("aws s3 %s failed: %v\nOutput: %s", e.command, e.err, e.output)
+}
+
+// aws runs an aws s3 command and returns its output
+func (s s3Store) aws(stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("aws", append([]string{"s3"}, args...)...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, errors.New(tr("the AWS CLI (aws) is needed for an S3 queue, install it and configure its credentials"))
+	}
+	if err != nil {
+		return nil, &awsError{command: args[0], err: err, output: stderr.String()}
+	}
+	return output, nil
+}
+
+func (s s3Store) list(dir, ext string) ([]string, error) {
+	prefix := s.url + "/"
+	if dir != "" {
+		prefix += dir + "/"
+	}
+	output, err := s.aws(nil, "ls", prefix)
+	// aws s3 ls fails without a message for a prefix with no objects
+	var failed *awsError
+	if errors.As(err, &failed) && strings.TrimSpace(failed.output) == "" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, line := range strings.Split(string(output), "\n") {
+		// Objects are listed as: date time size name
+		if fields := strings.Fields(line); len(fields) == 4 && strings.HasSuffix(fields[3], ext) {
+			names = append(names, path.Join(dir, fields[3]))
+		}
+	}
+	return names, nil
+}
+
+func (s s3Store) read(name string) ([]byte, error) {
+	return s.aws(nil, "cp", "--only-show-errors", s.url+"/"+name, "-")
+}
+
+func (s s3Store) write(name string, data []byte) error {
+	_, err := s.aws(data, "cp", "--only-show-errors", "-", s.url+"/"+name)
+	return err
+}
+
+func (s s3Store) remove(name string) error {
+	_, err := s.aws(nil, "rm", "--only-show-errors", s.url+"/"+name)
+	return err
+}
+
+// enqueue seals the recording, its options file and the options of this run
+// into a job bundle in the queue, for pindar drain to transcribe on a
+// connected machine. Nothing is uploaded, so no API key is needed.
+func enqueue(args Args, argv []string, fileOptionsPath string) error {
+	passphrase, err := askPassphrase(queuePassphraseEnv, tr("Passphrase for the queue: "), true)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	id, err := newJobID(now)
+	if err != nil {
+		return err
+	}
+	job := queueJob{ID: id, File: filepath.Base(args.File), Options: queueJobOptions(argv, args.File), QueuedAt: now.UTC()}
+	if err := checkDrainOptions(job.Options); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+	files := []stateFile{{Name: bundleJobFile, Content: data, ModTime: now}}
+
+	sources := []string{args.File}
+	if fileOptionsPath != "" {
+		sources = append(sources, fileOptionsPath)
+	}
+	for _, source := range sources {
+		info, err := os.Stat(source)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		content, err := os.ReadFile(source)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		files = append(files, stateFile{Name: path.Join(bundleAudioDir, filepath.Base(source)), Content: content, ModTime: info.ModTime()})
+	}
+
+	sealed, err := sealBundle(bundleKindJob, files, passphrase)
+	if err != nil {
+		return err
+	}
+	name := id + jobBundleExt
+	if err := openQueueStore(args.Queue).write(name, sealed); err != nil {
+		return err
+	}
+	uiPrintf(tr("🔒 Queued %s as %s in %s\n"), args.File, name, args.Queue)
+	return nil
+}
+
+// bundleJSON finds a JSON file of a bundle, decodes it into v, and returns
+// the other files
+func bundleJSON(files []stateFile, name string, v any) ([]stateFile, error) {
+	var rest []stateFile
+	found := false
+	for _, file := range files {
+		if file.Name != name {
+			rest = append(rest, file)
+			continue
+		}
+		if err := json.Unmarshal(file.Content, v); err != nil {
+			return nil, fmt.Errorf("failed to parse %s of bundle: %w", name, err)
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("bundle has no %s", name)
+	}
+	return rest, nil
+}
+
+// unpackJob opens a job bundle and writes the recording into dir
+func unpackJob(store queueStore, name, passphrase, dir string) (queueJob, error) {
+	var job queueJob
+	data, err := store.read(name)
+	if err != nil {
+		return job, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	files, err := openBundle(data, bundleKindJob, passphrase)
+	if err != nil {
+		return job, err
+	}
+	if files, err = bundleJSON(files, bundleJobFile, &job); err != nil {
+		return job, err
+	}
+	if job.File != filepath.Base(job.File) || !filepath.IsLocal(job.File) {
+		return job, fmt.Errorf("invalid recording name %q in bundle", job.File)
+	}
+	if err := checkDrainOptions(job.Options); err != nil {
+		return job, err
+	}
+	return job, restoreState(dir, files)
+}
+
+// returnResult seals the transcripts of a job into a result bundle in the
+// queue and removes the job
+func returnResult(store queueStore, name string, job queueJob, outputDir, passphrase string) error {
+	files, err := collectState(outputDir)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(queueResult{ID: job.ID, File: job.File, TranscribedAt: time.Now().UTC()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	files = append([]stateFile{{Name: bundleResultFile, Content: data, ModTime: time.Now()}}, files...)
+
+	sealed, err := sealBundle(bundleKindResult, files, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := store.write(path.Join(queueResultsDir, job.ID+resultBundleExt), sealed); err != nil {
+		return err
+	}
+	return store.remove(name)
+}
+
+// checkCollectable refuses results whose transcripts would overwrite files in
+// dir. Recorders name their files like ZOOM0001.WAV day after day, so the
+// transcripts of different recordings can share names.
+func checkCollectable(dir string, files []stateFile) error {
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file.Name))
+		if _, err := os.Lstat(path); err == nil {
+			return fmt.Errorf(tr("%s already exists, collect into another --output-dir"), path)
+		}
+	}
+	return nil
+}
+
+// collectResults saves the transcripts of the results returned to the queue
+// into dir and removes their bundles
+func collectResults(store queueStore, passphrase, dir string) {
+	names, err := store.list(queueResultsDir, resultBundleExt)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading queue: %v\n"), err)
+		os.Exit(1)
+	}
+	if len(names) == 0 {
+		uiPrintln(tr("No results returned yet."))
+		return
+	}
+
+	failed := 0
+	for _, name := range names {
+		data, err := store.read(name)
+		var files []stateFile
+		if err == nil {
+			files, err = openBundle(data, bundleKindResult, passphrase)
+		}
+		var result queueResult
+		if err == nil {
+			files, err = bundleJSON(files, bundleResultFile, &result)
+		}
+		if err == nil {
+			err = checkCollectable(dir, files)
+		}
+		if err == nil {
+			err = restoreState(dir, files)
+		}
+		if err == nil {
+			err = store.remove(name)
+		}
+		if err != nil {
+			uiPrintf("❌ %s: %v\n", name, err)
+			failed++
+			continue
+		}
+		uiPrintf(tr("✅ %s: saved %d files to %s\n"), result.File, len(files), dir)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// runDrain transcribes the jobs queued on a field device with a separate
+// pindar process each, like a --manifest run, and returns the transcripts to
+// the queue sealed with the same passphrase. With --collect it saves the
+// returned transcripts instead.
+func runDrain(argv []string) {
+	var args DrainArgs
+	parser := parseSubcommand("drain", &args, argv)
+	if args.Summary != "" && args.Collect {
+		parser.Fail(tr("--summary only applies to draining jobs, not to --collect"))
+	}
+	store := openQueueStore(args.Queue)
+	passphrase, err := askPassphrase(queuePassphraseEnv, tr("Passphrase for the queue: "), false)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading passphrase: %v\n"), err)
+		os.Exit(1)
+	}
+
+	if args.Collect {
+		dir := cmp.Or(args.OutputDir, ".")
+		if dir, err = expandOutputDir(dir, time.Now()); err == nil {
+			err = ensureOutputDir(dir, true)
+		}
+		if err != nil {
+			uiPrintf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		collectResults(store, passphrase, dir)
+		return
+	}
+
+	names, err := store.list("", jobBundleExt)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading queue: %v\n"), err)
+		os.Exit(1)
+	}
+	if len(names) == 0 {
+		uiPrintf(tr("No jobs queued in %s.\n"), args.Queue)
+		return
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		uiPrintf(tr("❌ Error draining queue: %v\n"), err)
+		os.Exit(1)
+	}
+	tmpDir, err := os.MkdirTemp("", "pindar_drain")
+	if err != nil {
+		uiPrintf(tr("❌ Error draining queue: %v\n"), err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Ask for the API key once instead of in every process; jobs queued
+	// with the fake provider don't need one
+	env := os.Environ()
+	if apiKey, err := getAPIKey(args.APIKey); err == nil {
+		env = append(env, "OPENAI_API_KEY="+apiKey)
+	}
+
+	var summary batchSummary
+	for i, name := range names {
+		uiPrintf("\n[%d/%d] %s\n", i+1, len(names), name)
+
+		jobDir := filepath.Join(tmpDir, fmt.Sprintf("job_%04d", i))
+		job, err := unpackJob(store, name, passphrase, jobDir)
+		if err != nil {
+			uiPrintf("❌ %v\n", err)
+			summary.addFailure(name, err.Error())
+			continue
+		}
+
+		outputDir := filepath.Join(jobDir, "output")
+		input := filepath.Join(jobDir, bundleAudioDir, job.File)
+		cmd := exec.Command(executable, append(job.Options, "--output-dir", outputDir, "--", input)...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		cmd.Env = env
+		report, elapsed, err := runBatchJob(cmd, tmpDir)
+		if err == nil {
+			if err = returnResult(store, name, job, outputDir, passphrase); err != nil {
+				uiPrintf("❌ %v\n", err)
+			}
+		}
+		os.RemoveAll(jobDir)
+		if err != nil {
+			summary.addFailure(job.File, err.Error())
+			continue
+		}
+		summary.addTranscribed(report, elapsed)
+	}
+
+	finishBatch(&summary, args.Summary)
+	uiPrintf(tr("\n✅ Transcribed all %d queued jobs, the results are sealed in %s\n"), len(names), strings.TrimSuffix(args.Queue, "/")+"/"+queueResultsDir)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	files := []stateFile{
+		{Name: bundleJobFile, Content: []byte(`{"id":"1"}`)},
+		{Name: "audio/interview.m4a", Content: []byte("audio")},
+	}
+	sealed, err := sealBundle(bundleKindJob, files, "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(string(sealed), "interview") {
+		t.Error("Expected the name of the recording to be sealed")
+	}
+
+	opened, err := openBundle(sealed, bundleKindJob, "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(opened) != 2 || opened[1].Name != "audio/interview.m4a" || string(opened[1].Content) != "audio" {
+		t.Errorf("Unexpected files %+v", opened)
+	}
+
+	if _, err := openBundle(sealed, bundleKindJob, "wrong horse"); err == nil {
+		t.Error("Expected an error for a wrong passphrase")
+	}
+	if _, err := openBundle(sealed, bundleKindResult, "correct horse"); err == nil {
+		t.Error("Expected an error for a job opened as a result")
+	}
+}
+
+func TestNewJobID(t *testing.T) {
+	id, err := newJobID(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(id, "20240501T103000Z-") || len(id) != len("20240501T103000Z-")+8 {
+		t.Errorf("Unexpected job ID %q", id)
+	}
+}
+
+func TestQueueJobOptions(t *testing.T) {
+	tests := []struct {
+		argv []string
+		want []string
+	}{
+		{
+			argv: []string{"--queue", "/media/usb", "--language", "de", "interview.m4a"},
+			want: []string{"--language", "de"},
+		},
+		{
+			argv: []string{"--api-key=sk-test", "-o", "out", "--queue=s3://drop", "--", "interview.m4a", "--format", "srt"},
+			want: []string{"--format", "srt"},
+		},
+	}
+	for _, test := range tests {
+		if got := queueJobOptions(test.argv, "interview.m4a"); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("queueJobOptions(%q) = %q, want %q", test.argv, got, test.want)
+		}
+	}
+}
+
+func TestDirStore(t *testing.T) {
+	dir := t.TempDir()
+	store := openQueueStore(dir)
+	if names, err := store.list(queueResultsDir, resultBundleExt); err != nil || len(names) != 0 {
+		t.Fatalf("Expected no results in an empty queue, got %v, %v", names, err)
+	}
+
+	if err := store.write("results/a"+resultBundleExt, []byte("sealed")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "results", "notes.txt"), []byte("not a bundle"), 0644)
+
+	names, err := store.list(queueResultsDir, resultBundleExt)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"results/a" + resultBundleExt}) {
+		t.Fatalf("Unexpected bundles %q", names)
+	}
+	if data, err := store.read(names[0]); err != nil || string(data) != "sealed" {
+		t.Errorf("Unexpected content %q, %v", data, err)
+	}
+	if err := store.remove(names[0]); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if names, _ := store.list(queueResultsDir, resultBundleExt); len(names) != 0 {
+		t.Errorf("Expected the bundle to be removed, got %q", names)
+	}
+}
+
+func TestDrainJobRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store := openQueueStore(filepath.Join(dir, "queue"))
+	job := queueJob{ID: "20240501T103000Z-00000000", File: "interview.m4a", Options: []string{"--language", "de"}}
+	jobData := []byte(`{"id":"20240501T103000Z-00000000","file":"interview.m4a","options":["--language","de"]}`)
+	sealed, err := sealBundle(bundleKindJob, []stateFile{
+		{Name: bundleJobFile, Content: jobData},
+		{Name: "audio/interview.m4a", Content: []byte("audio")},
+	}, "correct horse")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	name := job.ID + jobBundleExt
+	store.write(name, sealed)
+
+	jobDir := filepath.Join(dir, "job")
+	unpacked, err := unpackJob(store, name, "correct horse", jobDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(unpacked.Options, job.Options) || unpacked.File != job.File {
+		t.Errorf("Unexpected job %+v", unpacked)
+	}
+	if audio, err := os.ReadFile(filepath.Join(jobDir, bundleAudioDir, "interview.m4a")); err != nil || string(audio) != "audio" {
+		t.Errorf("Unexpected recording %q, %v", audio, err)
+	}
+
+	outputDir := filepath.Join(jobDir, "output")
+	os.MkdirAll(outputDir, 0755)
+	os.WriteFile(filepath.Join(outputDir, "interview.txt"), []byte("Hallo"), 0644)
+	if err := returnResult(store, name, unpacked, outputDir, "correct horse"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if names, _ := store.list("", jobBundleExt); len(names) != 0 {
+		t.Errorf("Expected the job to be removed, got %q", names)
+	}
+
+	collectDir := filepath.Join(dir, "collected")
+	collectResults(store, "correct horse", collectDir)
+	if transcript, err := os.ReadFile(filepath.Join(collectDir, "interview.txt")); err != nil || string(transcript) != "Hallo" {
+		t.Errorf("Unexpected transcript %q, %v", transcript, err)
+	}
+	if names, _ := store.list(queueResultsDir, resultBundleExt); len(names) != 0 {
+		t.Errorf("Expected the result to be removed, got %q", names)
+	}
+}
+
+func TestCheckDrainOptions(t *testing.T) {
+	allowed := [][]string{
+		nil,
+		{"--language", "de", "--format=srt", "--dictation"},
+		{"--temperature", "-0.5", "-y"},
+		{"--speakers", "Anna", "Bob", "--model", "whisper-1"},
+	}
+	for _, options := range allowed {
+		if err := checkDrainOptions(options); err != nil {
+			t.Errorf("checkDrainOptions(%q) = %v, want no error", options, err)
+		}
+	}
+
+	refused := [][]string{
+		{"--post-hook", "rm -rf ~"},
+		{"--language", "de", "--script=transform.lua"},
+		{"--ffmpeg-path", "/tmp/x"},
+		{"--dictation", "stray"},
+		{"--", "other.m4a"},
+		{"--prompt"},
+	}
+	for _, options := range refused {
+		if err := checkDrainOptions(options); err == nil {
+			t.Errorf("checkDrainOptions(%q) = nil, want an error", options)
+		}
+	}
+}
+
+func TestCheckCollectable(t *testing.T) {
+	dir := t.TempDir()
+	files := []stateFile{{Name: "ZOOM0001.txt", Content: []byte("Hallo")}}
+	if err := checkCollectable(dir, files); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "ZOOM0001.txt"), []byte("yesterday"), 0644)
+	if err := checkCollectable(dir, files); err == nil {
+		t.Error("Expected an error for a transcript that already exists")
+	}
+}
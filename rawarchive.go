@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/openai/openai-go/option"
+)
+
+// rawArchiveSuffix is the suffix of the file --keep-raw saves next to the transcript
+const rawArchiveSuffix = ".raw.json.zst"
+
+// rawArchive is what --keep-raw saves: the transcript pindar made and the
+// provider's responses it was made from, so new output formats can be
+// rendered later without transcribing the audio again
+type rawArchive struct {
+	Result Result `json:"result"`
+	// Responses are the bodies of the transcription responses in the order
+	// they arrived, including best-of runs and refined segments
+	Responses []json.RawMessage `json:"responses"`
+}
+
+// rawRecorder keeps the bodies of the transcription responses passing through it
+type rawRecorder struct {
+	mu        sync.Mutex
+	responses []json.RawMessage
+}
+
+// middleware sends the request and keeps the body of a successful
+// transcription response; chat requests of the analysis features are skipped
+func (r *rawRecorder) middleware(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	res, err := next(req)
+	if err != nil || res.StatusCode != http.StatusOK || !strings.HasSuffix(req.URL.Path, "/audio/transcriptions") {
+		return res, err
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response for --keep-raw: %w", err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	if json.Valid(body) {
+		r.mu.Lock()
+		r.responses = append(r.responses, json.RawMessage(body))
+		r.mu.Unlock()
+	}
+	return res, nil
+}
+
+// writeRawArchive saves an archive as zstd-compressed JSON
+func writeRawArchive(path string, archive rawArchive) error {
+	data, err := json.Marshal(archive)
+	if err != nil {
+		return fmt.Errorf("failed to marshal raw responses: %w", err)
+	}
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		return fmt.Errorf("failed to compress raw responses: %w", err)
+	}
+	defer encoder.Close()
+	if err := os.WriteFile(path, encoder.EncodeAll(data, nil), 0644); err != nil {
+		return fmt.Errorf("failed to write raw responses: %w", err)
+	}
+	return nil
+}
+
+// readRawArchive reads an archive written by --keep-raw, rejecting a result
+// of a newer schema version like parseResult
+func readRawArchive(path string) (*rawArchive, error) {
+	compressed, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+	defer decoder.Close()
+	data, err := decoder.DecodeAll(compressed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+
+	var archive rawArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if archive.Result.SchemaVersion > resultSchemaVersion {
+		return nil, fmt.Errorf(tr("the transcript has schema version %d, but this pindar only reads up to version %d; update pindar"), archive.Result.SchemaVersion, resultSchemaVersion)
+	}
+	return &archive, nil
+}
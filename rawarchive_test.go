@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+)
+
+func TestRawRecorderKeepsTranscriptionResponses(t *testing.T) {
+	raw := &rawRecorder{}
+	client, err := newClient(providerFake, "", option.WithMiddleware(raw.middleware))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	audio := filepath.Join(t.TempDir(), "audio.mp3")
+	if err := os.WriteFile(audio, []byte("not really audio"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	args := Args{Model: "whisper-1", Format: "verbose_json", BestOf: 1}
+	transcript, err := transcribeFile(context.Background(), client, args, audio, "audio.mp3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := chatJSON(context.Background(), client, "gpt-4o-mini", "", transcript.Text, &struct{}{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(raw.responses) != 1 || string(raw.responses[0]) != fakeTranscriptionResponse {
+		t.Errorf("Expected only the transcription response to be kept, got %d responses", len(raw.responses))
+	}
+	if len(transcript.Segments) != 3 {
+		t.Errorf("Expected the response to still reach the client, got %d segments", len(transcript.Segments))
+	}
+}
+
+func TestRawArchiveRoundTrip(t *testing.T) {
+	var transcript Transcript
+	if err := json.Unmarshal([]byte(fakeTranscriptionResponse), &transcript); err != nil {
+		t.Fatal(err)
+	}
+	archive := rawArchive{
+		Result:    newResult(&transcript, transcript.Segments),
+		Responses: []json.RawMessage{json.RawMessage(fakeTranscriptionResponse)},
+	}
+
+	path := filepath.Join(t.TempDir(), "talk"+rawArchiveSuffix)
+	if err := writeRawArchive(path, archive); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	read, err := readRawArchive(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(read.Result, archive.Result) {
+		t.Errorf("Expected the result to survive the round trip, got %+v", read.Result)
+	}
+	if len(read.Responses) != 1 || !json.Valid(read.Responses[0]) {
+		t.Errorf("Expected the response to survive the round trip, got %s", read.Responses)
+	}
+}
+
+func TestReadRawArchiveRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "future"+rawArchiveSuffix)
+	if err := writeRawArchive(path, rawArchive{Result: Result{SchemaVersion: resultSchemaVersion + 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readRawArchive(path); err == nil {
+		t.Error("Expected an error for a newer schema version")
+	}
+	if err := os.WriteFile(path, []byte("not zstd"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readRawArchive(path); err == nil {
+		t.Error("Expected an error for a file that isn't zstd")
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// refinePadding is added around low-confidence segments when cutting them out,
+// so words at the segment edges aren't clipped
+const refinePadding = 0.25
+
+// lowConfidenceSegments returns the indexes of segments whose avg_logprob is below threshold
+func lowConfidenceSegments(segments []Segment, threshold float64) []int {
+	var indexes []int
+	for i, s := range segments {
+		if s.AvgLogprob < threshold {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// segmentsText joins segment texts into the transcript's full text. Segments
+// labeled with a speaker are written as alternating turns.
+func segmentsText(segments []Segment) string {
+	var b strings.Builder
+	speaker := ""
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
+			continue
+		}
+		switch {
+		case segment.Speaker != speaker && b.Len() > 0:
+			fmt.Fprintf(&b, "\n\n%s: %s", segment.Speaker, text)
+		case segment.Speaker != speaker:
+			fmt.Fprintf(&b, "%s: %s", segment.Speaker, text)
+		case b.Len() > 0:
+			b.WriteString(" " + text)
+		default:
+			b.WriteString(text)
+		}
+		speaker = segment.Speaker
+	}
+	return b.String()
+}
+
+// refinePrompt builds the prompt for re-transcribing segment i: the user's
+// refinement prompt followed by the preceding segment as context
+func refinePrompt(base string, segments []Segment, i int) string {
+	prompt := strings.TrimSpace(base)
+	if i > 0 {
+		prompt = strings.TrimSpace(prompt + " " + strings.TrimSpace(segments[i-1].Text))
+	}
+	prompt, _ = trimPrompt(prompt, maxPromptTokens)
+	return prompt
+}
+
+// refineRequestArgs returns the arguments segments are re-transcribed with:
+// only the options of the request itself. Anything asking for segments, like
+// --entities, would request verbose_json, which the gpt-4o refinement models
+// reject.
+func refineRequestArgs(args Args) Args {
+	return Args{
+		Model:       args.RefineModel,
+		Language:    args.Language,
+		Format:      "text",
+		Temperature: args.Temperature,
+		BestOf:      1,
+	}
+}
+
+// refineTranscript re-transcribes segments below the --refine-below confidence
+// threshold with the refinement model and splices the new text back in
+func refineTranscript(ctx context.Context, client openai.Client, args Args, path string, transcript *Transcript) error {
+	indexes := lowConfidenceSegments(transcript.Segments, *args.RefineBelow)
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	uiPrintf(tr(" Refining %d of %d low-confidence segments with %s...\n"), len(indexes), len(transcript.Segments), args.RefineModel)
+
+	tmpDir, err := os.MkdirTemp("", "pindar_refine")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	refineArgs := refineRequestArgs(args)
+
+	for _, i := range indexes {
+		segment := transcript.Segments[i]
+		slicePath := filepath.Join(tmpDir, fmt.Sprintf("segment_%04d.mp4", segment.ID))
+		start := max(0, segment.Start-refinePadding)
+		end := segment.End + refinePadding
+		if err := extractAudioSlice(ctx, path, slicePath, start, end, 0); err != nil {
+			return fmt.Errorf("segment %d: %w", segment.ID, err)
+		}
+
+		refineArgs.Prompt = refinePrompt(args.RefinePrompt, transcript.Segments, i)
+		refined, err := transcribeFile(ctx, client, refineArgs, slicePath, filepath.Base(slicePath))
+		os.Remove(slicePath)
+		if err != nil {
+			return fmt.Errorf("segment %d: %w", segment.ID, err)
+		}
+
+		if text := strings.TrimSpace(refined.Text); text != "" {
+			setSegmentText(&transcript.Segments[i], " "+text)
+		}
+	}
+
+	transcript.Text = segmentsText(transcript.Segments)
+	// The words of refined segments are rebuilt from their new text
+	syncWordsWithText(transcript)
+	return nil
+}
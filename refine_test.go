@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLowConfidenceSegments(t *testing.T) {
+	segments := []Segment{
+		{ID: 0, AvgLogprob: -0.2},
+		{ID: 1, AvgLogprob: -0.9},
+		{ID: 2, AvgLogprob: -0.5},
+		{ID: 3, AvgLogprob: -1.4},
+	}
+
+	result := lowConfidenceSegments(segments, -0.7)
+	expected := []int{1, 3}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestSegmentsText(t *testing.T) {
+	segments := []Segment{
+		{Text: " The quick brown fox"},
+		{Text: "  "},
+		{Text: " jumps over the lazy dog."},
+	}
+
+	expected := "The quick brown fox jumps over the lazy dog."
+	if result := segmentsText(segments); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestRefinePrompt(t *testing.T) {
+	segments := []Segment{
+		{Text: " We deployed the new etcd cluster."},
+		{Text: " Then the Raft leader election failed."},
+	}
+
+	if result := refinePrompt("Glossary: etcd, Raft.", segments, 0); result != "Glossary: etcd, Raft." {
+		t.Errorf("First segment should only use the base prompt, got %q", result)
+	}
+
+	result := refinePrompt("Glossary: etcd, Raft.", segments, 1)
+	if !strings.HasPrefix(result, "Glossary: etcd, Raft.") || !strings.HasSuffix(result, "We deployed the new etcd cluster.") {
+		t.Errorf("Expected base prompt followed by the previous segment, got %q", result)
+	}
+}
+
+func TestRefineRequestArgs(t *testing.T) {
+	threshold := -0.7
+	args := Args{
+		Model: "whisper-1", RefineModel: "gpt-4o-transcribe", RefineBelow: &threshold, Language: "de",
+		Format: "srt", Temperature: 0.2, Entities: true, Anki: true, Interview: true, BestOf: 3,
+	}
+	refine := refineRequestArgs(args)
+	if refine.wantsSegments() {
+		t.Error("Expected the refinement request not to ask for segments")
+	}
+	if refine.Model != "gpt-4o-transcribe" || refine.Language != "de" || refine.Temperature != 0.2 || refine.BestOf != 1 {
+		t.Errorf("Unexpected refinement arguments %+v", refine)
+	}
+}
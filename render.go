@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RenderArgs are the arguments of the render subcommand
+type RenderArgs struct {
+	File             string  `arg:"positional,required" help:"JSON result written with --format verbose_json, or a .raw.json.zst file written by --keep-raw"`
+	Format           string  `arg:"--format" default:"text" help:"Output formats separated by commas, e.g. srt,html (text, srt, vtt, ttml, scc, verbose_json, csv, ass, lrc, html, epub, or audacity-labels)"`
+	OutputDir        string  `arg:"--output-dir,-o" help:"Directory to save the outputs (defaults to the current directory)"`
+	OutputName       string  `arg:"--output-name" help:"Name of the output files without extension (defaults to the name of the input)"`
+	AudioURL         string  `arg:"--audio-url" help:"URL or path of the audio file html output plays, relative to the page"`
+	MergePause       float64 `arg:"--merge-pause" help:"Merge segments separated by pauses shorter than this many seconds"`
+	MergeSentences   bool    `arg:"--merge-sentences" help:"Merge segments until a sentence boundary is reached"`
+	MergeMaxDuration float64 `arg:"--merge-max-duration" help:"Maximum length in seconds of a merged segment"`
+}
+
+// outputFormats are the formats pindar renders transcripts in
+var outputFormats = []string{"text", "srt", "vtt", "ttml", "scc", "verbose_json", "csv", "ass", "lrc", "html", "epub", "audacity-labels"}
+
+// parseFormats splits a comma-separated list of output formats, rejecting
+// unknown formats and those the transcript lacks the timestamps for
+func parseFormats(list string, transcript *Transcript) ([]string, error) {
+	var formats []string
+	for _, format := range strings.Split(list, ",") {
+		format = strings.ToLower(strings.TrimSpace(format))
+		switch {
+		case format == "" || slices.Contains(formats, format):
+			continue
+		case !slices.Contains(outputFormats, format):
+			return nil, fmt.Errorf(tr("unknown format %q, use one of: %s"), format, strings.Join(outputFormats, ", "))
+		case needsWords(format) && len(transcript.Words) == 0:
+			return nil, fmt.Errorf(tr("%s output needs word timestamps, which the transcript doesn't have"), format)
+		case needsSegments(format) && len(transcript.Segments) == 0:
+			return nil, fmt.Errorf(tr("%s output needs timestamps, which the transcript doesn't have"), format)
+		}
+		formats = append(formats, format)
+	}
+	if len(formats) == 0 {
+		return nil, errors.New(tr("no output format given"))
+	}
+	return formats, nil
+}
+
+// loadRenderInput reads a JSON result or a --keep-raw archive
+func loadRenderInput(path string) (*Result, error) {
+	if strings.HasSuffix(path, rawArchiveSuffix) {
+		archive, err := readRawArchive(path)
+		if err != nil {
+			return nil, err
+		}
+		return &archive.Result, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseResult(data)
+}
+
+// transcriptFromResult turns a JSON result back into the transcript it was made from
+func transcriptFromResult(result *Result) *Transcript {
+	return &Transcript{
+		Task:       result.Task,
+		Language:   result.Language,
+		Duration:   result.Duration,
+		Text:       result.Text,
+		Segments:   result.Segments,
+		Words:      result.Words,
+		Logprobs:   result.Logprobs,
+		Provider:   result.Provider,
+		Model:      result.Model,
+		Extensions: result.Extensions,
+	}
+}
+
+// renderStem returns the name of a rendered input without its extensions
+func renderStem(path string) string {
+	if base := filepath.Base(path); strings.HasSuffix(base, rawArchiveSuffix) {
+		return strings.TrimSuffix(base, rawArchiveSuffix)
+	}
+	return fileStem(path)
+}
+
+// runRender renders a saved transcript in other output formats without transcribing it again
+func runRender(argv []string) {
+	var args RenderArgs
+	parser := parseSubcommand("render", &args, argv)
+
+	result, err := loadRenderInput(args.File)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading transcript: %v\n"), err)
+		os.Exit(1)
+	}
+	transcript := transcriptFromResult(result)
+	formats, err := parseFormats(args.Format, transcript)
+	if err != nil {
+		parser.Fail(err.Error())
+	}
+
+	stem := renderStem(args.File)
+	if args.OutputName != "" {
+		stem = args.OutputName
+	}
+	if args.OutputDir != "" {
+		if err := ensureOutputDir(args.OutputDir, true); err != nil {
+			uiPrintf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+	merge := MergeOptions{MaxPause: args.MergePause, Sentences: args.MergeSentences, MaxDuration: args.MergeMaxDuration}
+	input, _ := filepath.Abs(args.File)
+
+	for _, format := range formats {
+		outputFile := determineOutputFileName(Args{Format: format, OutputDir: args.OutputDir, OutputName: stem}, args.File)
+		if output, _ := filepath.Abs(outputFile); output == input {
+			uiPrintf(tr("❌ Not rendering %s, it would overwrite %s; pass --output-dir or --output-name\n"), format, args.File)
+			os.Exit(1)
+		}
+
+		var text string
+		if format == "epub" {
+			text, err = renderEPUB(stem, transcript.Language, epubChapters(stem, transcript, nil, nil), time.Now())
+		} else {
+			page := htmlPage{Title: stem, Audio: template.URL(args.AudioURL)}
+			text, err = renderTranscript(transcript, format, merge, page)
+		}
+		if err != nil {
+			uiPrintf(tr("❌ Error rendering transcription: %v\n"), err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(outputFile, []byte(text), 0644); err != nil {
+			uiPrintf(tr("❌ Error writing output file: %v\n"), err)
+			os.Exit(1)
+		}
+		uiPrintf(tr("💾 Transcription saved to: %s\n"), outputFile)
+	}
+}
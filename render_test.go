@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestParseFormats(t *testing.T) {
+	timed := &Transcript{Segments: []Segment{{Text: "Hi"}}, Words: []Word{{Word: "Hi"}}}
+	formats, err := parseFormats(" SRT,html,,srt ,text", timed)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{"srt", "html", "text"}; !slices.Equal(formats, expected) {
+		t.Errorf("Expected %v, got %v", expected, formats)
+	}
+
+	untimed := &Transcript{Text: "Hi"}
+	tests := map[string]*Transcript{
+		"docx":     timed,
+		"":         timed,
+		"text,srt": untimed,
+		"html":     {Segments: timed.Segments},
+	}
+	for list, transcript := range tests {
+		if _, err := parseFormats(list, transcript); err == nil {
+			t.Errorf("%q: expected an error", list)
+		}
+	}
+}
+
+func TestLoadRenderInput(t *testing.T) {
+	var transcript Transcript
+	if err := json.Unmarshal([]byte(fakeTranscriptionResponse), &transcript); err != nil {
+		t.Fatal(err)
+	}
+	transcript.Provider, transcript.Model = providerFake, "whisper-1"
+	result := newResult(&transcript, transcript.Segments)
+
+	dir := t.TempDir()
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonFile := filepath.Join(dir, "talk.json")
+	if err := os.WriteFile(jsonFile, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	rawFile := filepath.Join(dir, "talk"+rawArchiveSuffix)
+	if err := writeRawArchive(rawFile, rawArchive{Result: result}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{jsonFile, rawFile} {
+		loaded, err := loadRenderInput(path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		if !reflect.DeepEqual(*loaded, result) {
+			t.Errorf("%s: expected %+v, got %+v", path, result, *loaded)
+		}
+		if stem := renderStem(path); stem != "talk" {
+			t.Errorf("%s: expected the stem talk, got %s", path, stem)
+		}
+	}
+}
+
+func TestRenderedResultMatchesOriginal(t *testing.T) {
+	var transcript Transcript
+	if err := json.Unmarshal([]byte(fakeTranscriptionResponse), &transcript); err != nil {
+		t.Fatal(err)
+	}
+	result := newResult(&transcript, transcript.Segments)
+
+	for _, format := range []string{"srt", "verbose_json"} {
+		original, err := renderTranscript(&transcript, format, MergeOptions{}, htmlPage{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rendered, err := renderTranscript(transcriptFromResult(&result), format, MergeOptions{}, htmlPage{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rendered != original {
+			t.Errorf("%s: expected the re-rendered output to match the original:\n%s", format, rendered)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// resultSchemaVersion is the version of pindar's JSON result. It only changes
+// when a field is removed or changes its meaning; new fields keep the version.
+const resultSchemaVersion = 1
+
+// Result is pindar's JSON transcript, written by --format verbose_json and
+// passed to --post-hook commands. It keeps the fields of OpenAI's verbose_json,
+// so existing readers continue to work, but it is built by pindar and stays
+// the same whichever provider transcribed the audio.
+type Result struct {
+	SchemaVersion int    `json:"schema_version"`
+	Provider      string `json:"provider,omitempty"`
+	Model         string `json:"model,omitempty"`
+	Task          string `json:"task,omitempty"`
+	// Language is the language the provider detected or was told, as it reported it
+	Language string  `json:"language,omitempty"`
+	Duration float64 `json:"duration,omitempty"`
+	Text     string  `json:"text"`
+	// Speakers lists the speaker labels of the segments in order of appearance
+	Speakers []string `json:"speakers,omitempty"`
+	// Confidence is the model's confidence in the whole transcript from 0 to 1
+	Confidence float64        `json:"confidence,omitempty"`
+	Segments   []Segment      `json:"segments,omitempty"`
+	Words      []Word         `json:"words,omitempty"`
+	Logprobs   []TokenLogprob `json:"logprobs,omitempty"`
+	// Extensions are the fields of the provider's response pindar doesn't
+	// interpret, kept as the provider sent them
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+// newResult builds the JSON result of a transcript with the given segments,
+// which may have been merged
+func newResult(transcript *Transcript, segments []Segment) Result {
+	scored := make([]Segment, len(segments))
+	for i, segment := range segments {
+		if segment.AvgLogprob != 0 {
+			segment.Confidence = logprobConfidence(segment.AvgLogprob)
+		}
+		scored[i] = segment
+	}
+	confidence := 0.0
+	if score, ok := transcriptScore(transcript); ok {
+		confidence = logprobConfidence(score)
+	}
+
+	return Result{
+		SchemaVersion: resultSchemaVersion,
+		Provider:      transcript.Provider,
+		Model:         transcript.Model,
+		Task:          transcript.Task,
+		Language:      transcript.Language,
+		Duration:      transcript.Duration,
+		Text:          transcript.Text,
+		Speakers:      segmentSpeakers(segments),
+		Confidence:    confidence,
+		Segments:      scored,
+		Words:         transcript.Words,
+		Logprobs:      transcript.Logprobs,
+		Extensions:    transcript.Extensions,
+	}
+}
+
+// logprobConfidence turns an average log probability into a confidence from
+// 0 to 1: the geometric mean of the token probabilities, rounded to 3 digits.
+// Providers reporting other measures map them to the same scale, so results
+// can be compared whichever provider made them.
+func logprobConfidence(logprob float64) float64 {
+	return math.Round(math.Exp(min(logprob, 0))*1000) / 1000
+}
+
+// segmentSpeakers returns the distinct speakers of the segments in order of appearance
+func segmentSpeakers(segments []Segment) []string {
+	var speakers []string
+	seen := map[string]bool{}
+	for _, segment := range segments {
+		if segment.Speaker != "" && !seen[segment.Speaker] {
+			seen[segment.Speaker] = true
+			speakers = append(speakers, segment.Speaker)
+		}
+	}
+	return speakers
+}
+
+// parseResult reads a JSON result. Files without a schema version, like
+// OpenAI's own verbose_json, are accepted as well; a newer version than
+// pindar knows is rejected, since its fields may mean something else.
+func parseResult(data []byte) (*Result, error) {
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse transcript: %w", err)
+	}
+	if result.SchemaVersion > resultSchemaVersion {
+		return nil, fmt.Errorf(tr("the transcript has schema version %d, but this pindar only reads up to version %d; update pindar"), result.SchemaVersion, resultSchemaVersion)
+	}
+	return &result, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewResult(t *testing.T) {
+	transcript := &Transcript{Provider: "openai", Model: "whisper-1", Language: "english", Text: "Hi. Hello."}
+	segments := []Segment{{Text: " Hi.", Speaker: "Customer"}, {Text: " Hello.", Speaker: "Agent"}, {Text: " Bye.", Speaker: "Customer"}}
+	result := newResult(transcript, segments)
+
+	if result.SchemaVersion != resultSchemaVersion || result.Provider != "openai" || result.Model != "whisper-1" {
+		t.Errorf("Expected the schema version, provider and model, got %+v", result)
+	}
+	if len(result.Speakers) != 2 || result.Speakers[0] != "Customer" || result.Speakers[1] != "Agent" {
+		t.Errorf("Expected the speakers in order of appearance, got %v", result.Speakers)
+	}
+
+	data, _ := json.Marshal(result)
+	parsed, err := parseResult(data)
+	if err != nil || parsed.Text != transcript.Text || len(parsed.Segments) != 3 {
+		t.Errorf("Expected the result to round-trip, got %+v, %v", parsed, err)
+	}
+}
+
+func TestParseResult(t *testing.T) {
+	if result, err := parseResult([]byte(`{"task": "transcribe", "text": "Hi.", "segments": [{"id": 0, "start": 0, "end": 1, "text": " Hi."}]}`)); err != nil || result.SchemaVersion != 0 || len(result.Segments) != 1 {
+		t.Errorf("Expected OpenAI's verbose_json to be accepted, got %+v, %v", result, err)
+	}
+	if _, err := parseResult([]byte(`{"schema_version": 99, "text": "Hi."}`)); err == nil {
+		t.Error("Expected a newer schema version to be rejected")
+	}
+	if _, err := parseResult([]byte(`not json`)); err == nil {
+		t.Error("Expected invalid JSON to be rejected")
+	}
+}
+
+func TestResultConfidence(t *testing.T) {
+	transcript := &Transcript{Segments: []Segment{{Start: 0, End: 1, AvgLogprob: -0.1}, {Start: 1, End: 4, AvgLogprob: -0.5}, {Start: 4, End: 5}}}
+	result := newResult(transcript, transcript.Segments)
+	if result.Segments[0].Confidence != 0.905 || result.Segments[1].Confidence != 0.607 || result.Segments[2].Confidence != 0 {
+		t.Errorf("Expected the segment confidences from their avg_logprob, got %+v", result.Segments)
+	}
+	if transcript.Segments[0].Confidence != 0 {
+		t.Error("Expected the transcript's segments to stay unchanged")
+	}
+	if result.Confidence != logprobConfidence(-1.6/5) {
+		t.Errorf("Expected the duration weighted confidence, got %v", result.Confidence)
+	}
+
+	logprobs := &Transcript{Logprobs: []TokenLogprob{{Logprob: -0.2}, {Logprob: 0}}}
+	if result := newResult(logprobs, nil); result.Confidence != 0.905 {
+		t.Errorf("Expected the confidence from the token logprobs, got %v", result.Confidence)
+	}
+	if result := newResult(&Transcript{Text: "Hi."}, nil); result.Confidence != 0 {
+		t.Errorf("Expected no confidence without logprobs, got %v", result.Confidence)
+	}
+}
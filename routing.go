@@ -0,0 +1,97 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// defaultModel is used when neither --model nor a routing rule selects a model
+const defaultModel = "gpt-4o-transcribe"
+
+// RoutingRule selects a model for audio up to a maximum duration
+type RoutingRule struct {
+	// MaxDuration is a Go duration like "5m" or "1h30m"; empty matches any length
+	MaxDuration string `json:"max_duration,omitempty"`
+	Model       string `json:"model"`
+}
+
+// routeModel picks the model of the first rule matching the audio duration in
+// seconds. A negative duration means unknown and only matches rules without a
+// maximum. It returns the chosen model and the 1-based index of the rule, or
+// the default model and 0 if no rule matches.
+func routeModel(rules []RoutingRule, duration float64) (string, int, error) {
+	for i, rule := range rules {
+		if rule.Model == "" {
+			return "", 0, fmt.Errorf("routing rule %d has no model", i+1)
+		}
+		if rule.MaxDuration == "" {
+			return rule.Model, i + 1, nil
+		}
+
+		limit, err := time.ParseDuration(rule.MaxDuration)
+		if err != nil {
+			return "", 0, fmt.Errorf("routing rule %d: invalid max_duration %q: %w", i+1, rule.MaxDuration, err)
+		}
+		if duration >= 0 && duration <= limit.Seconds() {
+			return rule.Model, i + 1, nil
+		}
+	}
+	return defaultModel, 0, nil
+}
+
+// validateRoutingRules checks every rule, including those routeModel doesn't reach
+func validateRoutingRules(rules []RoutingRule) error {
+	for i, rule := range rules {
+		if rule.Model == "" {
+			return fmt.Errorf("routing rule %d has no model", i+1)
+		}
+		if rule.MaxDuration == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(rule.MaxDuration); err != nil {
+			return fmt.Errorf("routing rule %d: invalid max_duration %q: %w", i+1, rule.MaxDuration, err)
+		}
+	}
+	return nil
+}
+
+// probeDuration returns the duration of a media file in seconds
+func probeDuration(path string) (float64, error) {
+	probe, err := probeAudio(path)
+	if err != nil {
+		return 0, err
+	}
+	duration, err := strconv.ParseFloat(probe.Format.Duration, 64)
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe reported no duration for %s", path)
+	}
+	return duration, nil
+}
+
+// selectRoutedModel applies the routing rules to an input file and reports
+// the choice. The fallback set with pindar init replaces the default model.
+func selectRoutedModel(rules []RoutingRule, fallback, path string) (string, error) {
+	if len(rules) == 0 {
+		return cmp.Or(fallback, defaultModel), nil
+	}
+
+	duration, err := probeDuration(path)
+	if err != nil {
+		uiPrintf(tr("⚠️  Could not determine audio duration for routing: %v\n"), err)
+		duration = -1
+	}
+
+	model, rule, err := routeModel(rules, duration)
+	if err != nil {
+		return "", err
+	}
+	if rule == 0 {
+		return cmp.Or(fallback, model), nil
+	}
+	if duration >= 0 {
+		uiPrintf(tr(" Routing %s audio to %s (rule %d)\n"), formatTimestamp(duration), model, rule)
+	}
+	return model, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteModel(t *testing.T) {
+	rules := []RoutingRule{
+		{MaxDuration: "5m", Model: "gpt-4o-transcribe"},
+		{MaxDuration: "1h", Model: "gpt-4o-mini-transcribe"},
+		{Model: "whisper-1"},
+	}
+
+	tests := []struct {
+		name         string
+		duration     float64
+		expected     string
+		expectedRule int
+	}{
+		{"Short file", 120, "gpt-4o-transcribe", 1},
+		{"Exactly at the limit", 300, "gpt-4o-transcribe", 1},
+		{"Medium file", 1800, "gpt-4o-mini-transcribe", 2},
+		{"Long file", 7200, "whisper-1", 3},
+		{"Unknown duration", -1, "whisper-1", 3},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model, rule, err := routeModel(rules, tc.duration)
+			if err != nil {
+				t.Fatalf("routeModel() failed: %v", err)
+			}
+			if model != tc.expected || rule != tc.expectedRule {
+				t.Errorf("Expected %s (rule %d), got %s (rule %d)", tc.expected, tc.expectedRule, model, rule)
+			}
+		})
+	}
+}
+
+func TestRouteModelDefaults(t *testing.T) {
+	model, rule, err := routeModel(nil, 60)
+	if err != nil || model != defaultModel || rule != 0 {
+		t.Errorf("Expected default model without rules, got %s (rule %d, err %v)", model, rule, err)
+	}
+
+	model, _, _ = routeModel([]RoutingRule{{MaxDuration: "5m", Model: "whisper-1"}}, 600)
+	if model != defaultModel {
+		t.Errorf("Expected default model when no rule matches, got %s", model)
+	}
+}
+
+func TestRouteModelInvalidRules(t *testing.T) {
+	if _, _, err := routeModel([]RoutingRule{{MaxDuration: "five minutes", Model: "whisper-1"}}, 60); err == nil {
+		t.Error("Expected an error for an invalid max_duration")
+	}
+	if _, _, err := routeModel([]RoutingRule{{MaxDuration: "5m"}}, 60); err == nil {
+		t.Error("Expected an error for a rule without model")
+	}
+}
+
+func TestValidateRoutingRules(t *testing.T) {
+	valid := []RoutingRule{{MaxDuration: "5m", Model: "gpt-4o-transcribe"}, {Model: "whisper-1"}}
+	if err := validateRoutingRules(valid); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// routeModel stops at the catch-all rule, so only validation finds the broken one after it
+	invalid := append(valid, RoutingRule{MaxDuration: "1 hour", Model: "whisper-1"})
+	if err := validateRoutingRules(invalid); err == nil || !strings.Contains(err.Error(), "rule 3") {
+		t.Errorf("Expected rule 3 to be reported, got %v", err)
+	}
+	if err := validateRoutingRules([]RoutingRule{{MaxDuration: "5m"}}); err == nil {
+		t.Error("Expected an error for a rule without model")
+	}
+}
+
+func TestSelectRoutedModelFallback(t *testing.T) {
+	if model, err := selectRoutedModel(nil, "whisper-1", "audio.mp3"); err != nil || model != "whisper-1" {
+		t.Errorf("Expected the configured model without rules, got %s (%v)", model, err)
+	}
+	if model, err := selectRoutedModel(nil, "", "audio.mp3"); err != nil || model != defaultModel {
+		t.Errorf("Expected the default model, got %s (%v)", model, err)
+	}
+}
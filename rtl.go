@@ -0,0 +1,48 @@
+package main
+
+import "strings"
+
+// rightToLeftMark makes the surrounding neutral characters, like punctuation
+// at the end of a subtitle line, take right-to-left direction
+const rightToLeftMark = "\u200f"
+
+// rtlLanguages are the languages written from right to left
+var rtlLanguages = map[string]bool{"ar": true, "fa": true, "he": true, "ps": true, "sd": true, "ug": true, "ur": true, "yi": true}
+
+// isRTL reports whether a language, as a code or as the name the API reports, is written from right to left
+func isRTL(language string) bool {
+	code, err := normalizeLanguage(language)
+	return err == nil && rtlLanguages[code]
+}
+
+// markRTL puts a right-to-left mark at both ends of every line, so players
+// that lay out subtitles left to right still put the punctuation at the end
+// of the sentence instead of at its start
+func markRTL(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			lines[i] = rightToLeftMark + line + rightToLeftMark
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withDirectionMarks returns copies of the segments with right-to-left marks around their text
+func withDirectionMarks(segments []Segment) []Segment {
+	marked := make([]Segment, len(segments))
+	for i, segment := range segments {
+		segment.Text = markRTL(segment.Text)
+		marked[i] = segment
+	}
+	return marked
+}
+
+// rtlMarkdown sets the direction of a Markdown document written in a
+// right-to-left language; Markdown itself has no way to express it
+func rtlMarkdown(content, language string) string {
+	if !isRTL(language) {
+		return content
+	}
+	return "<div dir=\"rtl\">\n\n" + content + "\n</div>\n"
+}
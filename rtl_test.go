@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsRTL(t *testing.T) {
+	for language, expected := range map[string]bool{"arabic": true, "he": true, "Persian": true, "english": false, "": false, "klingon": false} {
+		if got := isRTL(language); got != expected {
+			t.Errorf("isRTL(%q) = %v, expected %v", language, got, expected)
+		}
+	}
+}
+
+func TestRTLOutputs(t *testing.T) {
+	transcript := &Transcript{Language: "hebrew", Text: "שלום.", Segments: []Segment{{Start: 0, End: 1, Text: " שלום."}}}
+
+	srt, _ := renderTranscript(transcript, "srt", MergeOptions{}, htmlPage{})
+	if !strings.Contains(srt, "\n\u200fשלום.\u200f\n") {
+		t.Errorf("Expected right-to-left marks around the cue text, got %q", srt)
+	}
+	if transcript.Segments[0].Text != " שלום." {
+		t.Error("Expected the transcript's segments to stay unchanged")
+	}
+	ttml, _ := renderTranscript(transcript, "ttml", MergeOptions{}, htmlPage{})
+	if !strings.Contains(ttml, `xmlns:tts="http://www.w3.org/ns/ttml#styling"`) || !strings.Contains(ttml, `tts:direction="rtl"`) {
+		t.Errorf("Expected the TTML paragraphs to be right-to-left, got %s", ttml)
+	}
+	html, _ := renderTranscript(transcript, "html", MergeOptions{}, htmlPage{Title: "t"})
+	if !strings.Contains(html, `<html lang="he" dir="rtl">`) {
+		t.Errorf("Expected the html page to be right-to-left")
+	}
+
+	if got := rtlMarkdown("# Minutes\n", "arabic"); got != "<div dir=\"rtl\">\n\n# Minutes\n\n</div>\n" {
+		t.Errorf("Expected the Markdown to be wrapped in a right-to-left div, got %q", got)
+	}
+	if got := rtlMarkdown("# Minutes\n", "english"); got != "# Minutes\n" {
+		t.Errorf("Expected left-to-right Markdown unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CEA-608 limits for pop-on captions: rows are at most 32 characters and
+// pindar uses the bottom two rows of the screen
+const (
+	sccRowWidth = 32
+	sccRows     = 2
+)
+
+// CEA-608 channel 1 control codes, without parity. Each is sent twice, as
+// decoders drop a single code that may have been corrupted.
+const (
+	sccResumeCaptionLoading = 0x1420
+	sccEraseNonDisplayed    = 0x142e
+	sccEndOfCaption         = 0x142f
+	sccEraseDisplayed       = 0x142c
+)
+
+// sccRowPreambles position the cursor at column 0 of rows 14 and 15
+var sccRowPreambles = []int{0x1450, 0x1470}
+
+// sccCharacters maps characters of the CEA-608 basic character set that
+// differ from ASCII
+var sccCharacters = map[rune]byte{
+	'á': 0x2a, 'é': 0x5c, 'í': 0x5e, 'ó': 0x5f, 'ú': 0x60,
+	'ç': 0x7b, '÷': 0x7c, 'Ñ': 0x7d, 'ñ': 0x7e,
+}
+
+// sccTransliterations spell characters outside the basic character set with ones inside it
+var sccTransliterations = strings.NewReplacer(
+	"ä", "a", "à", "a", "â", "a", "Ä", "A", "À", "A", "Â", "A", "Á", "A",
+	"ë", "e", "è", "e", "ê", "e", "É", "E", "È", "E", "Ê", "E",
+	"ï", "i", "î", "i", "Í", "I", "Î", "I",
+	"ö", "o", "ô", "o", "Ö", "O", "Ó", "O", "Ô", "O",
+	"ü", "u", "ù", "u", "û", "u", "Ü", "U", "Ú", "U",
+	"ß", "ss", "Ç", "C",
+	"’", "'", "‘", "'", "“", `"`, "”", `"`, "…", "...", "–", "-", "—", "-",
+)
+
+// withParity sets the odd parity bit CEA-608 requires on a 7-bit byte
+func withParity(b byte) byte {
+	b &= 0x7f
+	ones := 0
+	for v := b; v > 0; v >>= 1 {
+		ones += int(v & 1)
+	}
+	if ones%2 == 0 {
+		b |= 0x80
+	}
+	return b
+}
+
+// sccWord formats a pair of 7-bit bytes as a hex word with parity
+func sccWord(hi, lo byte) string {
+	return fmt.Sprintf("%02x%02x", withParity(hi), withParity(lo))
+}
+
+// sccControl formats a control code, sent twice
+func sccControl(code int) []string {
+	word := sccWord(byte(code>>8), byte(code))
+	return []string{word, word}
+}
+
+// sccBytes converts text to bytes of the CEA-608 basic character set,
+// replacing characters it can't represent
+func sccBytes(text string) []byte {
+	var out []byte
+	for _, r := range sccTransliterations.Replace(text) {
+		switch {
+		case sccCharacters[r] != 0:
+			out = append(out, sccCharacters[r])
+		case r >= 0x20 && r < 0x7f && !strings.ContainsRune("*\\^_`{|}~", r):
+			out = append(out, byte(r))
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// wrapCaption breaks text into lines of at most width characters at spaces,
+// hard-breaking words that are longer than a line
+func wrapCaption(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for len(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			lines = append(lines, word[:width])
+			word = word[width:]
+		}
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// sccCaption is a pop-on caption of up to sccRows lines
+type sccCaption struct {
+	Start, End float64
+	Lines      []string
+}
+
+// sccCaptions splits segments into captions that fit the screen, dividing the
+// time of a long segment by the number of characters per caption
+func sccCaptions(segments []Segment) []sccCaption {
+	var captions []sccCaption
+	for _, segment := range segments {
+		lines := wrapCaption(string(sccBytes(labeledText(segment))), sccRowWidth)
+		total := 0
+		for _, line := range lines {
+			total += len(line)
+		}
+
+		start, done := segment.Start, 0
+		for i := 0; i < len(lines); i += sccRows {
+			chunk := lines[i:min(i+sccRows, len(lines))]
+			for _, line := range chunk {
+				done += len(line)
+			}
+			end := segment.Start + (segment.End-segment.Start)*float64(done)/float64(total)
+			captions = append(captions, sccCaption{Start: start, End: end, Lines: chunk})
+			start = end
+		}
+	}
+	return captions
+}
+
+// sccFrame converts seconds to a frame number at the NTSC rate of 29.97 fps
+func sccFrame(seconds float64) int {
+	return int(math.Round(max(seconds, 0) * 30000 / 1001))
+}
+
+// formatDropFrame renders a frame number as an SMPTE drop-frame timecode,
+// which skips frame numbers 0 and 1 of every minute except each tenth so the
+// timecode stays in step with the clock
+func formatDropFrame(frame int) string {
+	const framesPer10Minutes, framesPerMinute = 17982, 1798
+	d, m := frame/framesPer10Minutes, frame%framesPer10Minutes
+	frame += 18 * d
+	if m > 1 {
+		frame += 2 * ((m - 2) / framesPerMinute)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d;%02d", frame/108000, frame/1800%60, frame/30%60, frame%30)
+}
+
+// renderSCC produces Scenarist SCC captions (CEA-608, channel 1) with one
+// pop-on caption per screenful of segment text
+func renderSCC(segments []Segment) string {
+	var b strings.Builder
+	b.WriteString("Scenarist_SCC V1.0\n")
+
+	captions := sccCaptions(segments)
+	// Each line of codes takes one frame per word to transmit; nextFree is the
+	// first frame not used by the previous line
+	nextFree := 0
+	for i, caption := range captions {
+		codes := append(sccControl(sccResumeCaptionLoading), sccControl(sccEraseNonDisplayed)...)
+		rows := sccRowPreambles[sccRows-len(caption.Lines):]
+		for j, line := range caption.Lines {
+			codes = append(codes, sccControl(rows[j])...)
+			text := []byte(line)
+			if len(text)%2 == 1 {
+				text = append(text, 0)
+			}
+			for k := 0; k < len(text); k += 2 {
+				codes = append(codes, sccWord(text[k], text[k+1]))
+			}
+		}
+		codes = append(codes, sccControl(sccEndOfCaption)...)
+
+		// Load the caption so that it appears right at its start time
+		load := max(sccFrame(caption.Start)-len(codes)+1, nextFree)
+		fmt.Fprintf(&b, "\n%s\t%s\n", formatDropFrame(load), strings.Join(codes, " "))
+		nextFree = load + len(codes)
+
+		// The next caption replaces this one when it follows right away,
+		// otherwise clear the screen at the end
+		if i+1 == len(captions) || captions[i+1].Start-caption.End >= 1 {
+			clear := max(sccFrame(caption.End), nextFree)
+			fmt.Fprintf(&b, "\n%s\t%s\n", formatDropFrame(clear), strings.Join(sccControl(sccEraseDisplayed), " "))
+			nextFree = clear + 2
+		}
+	}
+	return b.String()
+}
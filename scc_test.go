@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithParity(t *testing.T) {
+	tests := []struct {
+		in, expected byte
+	}{
+		{0x14, 0x94}, {0x20, 0x20}, {0x2f, 0x2f}, {0x2c, 0x2c}, {0x48, 0xc8}, {0x00, 0x80},
+	}
+
+	for _, tc := range tests {
+		if result := withParity(tc.in); result != tc.expected {
+			t.Errorf("withParity(%#x) = %#x, expected %#x", tc.in, result, tc.expected)
+		}
+	}
+}
+
+func TestFormatDropFrame(t *testing.T) {
+	tests := []struct {
+		frame    int
+		expected string
+	}{
+		{0, "00:00:00;00"},
+		{1799, "00:00:59;29"},
+		{1800, "00:01:00;02"},
+		{17982, "00:10:00;00"},
+		{107892, "01:00:00;00"},
+	}
+
+	for _, tc := range tests {
+		if result := formatDropFrame(tc.frame); result != tc.expected {
+			t.Errorf("formatDropFrame(%d) = %s, expected %s", tc.frame, result, tc.expected)
+		}
+	}
+}
+
+func TestWrapCaption(t *testing.T) {
+	expected := []string{"This is a much longer segment", "that will not fit on one row."}
+	result := wrapCaption("This is a much longer segment that will not fit on one row.", 32)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestSCCBytes(t *testing.T) {
+	expected := "Se\x7eor Muller? \"ok\""
+	result := string(sccBytes("Señor Müller* “ok”"))
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestRenderSCC(t *testing.T) {
+	expected := "Scenarist_SCC V1.0\n" +
+		"\n00:00:00;16\t9420 9420 94ae 94ae 9470 9470 c8e5 ecec ef2c 2073 e5fe eff2 a180 942f 942f\n" +
+		"\n00:00:03;00\t942c 942c\n"
+	result := renderSCC([]Segment{{Start: 1, End: 3, Text: " Hello, señor!"}})
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ScorecardCriterion is one check of a --qa-scorecard rules file. A criterion
+// passes if any of its phrases is said, or with None if none of them is.
+type ScorecardCriterion struct {
+	Name string `yaml:"name"`
+	// Speaker limits the check to the segments of one --split-call speaker
+	Speaker string `yaml:"speaker"`
+	// Within limits the check to the first seconds of the call (0 means the whole call)
+	Within float64  `yaml:"within"`
+	Any    []string `yaml:"any"`
+	None   []string `yaml:"none"`
+	Points *int     `yaml:"points"`
+}
+
+// ScorecardRules are the criteria a call is scored against
+type ScorecardRules struct {
+	Criteria []ScorecardCriterion `yaml:"criteria"`
+}
+
+// usesSpeakers reports whether any criterion is limited to a speaker
+func (r *ScorecardRules) usesSpeakers() bool {
+	for _, c := range r.Criteria {
+		if c.Speaker != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// scorecardResult is the outcome of one criterion with the segment that decided it
+type scorecardResult struct {
+	Criterion ScorecardCriterion
+	Passed    bool
+	Evidence  *Segment
+}
+
+// points returns the points a criterion is worth, 1 unless set
+func (c ScorecardCriterion) points() int {
+	if c.Points == nil {
+		return 1
+	}
+	return *c.Points
+}
+
+// loadScorecardRules reads and validates a rules file
+func loadScorecardRules(path string) (*ScorecardRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var rules ScorecardRules
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(rules.Criteria) == 0 {
+		return nil, fmt.Errorf(tr("%s has no criteria"), path)
+	}
+	for i, c := range rules.Criteria {
+		switch {
+		case strings.TrimSpace(c.Name) == "":
+			return nil, fmt.Errorf(tr("criterion %d has no name"), i+1)
+		case (len(c.Any) == 0) == (len(c.None) == 0):
+			return nil, fmt.Errorf(tr("criterion %q needs either \"any\" or \"none\" phrases"), c.Name)
+		case c.Within < 0 || c.points() < 0:
+			return nil, fmt.Errorf(tr("criterion %q has a negative \"within\" or \"points\""), c.Name)
+		}
+	}
+	return &rules, nil
+}
+
+// normalizePhrase lowercases text and reduces it to words separated by single
+// spaces, padded with spaces so phrases only match whole words
+func normalizePhrase(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	})
+	return " " + strings.Join(words, " ") + " "
+}
+
+// findPhrase returns the first segment saying one of the phrases, or nil
+func findPhrase(segments []Segment, phrases []string) *Segment {
+	for i := range segments {
+		text := normalizePhrase(segments[i].Text)
+		for _, phrase := range phrases {
+			if strings.Contains(text, normalizePhrase(phrase)) {
+				return &segments[i]
+			}
+		}
+	}
+	return nil
+}
+
+// scoreCall evaluates every criterion against the segments of a call
+func scoreCall(rules *ScorecardRules, segments []Segment) []scorecardResult {
+	results := make([]scorecardResult, len(rules.Criteria))
+	for i, c := range rules.Criteria {
+		var scope []Segment
+		for _, segment := range segments {
+			if c.Speaker != "" && !strings.EqualFold(segment.Speaker, c.Speaker) {
+				continue
+			}
+			if c.Within > 0 && segment.Start >= c.Within {
+				continue
+			}
+			scope = append(scope, segment)
+		}
+
+		results[i] = scorecardResult{Criterion: c}
+		if len(c.Any) > 0 {
+			results[i].Evidence = findPhrase(scope, c.Any)
+			results[i].Passed = results[i].Evidence != nil
+		} else {
+			results[i].Evidence = findPhrase(scope, c.None)
+			results[i].Passed = results[i].Evidence == nil
+		}
+	}
+	return results
+}
+
+// renderScorecard formats the results as a Markdown report with the total score
+func renderScorecard(title string, results []scorecardResult) string {
+	score, total := 0, 0
+	for _, result := range results {
+		total += result.Criterion.points()
+		if result.Passed {
+			score += result.Criterion.points()
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# QA scorecard: %s\n\n", title)
+	percent := 100
+	if total > 0 {
+		percent = score * 100 / total
+	}
+	fmt.Fprintf(&b, "**Score: %d/%d (%d%%)**\n\n", score, total, percent)
+	b.WriteString("| Criterion | Result | Points | Evidence |\n|---|---|---|---|\n")
+	for _, result := range results {
+		status, points := "❌ Fail", 0
+		if result.Passed {
+			status, points = "✅ Pass", result.Criterion.points()
+		}
+		evidence := ""
+		if result.Evidence != nil {
+			evidence = strings.TrimSpace(result.Evidence.Text)
+			if result.Evidence.Speaker != "" {
+				evidence = result.Evidence.Speaker + ": " + evidence
+			}
+			evidence = "[" + formatTimestamp(result.Evidence.Start) + "] " + evidence
+		}
+		fmt.Fprintf(&b, "| %s | %s | %d/%d | %s |\n", markdownCell(result.Criterion.Name), status, points, result.Criterion.points(), markdownCell(evidence))
+	}
+	return b.String()
+}
+
+// saveScorecard scores the call and writes the report to a Markdown sidecar file
+func saveScorecard(args Args, originalFile string, rules *ScorecardRules, transcript *Transcript) error {
+	if len(transcript.Segments) == 0 {
+		return errors.New(tr("the transcript has no segments to score"))
+	}
+	scorecardFile := sidecarFileName(args, originalFile, ".scorecard.md")
+	content := renderScorecard(filepath.Base(originalFile), scoreCall(rules, transcript.Segments))
+	if err := os.WriteFile(scorecardFile, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write scorecard: %w", err)
+	}
+	uiPrintf(tr("💾 QA scorecard saved to: %s\n"), scorecardFile)
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testScorecardRules = `criteria:
+  - name: Greeting
+    speaker: agent
+    within: 15
+    any: ["thank you for calling", "good morning"]
+    points: 10
+  - name: Hold time mentioned
+    any: ["on hold"]
+  - name: No prohibited phrases
+    none: ["calm down", "not my problem"]
+    points: 5
+`
+
+func writeScorecardRules(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadScorecardRules(t *testing.T) {
+	rules, err := loadScorecardRules(writeScorecardRules(t, testScorecardRules))
+	if err != nil {
+		t.Fatalf("loadScorecardRules() failed: %v", err)
+	}
+	if len(rules.Criteria) != 3 || rules.Criteria[1].points() != 1 || rules.Criteria[2].points() != 5 || !rules.usesSpeakers() {
+		t.Errorf("Unexpected rules %+v", rules)
+	}
+
+	for _, invalid := range []string{
+		"criteria: []\n",
+		"criteria:\n  - any: [hello]\n",
+		"criteria:\n  - name: Both\n    any: [a]\n    none: [b]\n",
+		"criteria:\n  - name: Neither\n",
+		"criteria:\n  - name: Negative\n    any: [a]\n    points: -1\n",
+		"criteria:\n  - name: Typo\n    anny: [a]\n",
+	} {
+		if _, err := loadScorecardRules(writeScorecardRules(t, invalid)); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestScoreCall(t *testing.T) {
+	rules, err := loadScorecardRules(writeScorecardRules(t, testScorecardRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	segments := []Segment{
+		{Start: 1, Text: " Good morning! Thank you for calling.", Speaker: "Customer"},
+		{Start: 3, Text: " Thank you for calling, this is Ana.", Speaker: "Agent"},
+		{Start: 20, Text: " Please CALM down, sir.", Speaker: "Agent"},
+	}
+
+	results := scoreCall(rules, segments)
+	if !results[0].Passed || results[0].Evidence.Start != 3 {
+		t.Errorf("Expected the agent's greeting to pass, got %+v", results[0])
+	}
+	if results[1].Passed || results[1].Evidence != nil {
+		t.Errorf("Expected the missing phrase to fail, got %+v", results[1])
+	}
+	if results[2].Passed || results[2].Evidence.Start != 20 {
+		t.Errorf("Expected the prohibited phrase to fail, got %+v", results[2])
+	}
+
+	report := renderScorecard("call.wav", results)
+	for _, expected := range []string{
+		"**Score: 10/16 (62%)**",
+		"| Greeting | ✅ Pass | 10/10 | [00:00:03] Agent: Thank you for calling, this is Ana. |",
+		"| Hold time mentioned | ❌ Fail | 0/1 |  |",
+		"| No prohibited phrases | ❌ Fail | 0/5 | [00:00:20] Agent: Please CALM down, sir. |",
+	} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected %q in the report:\n%s", expected, report)
+		}
+	}
+}
+
+func TestNormalizePhrase(t *testing.T) {
+	if !strings.Contains(normalizePhrase("Hi, it's—ON HOLD."), normalizePhrase("on hold")) {
+		t.Error("Expected punctuation and case to be ignored")
+	}
+	if strings.Contains(normalizePhrase("uphold"), normalizePhrase("hold")) {
+		t.Error("Expected phrases to match whole words only")
+	}
+}
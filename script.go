@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// transcriptScript is a --script file, loaded once and applied to every transcript
+type transcriptScript struct {
+	path  string
+	state *lua.LState
+}
+
+// loadTranscriptScript runs a Lua script and checks that it defines the
+// transform function, so a broken script fails before anything is uploaded
+func loadTranscriptScript(path string) (*transcriptScript, error) {
+	state := lua.NewState()
+	if err := state.DoFile(path); err != nil {
+		state.Close()
+		return nil, fmt.Errorf("failed to run %s: %w", path, err)
+	}
+	if state.GetGlobal("transform").Type() != lua.LTFunction {
+		state.Close()
+		return nil, fmt.Errorf(tr("%s does not define a function transform(transcript)"), path)
+	}
+	return &transcriptScript{path: path, state: state}, nil
+}
+
+// close releases the Lua state
+func (s *transcriptScript) close() {
+	s.state.Close()
+}
+
+// apply passes the transcript to the script's transform function and takes
+// the segments back. The function may change the table in place or return a
+// new one; the text is rebuilt from the segments, and words outside the
+// remaining segments are dropped.
+func (s *transcriptScript) apply(transcript *Transcript) error {
+	segments, err := toLuaValue(s.state, transcript.Segments)
+	if err != nil {
+		return err
+	}
+	table := s.state.NewTable()
+	table.RawSetString("language", lua.LString(transcript.Language))
+	table.RawSetString("duration", lua.LNumber(transcript.Duration))
+	table.RawSetString("text", lua.LString(transcript.Text))
+	table.RawSetString("segments", segments)
+
+	err = s.state.CallByParam(lua.P{Fn: s.state.GetGlobal("transform"), NRet: 1, Protect: true}, table)
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.path, err)
+	}
+	result := s.state.Get(-1)
+	s.state.Pop(1)
+	if result != lua.LNil {
+		returned, ok := result.(*lua.LTable)
+		if !ok {
+			return fmt.Errorf(tr("%s: transform must return the transcript table, not a %s"), s.path, result.Type())
+		}
+		table = returned
+	}
+
+	var transformed []Segment
+	if err := fromLuaValue(table.RawGetString("segments"), &transformed); err != nil {
+		return fmt.Errorf(tr("%s: invalid segments: %v"), s.path, err)
+	}
+	// Segments whose text the script changed lose their token IDs, as with
+	// setSegmentText
+	said := map[string]bool{}
+	for _, segment := range transcript.Segments {
+		said[segment.Text] = true
+	}
+	for i := range transformed {
+		transformed[i].ID = i
+		if !said[transformed[i].Text] {
+			transformed[i].Tokens = nil
+		}
+	}
+	transcript.Segments = transformed
+	transcript.Words = wordsWithinSegments(transcript.Words, transformed)
+	transcript.Text = segmentsText(transformed)
+	syncWordsWithText(transcript)
+	return nil
+}
+
+// wordsWithinSegments keeps the words that start inside one of the segments
+func wordsWithinSegments(words []Word, segments []Segment) []Word {
+	var kept []Word
+	for _, word := range words {
+		for _, segment := range segments {
+			if word.Start >= segment.Start && word.Start < segment.End {
+				kept = append(kept, word)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// toLuaValue converts a value to Lua tables, strings, numbers and booleans
+// through its JSON form, so the fields have their verbose_json names
+func toLuaValue(state *lua.LState, value any) (lua.LValue, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal script input: %w", err)
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal script input: %w", err)
+	}
+	return jsonToLua(state, decoded), nil
+}
+
+// jsonToLua converts a decoded JSON value to Lua
+func jsonToLua(state *lua.LState, value any) lua.LValue {
+	switch v := value.(type) {
+	case map[string]any:
+		table := state.NewTable()
+		for key, item := range v {
+			table.RawSetString(key, jsonToLua(state, item))
+		}
+		return table
+	case []any:
+		table := state.NewTable()
+		for _, item := range v {
+			table.Append(jsonToLua(state, item))
+		}
+		return table
+	case string:
+		return lua.LString(v)
+	case float64:
+		return lua.LNumber(v)
+	case bool:
+		return lua.LBool(v)
+	}
+	return lua.LNil
+}
+
+// luaToJSON converts a Lua value to a value encoding/json can marshal. Tables
+// with array elements become lists, other tables objects; an empty table
+// becomes null, which is valid for both.
+func luaToJSON(value lua.LValue) (any, error) {
+	switch v := value.(type) {
+	case *lua.LTable:
+		if n := v.MaxN(); n > 0 {
+			list := make([]any, n)
+			for i := range list {
+				item, err := luaToJSON(v.RawGetInt(i + 1))
+				if err != nil {
+					return nil, err
+				}
+				list[i] = item
+			}
+			return list, nil
+		}
+		object := map[string]any{}
+		var err error
+		v.ForEach(func(key, item lua.LValue) {
+			if err != nil {
+				return
+			}
+			if key.Type() != lua.LTString {
+				err = fmt.Errorf(tr("unexpected table key %s"), key.String())
+				return
+			}
+			object[key.String()], err = luaToJSON(item)
+		})
+		if len(object) == 0 {
+			return nil, err
+		}
+		return object, err
+	case lua.LString:
+		return string(v), nil
+	case lua.LNumber:
+		return float64(v), nil
+	case lua.LBool:
+		return bool(v), nil
+	}
+	if value == lua.LNil {
+		return nil, nil
+	}
+	return nil, fmt.Errorf(tr("a %s can't be part of a transcript"), value.Type())
+}
+
+// fromLuaValue decodes a Lua value into dest through its JSON form, rejecting
+// unknown fields so a misspelled field name doesn't go unnoticed
+func fromLuaValue(value lua.LValue, dest any) error {
+	decoded, err := luaToJSON(value)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dest); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return fmt.Errorf(tr("%s must not be a %s"), typeErr.Field, typeErr.Value)
+		}
+		return err
+	}
+	return nil
+}
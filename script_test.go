@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeScript(t *testing.T, source string) *transcriptScript {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "transform.lua")
+	os.WriteFile(path, []byte(source), 0644)
+	script, err := loadTranscriptScript(path)
+	if err != nil {
+		t.Fatalf("loadTranscriptScript() failed: %v", err)
+	}
+	t.Cleanup(script.close)
+	return script
+}
+
+func TestTranscriptScript(t *testing.T) {
+	script := writeScript(t, `
+local names = {Agent = "Sam", Customer = "Caller"}
+
+function transform(transcript)
+  local kept = {}
+  for _, segment in ipairs(transcript.segments) do
+    if not segment.text:find("%[music%]") then
+      segment.speaker = names[segment.speaker] or segment.speaker
+      table.insert(kept, segment)
+    end
+  end
+  transcript.segments = kept
+  return transcript
+end
+`)
+	transcript := &Transcript{
+		Language: "english",
+		Text:     "Agent: Hello. [music] Customer: Hi.",
+		Segments: []Segment{
+			{ID: 0, Start: 0, End: 1, Text: " Hello.", Speaker: "Agent", Topics: []string{"greeting"}},
+			{ID: 1, Start: 1, End: 5, Text: " [music]", Speaker: "Agent"},
+			{ID: 2, Start: 5, End: 6, Text: " Hi.", Speaker: "Customer"},
+		},
+		Words: []Word{{Word: "Hello", Start: 0.2, End: 0.8}, {Word: "music", Start: 2, End: 4}, {Word: "Hi", Start: 5.1, End: 5.5}},
+	}
+	if err := script.apply(transcript); err != nil {
+		t.Fatalf("apply() failed: %v", err)
+	}
+
+	if len(transcript.Segments) != 2 || transcript.Segments[1].ID != 1 || transcript.Segments[1].Speaker != "Caller" || transcript.Segments[1].End != 6 {
+		t.Errorf("Expected the music to be dropped and the speakers renamed, got %+v", transcript.Segments)
+	}
+	if len(transcript.Segments[0].Topics) != 1 || transcript.Segments[0].Topics[0] != "greeting" {
+		t.Errorf("Expected the topics to survive the round trip, got %v", transcript.Segments[0].Topics)
+	}
+	if transcript.Text != "Sam: Hello.\n\nCaller: Hi." {
+		t.Errorf("Expected the text to be rebuilt from the segments, got %q", transcript.Text)
+	}
+	if len(transcript.Words) != 2 {
+		t.Errorf("Expected the words of the dropped segment to be removed, got %v", transcript.Words)
+	}
+}
+
+func TestTranscriptScriptInPlace(t *testing.T) {
+	script := writeScript(t, `
+function transform(transcript)
+  for _, segment in ipairs(transcript.segments) do
+    segment.text = segment.text:upper()
+  end
+end
+`)
+	transcript := &Transcript{Text: "hi there", Segments: []Segment{{Text: " hi there", End: 1}}}
+	if err := script.apply(transcript); err != nil {
+		t.Fatalf("apply() failed: %v", err)
+	}
+	if transcript.Text != "HI THERE" {
+		t.Errorf("Expected changes to the table to be kept without a return value, got %q", transcript.Text)
+	}
+}
+
+func TestTranscriptScriptErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.lua")
+	os.WriteFile(path, []byte("x = 1"), 0644)
+	if _, err := loadTranscriptScript(path); err == nil {
+		t.Error("Expected a script without transform to be rejected")
+	}
+
+	transcript := &Transcript{Segments: []Segment{{Text: " hi", End: 1}}}
+	for _, source := range []string{
+		`function transform(t) return 42 end`,
+		`function transform(t) t.segments[1].txt = "typo" end`,
+		`function transform(t) t.segments[1].start = "soon" end`,
+		`function transform(t) error("boom") end`,
+	} {
+		if err := writeScript(t, source).apply(transcript); err == nil {
+			t.Errorf("Expected %q to fail", source)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sessionTakePattern matches the take number in the name of a studio
+// recording and the part after it, as in take_03_vocal or Take 3 - Guitar
+var sessionTakePattern = regexp.MustCompile(`(?i)(?:^|[^a-z])take[ _.-]*(\d+)(?:[ _.-]+(.*))?$`)
+
+// sessionAudioExtensions are the recordings --session and pindar digest pick
+// up; other files in the directory, like transcripts and options files, are
+// ignored
+var sessionAudioExtensions = map[string]bool{
+	"aif": true, "aiff": true, "flac": true, "m4a": true, "mp3": true,
+	"mp4": true, "ogg": true, "opus": true, "wav": true, "webm": true,
+}
+
+// sessionFile is a recording of a take
+type sessionFile struct {
+	Path string
+	// Part is what the file name says after the take number, e.g. vocal
+	Part string
+}
+
+// sessionTake is the recordings of one take, ordered by part
+type sessionTake struct {
+	Number int
+	Files  []sessionFile
+}
+
+// sessionTakes groups the recordings of a session directory by take number,
+// in take order. Audio files without a take number are returned as skipped.
+func sessionTakes(paths []string) (takes []sessionTake, skipped []string) {
+	byNumber := map[int]*sessionTake{}
+	for _, path := range paths {
+		if !sessionAudioExtensions[getFileExtension(path)] {
+			continue
+		}
+		match := sessionTakePattern.FindStringSubmatch(fileStem(path))
+		if match == nil {
+			skipped = append(skipped, path)
+			continue
+		}
+		number, _ := strconv.Atoi(match[1])
+		take, ok := byNumber[number]
+		if !ok {
+			take = &sessionTake{Number: number}
+			byNumber[number] = take
+		}
+		part := strings.TrimSpace(strings.NewReplacer("_", " ", "-", " ").Replace(match[2]))
+		take.Files = append(take.Files, sessionFile{Path: path, Part: part})
+	}
+
+	for _, take := range byNumber {
+		sort.Slice(take.Files, func(i, j int) bool {
+			a, b := take.Files[i], take.Files[j]
+			if a.Part != b.Part {
+				return a.Part < b.Part
+			}
+			return a.Path < b.Path
+		})
+		takes = append(takes, *take)
+	}
+	sort.Slice(takes, func(i, j int) bool { return takes[i].Number < takes[j].Number })
+	return takes, skipped
+}
+
+// renderSessionLog formats the transcripts of a session as Markdown with a
+// heading per take, and one per part if the take has several recordings.
+// Recordings missing from transcripts are marked as failed.
+func renderSessionLog(title string, takes []sessionTake, transcripts map[string]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Session: %s\n", title)
+	for _, take := range takes {
+		fmt.Fprintf(&b, "\n## Take %d\n", take.Number)
+		for _, file := range take.Files {
+			if len(take.Files) > 1 || file.Part != "" {
+				heading := file.Part
+				if heading == "" {
+					heading = filepath.Base(file.Path)
+				}
+				fmt.Fprintf(&b, "\n### %s\n", heading)
+			}
+			text, ok := transcripts[file.Path]
+			switch {
+			case !ok:
+				text = "_Transcription failed_"
+			case strings.TrimSpace(text) == "":
+				text = "_No speech_"
+			}
+			fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(text))
+		}
+	}
+	return b.String()
+}
+
+// sessionLogFileName returns the path of a session's log, named after the
+// directory unless --output-name is given
+func sessionLogFileName(args Args) string {
+	stem := args.OutputName
+	if stem == "" {
+		abs, err := filepath.Abs(args.Session)
+		if err != nil {
+			abs = args.Session
+		}
+		stem = filepath.Base(abs)
+	}
+	return filepath.Join(args.OutputDir, fitFileName(stem, ".session.md"))
+}
+
+// sessionSidecarFlag returns the first option set that saves a file of its
+// own next to a transcript, or an empty string. --session only keeps the log,
+// so those files would be lost with the takes' transcripts.
+func sessionSidecarFlag(args Args) string {
+	flags := []struct {
+		name string
+		set  bool
+	}{
+		{"--meeting-minutes", args.Minutes},
+		{"--entities", args.Entities},
+		{"--interview", args.Interview},
+		{"--anonymize", args.Anonymize},
+		{"--anki", args.Anki},
+		{"--bilingual", args.Bilingual != ""},
+		{"--label-studio", args.LabelStudio},
+		{"--qa-scorecard", args.QAScorecard != ""},
+		{"--keep-raw", args.KeepRaw},
+	}
+	for _, flag := range flags {
+		if flag.set {
+			return flag.name
+		}
+	}
+	return ""
+}
+
+// runSession transcribes the takes of a studio session directory, each
+// recording with a separate pindar process like --manifest, and compiles the
+// transcripts into a single Markdown log in take order
+func runSession(args Args, argv []string) {
+	entries, err := os.ReadDir(args.Session)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading session: %v\n"), err)
+		os.Exit(1)
+	}
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			paths = append(paths, filepath.Join(args.Session, entry.Name()))
+		}
+	}
+	takes, skipped := sessionTakes(paths)
+	for _, path := range skipped {
+		uiPrintf(tr("⚠️  Skipping %s, its name has no take number\n"), filepath.Base(path))
+	}
+	if len(takes) == 0 {
+		uiPrintf(tr("❌ No takes found in %s (expected names like take_03_vocal.wav)\n"), args.Session)
+		os.Exit(1)
+	}
+
+	if args.OutputDir != "" {
+		if args.OutputDir, err = expandOutputDir(args.OutputDir, time.Now()); err == nil {
+			err = ensureOutputDir(args.OutputDir, !args.NoCreateDir)
+		}
+		if err != nil {
+			uiPrintf("❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		uiPrintf(tr("❌ Error running session: %v\n"), err)
+		os.Exit(1)
+	}
+	tmpDir, err := os.MkdirTemp("", "pindar_session")
+	if err != nil {
+		uiPrintf(tr("❌ Error running session: %v\n"), err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Ask for the API key once instead of in every process
+	env := os.Environ()
+	if args.Provider != providerFake && args.ReplayCassette == "" {
+		apiKey, err := getAPIKey(args.APIKey)
+		if err != nil {
+			uiPrintf(tr(" Error getting API key: %v\n"), err)
+			os.Exit(1)
+		}
+		env = append(env, "OPENAI_API_KEY="+apiKey)
+	}
+
+	// The takes are transcribed as text into the temporary directory and only
+	// the log is saved
+	options := argv
+	for _, flag := range []string{"--session", "--summary", "--api-key", "--format", "--output-dir", "-o", "--output-ext", "--output-name"} {
+		options = withoutFlag(options, flag)
+	}
+	options = append(options, "--format", "text", "--output-dir", tmpDir)
+
+	var files []sessionFile
+	for _, take := range takes {
+		files = append(files, take.Files...)
+	}
+	transcripts := map[string]string{}
+	var summary batchSummary
+	for i, file := range files {
+		uiPrintf("\n[%d/%d] %s\n", i+1, len(files), filepath.Base(file.Path))
+
+		name := strconv.Itoa(i + 1)
+		cmd := exec.Command(executable, append(options, "--output-name", name, "--", file.Path)...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		cmd.Env = env
+		report, elapsed, err := runBatchJob(cmd, tmpDir)
+		if err != nil {
+			summary.addFailure(file.Path, err.Error())
+			continue
+		}
+		text, err := os.ReadFile(filepath.Join(tmpDir, name+".txt"))
+		if err != nil {
+			uiPrintf(tr("❌ Error reading transcript: %v\n"), err)
+			summary.addFailure(file.Path, err.Error())
+			continue
+		}
+		summary.addTranscribed(report, elapsed)
+		transcripts[file.Path] = string(text)
+	}
+
+	logFile := sessionLogFileName(args)
+	title := strings.TrimSuffix(filepath.Base(logFile), ".session.md")
+	if err := os.WriteFile(logFile, []byte(renderSessionLog(title, takes, transcripts)), 0644); err != nil {
+		uiPrintf(tr("❌ Error writing session log: %v\n"), err)
+		os.Exit(1)
+	}
+	uiPrintf(tr("\n💾 Session log of %d takes saved to: %s\n"), len(takes), logFile)
+	finishBatch(&summary, args.Summary)
+}
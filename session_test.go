@@ -0,0 +1,69 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSessionTakes(t *testing.T) {
+	paths := []string{
+		"s/take_10_vocal.wav",
+		"s/take_03_vocal.wav",
+		"s/Take 3 - Guitar.flac",
+		"s/take_03_vocal.txt",
+		"s/take_3.wav.pindar.yaml",
+		"s/room tone.wav",
+		"s/take7.mp3",
+		"s/mistake_2.wav",
+	}
+	takes, skipped := sessionTakes(paths)
+
+	expected := []sessionTake{
+		{Number: 3, Files: []sessionFile{{Path: "s/Take 3 - Guitar.flac", Part: "Guitar"}, {Path: "s/take_03_vocal.wav", Part: "vocal"}}},
+		{Number: 7, Files: []sessionFile{{Path: "s/take7.mp3"}}},
+		{Number: 10, Files: []sessionFile{{Path: "s/take_10_vocal.wav", Part: "vocal"}}},
+	}
+	if !reflect.DeepEqual(takes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, takes)
+	}
+	if expected := []string{"s/room tone.wav", "s/mistake_2.wav"}; !slices.Equal(skipped, expected) {
+		t.Errorf("Expected %v to be skipped, got %v", expected, skipped)
+	}
+}
+
+func TestRenderSessionLog(t *testing.T) {
+	takes := []sessionTake{
+		{Number: 1, Files: []sessionFile{{Path: "take_1.wav"}}},
+		{Number: 2, Files: []sessionFile{{Path: "take_2_bass.wav", Part: "bass"}, {Path: "take_2_vocal.wav", Part: "vocal"}}},
+	}
+	transcripts := map[string]string{"take_1.wav": "One, two.\n", "take_2_bass.wav": " "}
+	got := renderSessionLog("Album", takes, transcripts)
+	expected := "# Session: Album\n" +
+		"\n## Take 1\n\nOne, two.\n" +
+		"\n## Take 2\n\n### bass\n\n_No speech_\n\n### vocal\n\n_Transcription failed_\n"
+	if got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestSessionLogFileName(t *testing.T) {
+	args := Args{Session: filepath.Join("sessions", "2024-05-01 Band") + string(filepath.Separator), OutputDir: "logs"}
+	if got, expected := sessionLogFileName(args), filepath.Join("logs", "2024-05-01 Band.session.md"); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+	args.OutputName = "day1"
+	if got, expected := sessionLogFileName(args), filepath.Join("logs", "day1.session.md"); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestSessionSidecarFlag(t *testing.T) {
+	if flag := sessionSidecarFlag(Args{Language: "en", Format: "srt"}); flag != "" {
+		t.Errorf("Expected no sidecar option, got %q", flag)
+	}
+	if flag := sessionSidecarFlag(Args{Entities: true, KeepRaw: true}); flag != "--entities" {
+		t.Errorf("Expected --entities, got %q", flag)
+	}
+}
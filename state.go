@@ -0,0 +1,249 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ExportStateArgs defines the arguments of the export-state subcommand
+type ExportStateArgs struct {
+	Archive string `arg:"positional,required" help:"Archive to write, e.g. pindar-state.tar.gz, or pindar-state.tar.zst for zstd"`
+}
+
+// ImportStateArgs defines the arguments of the import-state subcommand
+type ImportStateArgs struct {
+	Archive string `arg:"positional,required" help:"Archive written by pindar export-state"`
+	Force   bool   `arg:"--force" help:"Replace files that already exist on this machine"`
+}
+
+// zstdMagic starts every zstd-compressed file
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// maxStateFileSize guards import-state against archives that aren't pindar's
+const maxStateFileSize = 64 << 20
+
+// stateFile is a file of pindar's state, named relative to the config directory
+type stateFile struct {
+	Name    string
+	Content []byte
+	ModTime time.Time
+}
+
+// collectState reads every file in the config directory: the config with its
+// routing rules, ffmpeg settings and macros, and the corrections log. The data
+// directory is left out; its ffmpeg build only runs on this platform.
+func collectState(dir string) ([]stateFile, error) {
+	var files []stateFile
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		name, _ := filepath.Rel(dir, path)
+		files = append(files, stateFile{Name: filepath.ToSlash(name), Content: content, ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// writeStateArchive writes the files as a gzip-compressed tar archive
+func writeStateArchive(w io.Writer, files []stateFile) error {
+	return writeTarArchive(gzip.NewWriter(w), files)
+}
+
+// writeZstdStateArchive writes the files as a zstd-compressed tar archive
+func writeZstdStateArchive(w io.Writer, files []stateFile) error {
+	encoder, err := zstd.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	return writeTarArchive(encoder, files)
+}
+
+// writeTarArchive writes the files as a tar archive into a compressor and closes it
+func writeTarArchive(compressor io.WriteCloser, files []stateFile) error {
+	tw := tar.NewWriter(compressor)
+	for _, file := range files {
+		header := &tar.Header{Name: file.Name, Mode: 0600, Size: int64(len(file.Content)), ModTime: file.ModTime, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(file.Content); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return compressor.Close()
+}
+
+// readStateArchive reads the files of an archive written by writeStateArchive
+// or writeZstdStateArchive, telling them apart by their first bytes
+func readStateArchive(r io.Reader) ([]stateFile, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(len(zstdMagic)); bytes.Equal(magic, zstdMagic) {
+		decoder, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		defer decoder.Close()
+		return readArchiveFiles(decoder, maxStateFileSize)
+	}
+
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, errors.New(tr("not a pindar state archive (expected a .tar.gz or .tar.zst written by pindar export-state)"))
+	}
+	defer gz.Close()
+	return readArchiveFiles(gz, maxStateFileSize)
+}
+
+// readArchiveFiles reads the files of a tar archive, rejecting names that
+// would end up outside the directory they are restored to and files larger
+// than maxSize
+func readArchiveFiles(r io.Reader, maxSize int64) ([]stateFile, error) {
+	var files []stateFile
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		if header.Typeflag != tar.TypeReg || !filepath.IsLocal(filepath.FromSlash(header.Name)) || header.Size > maxSize {
+			return nil, fmt.Errorf(tr("the archive contains an unexpected entry %q"), header.Name)
+		}
+		content, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from archive: %w", header.Name, err)
+		}
+		files = append(files, stateFile{Name: header.Name, Content: content, ModTime: header.ModTime})
+	}
+}
+
+// conflictingStateFiles returns the names of files that exist in dir with different content
+func conflictingStateFiles(dir string, files []stateFile) []string {
+	var conflicts []string
+	for _, file := range files {
+		existing, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file.Name)))
+		if err == nil && !bytes.Equal(existing, file.Content) {
+			conflicts = append(conflicts, file.Name)
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// restoreState writes the files into dir. They hold the API key, so only the user may read them.
+func restoreState(dir string, files []stateFile) error {
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, file.Content, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// runExportState archives pindar's state to move it to another machine
+func runExportState(argv []string) {
+	var args ExportStateArgs
+	parseSubcommand("export-state", &args, argv)
+
+	dir, err := getConfigDir()
+	if err != nil {
+		uiPrintf(tr("❌ Error exporting state: %v\n"), err)
+		os.Exit(1)
+	}
+	files, err := collectState(dir)
+	if err != nil {
+		uiPrintf(tr("❌ Error exporting state: %v\n"), err)
+		os.Exit(1)
+	}
+	if len(files) == 0 {
+		uiPrintf(tr("Nothing to export, %s is empty.\n"), dir)
+		return
+	}
+
+	// The archive is compressed with zstd if its name asks for it
+	write := writeStateArchive
+	if strings.HasSuffix(args.Archive, ".zst") {
+		write = writeZstdStateArchive
+	}
+	var buf bytes.Buffer
+	if err := write(&buf, files); err != nil {
+		uiPrintf(tr("❌ Error exporting state: %v\n"), err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(args.Archive, buf.Bytes(), 0600); err != nil {
+		uiPrintf(tr("❌ Error exporting state: %v\n"), err)
+		os.Exit(1)
+	}
+	uiPrintf(tr("💾 State (%d files) saved to: %s\n"), len(files), args.Archive)
+	uiPrintln(tr("⚠️  The archive contains your API key if it is saved in the config file, keep it private."))
+}
+
+// runImportState restores state written by export-state into the config directory
+func runImportState(argv []string) {
+	var args ImportStateArgs
+	parseSubcommand("import-state", &args, argv)
+
+	f, err := os.Open(args.Archive)
+	if err != nil {
+		uiPrintf(tr("❌ Error importing state: %v\n"), err)
+		os.Exit(1)
+	}
+	files, err := readStateArchive(f)
+	f.Close()
+	if err != nil {
+		uiPrintf(tr("❌ Error importing state: %v\n"), err)
+		os.Exit(1)
+	}
+
+	dir, err := getConfigDir()
+	if err != nil {
+		uiPrintf(tr("❌ Error importing state: %v\n"), err)
+		os.Exit(1)
+	}
+	if conflicts := conflictingStateFiles(dir, files); len(conflicts) > 0 && !args.Force {
+		uiPrintf(tr("❌ These files already exist in %s: %s (use --force to replace them)\n"), dir, strings.Join(conflicts, ", "))
+		os.Exit(1)
+	}
+	if err := restoreState(dir, files); err != nil {
+		uiPrintf(tr("❌ Error importing state: %v\n"), err)
+		os.Exit(1)
+	}
+	uiPrintf(tr("✅ Imported %d files into %s\n"), len(files), dir)
+}
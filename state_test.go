@@ -0,0 +1,76 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStateArchiveRoundTrip(t *testing.T) {
+	source := t.TempDir()
+	os.WriteFile(filepath.Join(source, "config.json"), []byte(`{"openai_api_key":"sk-test"}`), 0600)
+	os.WriteFile(filepath.Join(source, "corrections.jsonl"), []byte("{}\n"), 0600)
+
+	files, err := collectState(source)
+	if err != nil || len(files) != 2 {
+		t.Fatalf("collectState() = %d files, %v", len(files), err)
+	}
+	var buf bytes.Buffer
+	if err := writeStateArchive(&buf, files); err != nil {
+		t.Fatalf("writeStateArchive() failed: %v", err)
+	}
+	restored, err := readStateArchive(&buf)
+	if err != nil || len(restored) != 2 {
+		t.Fatalf("readStateArchive() = %d files, %v", len(restored), err)
+	}
+
+	target := t.TempDir()
+	os.WriteFile(filepath.Join(target, "config.json"), []byte(`{}`), 0600)
+	if conflicts := conflictingStateFiles(target, restored); len(conflicts) != 1 || conflicts[0] != "config.json" {
+		t.Errorf("Expected config.json to conflict, got %v", conflicts)
+	}
+	if err := restoreState(target, restored); err != nil {
+		t.Fatalf("restoreState() failed: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(target, "config.json")); string(content) != `{"openai_api_key":"sk-test"}` {
+		t.Errorf("Expected the config to be restored, got %q", content)
+	}
+	if conflicts := conflictingStateFiles(target, restored); len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts after restoring, got %v", conflicts)
+	}
+}
+
+func TestReadStateArchiveRejectsEscapingNames(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "../evil.json", Mode: 0600, Size: 2, Typeflag: tar.TypeReg})
+	tw.Write([]byte("{}"))
+	tw.Close()
+	gz.Close()
+
+	if _, err := readStateArchive(&buf); err == nil {
+		t.Error("Expected an entry outside the config directory to be rejected")
+	}
+	if _, err := readStateArchive(bytes.NewReader([]byte("not gzip"))); err == nil {
+		t.Error("Expected a file that isn't gzip-compressed to be rejected")
+	}
+}
+
+func TestZstdStateArchive(t *testing.T) {
+	files := []stateFile{{Name: "config.json", Content: []byte(`{"format":"srt"}`)}}
+	var buf bytes.Buffer
+	if err := writeZstdStateArchive(&buf, files); err != nil {
+		t.Fatalf("writeZstdStateArchive() failed: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), zstdMagic) {
+		t.Fatal("Expected a zstd-compressed archive")
+	}
+	restored, err := readStateArchive(&buf)
+	if err != nil || len(restored) != 1 || string(restored[0].Content) != `{"format":"srt"}` {
+		t.Errorf("readStateArchive() = %v, %v", restored, err)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// sentiments are the sentiment labels assigned with --tag-segments
+var sentiments = []string{"positive", "neutral", "negative"}
+
+// segmentTags are the labels the analysis model assigns to one segment
+type segmentTags struct {
+	Segment   int      `json:"segment"`
+	Sentiment string   `json:"sentiment"`
+	Topics    []string `json:"topics"`
+}
+
+// tagInstructions builds the system prompt for tagging, restricting the topics
+// to the given labels when there are any
+func tagInstructions(topics []string) string {
+	instructions := `You label transcript segments for quality assurance. The user sends numbered transcript segments. ` +
+		`Return JSON of the form {"segments": [{"segment": ..., "sentiment": ..., "topics": [...]}]} with one entry ` +
+		`per segment. "sentiment" is one of ` + strings.Join(sentiments, ", ") + ` and "topics" lists short lowercase ` +
+		`topic labels for what the segment is about.`
+	if len(topics) > 0 {
+		instructions += ` Only use these topic labels: ` + strings.Join(topics, ", ") + `. Leave "topics" empty if none fits.`
+	}
+	return instructions
+}
+
+// tagSegments asks the analysis model for the sentiment and topics of every segment
+func tagSegments(ctx context.Context, client openai.Client, args Args, transcript *Transcript) error {
+	instructions := tagInstructions(args.Topics)
+	for start := 0; start < len(transcript.Segments); start += analysisBatchSize {
+		end := min(start+analysisBatchSize, len(transcript.Segments))
+
+		var answer struct {
+			Segments []segmentTags `json:"segments"`
+		}
+		if err := chatJSON(ctx, client, args.AnalysisModel, instructions, numberedSegments(transcript.Segments[start:end]), &answer); err != nil {
+			return err
+		}
+		applySegmentTags(transcript.Segments[start:end], answer.Segments, args.Topics)
+	}
+	return nil
+}
+
+// applySegmentTags stores the tags on the segments, ignoring unknown segment
+// numbers, sentiments and (when restricted) topics
+func applySegmentTags(segments []Segment, tags []segmentTags, allowedTopics []string) {
+	for _, tag := range tags {
+		if tag.Segment < 0 || tag.Segment >= len(segments) {
+			continue
+		}
+		segment := &segments[tag.Segment]
+
+		if sentiment := strings.ToLower(strings.TrimSpace(tag.Sentiment)); slices.Contains(sentiments, sentiment) {
+			segment.Sentiment = sentiment
+		}
+		segment.Topics = nil
+		for _, topic := range tag.Topics {
+			topic = strings.ToLower(strings.TrimSpace(topic))
+			if topic == "" || slices.Contains(segment.Topics, topic) {
+				continue
+			}
+			if len(allowedTopics) > 0 && !slices.Contains(allowedTopics, topic) {
+				continue
+			}
+			segment.Topics = append(segment.Topics, topic)
+		}
+	}
+}
+
+// tagTranscripts tags the segments of each transcript, printing progress
+func tagTranscripts(ctx context.Context, client openai.Client, args Args, transcripts []*Transcript) error {
+	uiPrintf(tr(" Tagging segments with %s...\n"), args.AnalysisModel)
+	for _, transcript := range transcripts {
+		if err := tagSegments(ctx, client, args, transcript); err != nil {
+			return fmt.Errorf("failed to tag segments: %w", err)
+		}
+	}
+	return nil
+}
+
+// normalizeTopics lowercases the --topics labels and drops empty ones
+func normalizeTopics(topics []string) []string {
+	var normalized []string
+	for _, topic := range topics {
+		if topic = strings.ToLower(strings.TrimSpace(topic)); topic != "" {
+			normalized = append(normalized, topic)
+		}
+	}
+	return normalized
+}
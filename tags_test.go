@@ -0,0 +1,40 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestApplySegmentTags(t *testing.T) {
+	segments := []Segment{{Text: " I was charged twice."}, {Text: " Thanks, that fixed it!"}}
+	tags := []segmentTags{
+		{Segment: 0, Sentiment: "Negative", Topics: []string{"Billing", "billing", "weather"}},
+		{Segment: 1, Sentiment: "ecstatic", Topics: []string{"refund"}},
+		{Segment: 5, Sentiment: "positive"},
+	}
+
+	applySegmentTags(segments, tags, []string{"billing", "refund"})
+
+	if segments[0].Sentiment != "negative" {
+		t.Errorf("Expected %q, got %q", "negative", segments[0].Sentiment)
+	}
+	if !slices.Equal(segments[0].Topics, []string{"billing"}) {
+		t.Errorf("Expected [billing], got %v", segments[0].Topics)
+	}
+	if segments[1].Sentiment != "" {
+		t.Errorf("Expected unknown sentiment to be dropped, got %q", segments[1].Sentiment)
+	}
+	if !slices.Equal(segments[1].Topics, []string{"refund"}) {
+		t.Errorf("Expected [refund], got %v", segments[1].Topics)
+	}
+}
+
+func TestTagInstructions(t *testing.T) {
+	if strings.Contains(tagInstructions(nil), "Only use these topic labels") {
+		t.Error("Expected unrestricted topics without --topics")
+	}
+	if !strings.Contains(tagInstructions([]string{"billing", "refund"}), "billing, refund") {
+		t.Error("Expected the --topics labels in the instructions")
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/openai/openai-go"
+)
+
+// telephonyFilter is the ffmpeg filter of the phone-call preset. Phone audio
+// only carries 300-3400 Hz, so everything outside that band is noise, and the
+// 8 kHz recording is upsampled to the 16 kHz the models work at.
+const telephonyFilter = "highpass=f=300,lowpass=f=3400,aresample=16000"
+
+// telephonyCodecs are the G.711 codecs phone systems record calls with
+var telephonyCodecs = []string{"pcm_mulaw", "pcm_alaw"}
+
+// defaultCallLegs label the channels of a stereo call recording, left first
+var defaultCallLegs = []string{"Agent", "Customer"}
+
+// isTelephonyStream reports whether a stream is 8 kHz G.711 phone audio
+func isTelephonyStream(s probeStream) bool {
+	return slices.Contains(telephonyCodecs, s.CodecName) && s.SampleRate == "8000"
+}
+
+// shouldApplyTelephony decides from the --telephony mode whether to apply the
+// phone-call preset to the selected audio track
+func shouldApplyTelephony(mode, path string, track int) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	probe, err := probeAudio(path)
+	if err != nil {
+		return false
+	}
+	audio := probe.streamsOfType("audio")
+	index := max(track, 1) - 1
+	return index < len(audio) && isTelephonyStream(audio[index])
+}
+
+// callChannelFilter returns the ffmpeg filter extracting one channel of a
+// stereo call, followed by the phone-call preset if requested
+func callChannelFilter(channel int, telephony bool) string {
+	filter := fmt.Sprintf("pan=mono|c0=c%d", channel)
+	if telephony {
+		filter += "," + telephonyFilter
+	}
+	return filter
+}
+
+// callLegNames returns the labels of the two call legs: the first two
+// --speakers if given, otherwise Agent and Customer
+func callLegNames(speakers []string) []string {
+	if len(speakers) >= 2 {
+		return speakers[:2]
+	}
+	return defaultCallLegs
+}
+
+// transcribeCallLegs transcribes the two channels of a stereo call recording
+// separately and interleaves them into one transcript labeled by speaker
+func transcribeCallLegs(ctx context.Context, client openai.Client, args Args, path string, track int, telephony bool) (*Transcript, error) {
+	if probe, err := probeAudio(path); err == nil {
+		audio := probe.streamsOfType("audio")
+		if index := max(track, 1) - 1; index < len(audio) && audio[index].Channels != 2 {
+			return nil, fmt.Errorf(tr("--split-call needs a stereo recording, but %s has %d channel(s)"), filepath.Base(path), audio[index].Channels)
+		}
+	}
+
+	names := callLegNames(args.Speakers)
+	legs := make([]*Transcript, len(names))
+	for channel, name := range names {
+		uiPrintf(" [%d/%d] %s\n", channel+1, len(names), name)
+		converted, cleanup, err := convertToMP4(ctx, path, track, callChannelFilter(channel, telephony))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		legs[channel], err = transcribe(ctx, client, args, converted, filepath.Base(converted))
+		cleanup()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return combineCallLegs(legs, names), nil
+}
+
+// combineCallLegs interleaves the segments of the call legs by time and
+// labels them with the speaker
+func combineCallLegs(legs []*Transcript, names []string) *Transcript {
+	combined := &Transcript{}
+	for i, leg := range legs {
+		if combined.Language == "" {
+			combined.Language = leg.Language
+		}
+		combined.Task = leg.Task
+		combined.Duration = max(combined.Duration, leg.Duration)
+		for _, segment := range leg.Segments {
+			segment.Speaker = names[i]
+			combined.Segments = append(combined.Segments, segment)
+		}
+		combined.Words = append(combined.Words, leg.Words...)
+	}
+	sort.SliceStable(combined.Segments, func(i, j int) bool { return combined.Segments[i].Start < combined.Segments[j].Start })
+	sort.SliceStable(combined.Words, func(i, j int) bool { return combined.Words[i].Start < combined.Words[j].Start })
+
+	for i := range combined.Segments {
+		combined.Segments[i].ID = i
+	}
+	combined.Text = segmentsText(combined.Segments)
+	return combined
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsTelephonyStream(t *testing.T) {
+	tests := []struct {
+		stream   probeStream
+		expected bool
+	}{
+		{probeStream{CodecName: "pcm_mulaw", SampleRate: "8000"}, true},
+		{probeStream{CodecName: "pcm_alaw", SampleRate: "8000"}, true},
+		{probeStream{CodecName: "pcm_mulaw", SampleRate: "16000"}, false},
+		{probeStream{CodecName: "pcm_s16le", SampleRate: "8000"}, false},
+	}
+	for _, test := range tests {
+		if result := isTelephonyStream(test.stream); result != test.expected {
+			t.Errorf("isTelephonyStream(%s at %s Hz) = %v, expected %v", test.stream.CodecName, test.stream.SampleRate, result, test.expected)
+		}
+	}
+}
+
+func TestShouldApplyTelephonyModes(t *testing.T) {
+	if !shouldApplyTelephony("always", "/nonexistent.wav", 0) {
+		t.Error("Expected always to apply the preset")
+	}
+	if shouldApplyTelephony("never", "/nonexistent.wav", 0) || shouldApplyTelephony("auto", "/nonexistent.wav", 0) {
+		t.Error("Expected never, and auto on an unreadable file, to skip the preset")
+	}
+}
+
+func TestCallChannelFilter(t *testing.T) {
+	if filter := callChannelFilter(1, false); filter != "pan=mono|c0=c1" {
+		t.Errorf("Unexpected filter %q", filter)
+	}
+	if filter := callChannelFilter(0, true); filter != "pan=mono|c0=c0,"+telephonyFilter {
+		t.Errorf("Unexpected filter %q", filter)
+	}
+}
+
+func TestCallLegNames(t *testing.T) {
+	if names := callLegNames(nil); strings.Join(names, ",") != "Agent,Customer" {
+		t.Errorf("Expected the default names, got %v", names)
+	}
+	if names := callLegNames([]string{"Ana", "Ben", "Cy"}); strings.Join(names, ",") != "Ana,Ben" {
+		t.Errorf("Expected the first two speakers, got %v", names)
+	}
+}
+
+func TestCombineCallLegs(t *testing.T) {
+	agent := &Transcript{Language: "english", Duration: 10, Segments: []Segment{
+		{Start: 0, End: 2, Text: " Hello, how can I help?"},
+		{Start: 6, End: 8, Text: " Sure."},
+		{Start: 8, End: 9, Text: " One moment."},
+	}}
+	customer := &Transcript{Language: "english", Duration: 11, Segments: []Segment{
+		{Start: 2.5, End: 5, Text: " My order is late."},
+	}}
+
+	combined := combineCallLegs([]*Transcript{agent, customer}, []string{"Agent", "Customer"})
+	expected := "Agent: Hello, how can I help?\n\nCustomer: My order is late.\n\nAgent: Sure. One moment."
+	if combined.Text != expected {
+		t.Errorf("Expected text %q, got %q", expected, combined.Text)
+	}
+	if len(combined.Segments) != 4 || combined.Segments[1].Speaker != "Customer" || combined.Segments[1].ID != 1 {
+		t.Errorf("Expected the segments interleaved by time, got %+v", combined.Segments)
+	}
+	if combined.Duration != 11 {
+		t.Errorf("Expected the longer duration, got %v", combined.Duration)
+	}
+
+	merged := mergeSegments(combined.Segments, MergeOptions{MaxPause: 10})
+	if len(merged) != 3 || merged[2].Speaker != "Agent" || strings.TrimSpace(merged[2].Text) != "Sure. One moment." {
+		t.Errorf("Expected merging to stop at speaker changes, got %+v", merged)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/openai/openai-go/option"
+)
+
+// A transcription request may take a fixed allowance for the upload plus the
+// duration of the audio itself. Transcription is much faster than real time,
+// so this only cuts off requests that have stalled.
+const (
+	baseTranscriptionTimeout = 5 * time.Minute
+	// assumedBytesPerSecond estimates the duration of files ffprobe can't read;
+	// 32 kbit/s is below the bitrate of nearly all speech recordings
+	assumedBytesPerSecond = 4000
+)
+
+// progressInterval is how often a running request reports that it is still alive
+var progressInterval = 30 * time.Second
+
+// transcriptionTimeout returns the time allowed for transcribing a file
+func transcriptionTimeout(path string) time.Duration {
+	duration, err := probeDuration(path)
+	if err != nil {
+		info, statErr := os.Stat(path)
+		if statErr != nil {
+			return baseTranscriptionTimeout
+		}
+		duration = float64(info.Size()) / assumedBytesPerSecond
+	}
+	return baseTranscriptionTimeout + time.Duration(duration*float64(time.Second))
+}
+
+// requestProgress tracks how much of a request body has been sent
+type requestProgress struct {
+	sent  atomic.Int64
+	total atomic.Int64
+}
+
+// countingReader counts the bytes read from a request body
+type countingReader struct {
+	io.ReadCloser
+	progress *requestProgress
+}
+
+func (r countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.progress.sent.Add(int64(n))
+	return n, err
+}
+
+// middleware counts the bytes of the request body as they are sent. Retries
+// start counting from zero again.
+func (p *requestProgress) middleware(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	p.sent.Store(0)
+	p.total.Store(req.ContentLength)
+	if req.Body != nil {
+		req.Body = countingReader{req.Body, p}
+	}
+	return next(req)
+}
+
+// report prints a message every progressInterval until stop is called, so a
+// long upload or transcription doesn't look like it hangs
+func (p *requestProgress) report(timeout time.Duration) (stop func()) {
+	start := time.Now()
+	ticker := time.NewTicker(progressInterval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				elapsed := time.Since(start).Round(time.Second)
+				if sent, total := p.sent.Load(), p.total.Load(); sent < total {
+					uiPrintf(tr(" Uploading: %.1f of %.1f MB (%s elapsed)\n"), float64(sent)/1e6, float64(total)/1e6, elapsed)
+				} else {
+					uiPrintf(tr(" Waiting for the transcription (%s elapsed, giving up after %s)\n"), elapsed, timeout.Round(time.Second))
+				}
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/option"
+)
+
+func TestTranscriptionTimeoutFromFileSize(t *testing.T) {
+	// Not real audio, so the duration is estimated from the size
+	path := filepath.Join(t.TempDir(), "talk.mp3")
+	if err := os.WriteFile(path, make([]byte, 3600*assumedBytesPerSecond), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := transcriptionTimeout(path); got != baseTranscriptionTimeout+time.Hour {
+		t.Errorf("Expected an hour on top of the base timeout, got %s", got)
+	}
+	if got := transcriptionTimeout(filepath.Join(t.TempDir(), "missing.mp3")); got != baseTranscriptionTimeout {
+		t.Errorf("Expected the base timeout for a missing file, got %s", got)
+	}
+}
+
+func TestRequestProgressCountsBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+	}))
+	defer server.Close()
+
+	progress := &requestProgress{}
+	req, _ := http.NewRequest("POST", server.URL, strings.NewReader(strings.Repeat("a", 5000)))
+	var next option.MiddlewareNext = http.DefaultClient.Do
+	res, err := progress.middleware(req, next)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	res.Body.Close()
+
+	if progress.sent.Load() != 5000 || progress.total.Load() != 5000 {
+		t.Errorf("Expected 5000 of 5000 bytes sent, got %d of %d", progress.sent.Load(), progress.total.Load())
+	}
+}
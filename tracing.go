@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/openai/openai-go/option"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters of --trace-exporter, named as in OTEL_TRACES_EXPORTER
+const (
+	traceExporterNone    = "none"
+	traceExporterOTLP    = "otlp"
+	traceExporterConsole = "console"
+)
+
+// tracer creates pindar's spans. It does nothing until setupTracing installs
+// an exporter, so spans cost nothing while tracing is off.
+var tracer = otel.Tracer("github.com/richartkeil/pindar")
+
+// envTraceExporter picks the exporter of OTEL_TRACES_EXPORTER. Other
+// OpenTelemetry programs read the variable as well, so it may list several
+// exporters, like "otlp,console", or ones pindar doesn't have, like "zipkin".
+// Rather than failing every run, pindar uses the first exporter it has and
+// returns the others it ignores.
+func envTraceExporter(value string) (string, []string) {
+	exporter := ""
+	var ignored []string
+	for _, name := range strings.Split(value, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "":
+		case traceExporterNone, traceExporterOTLP, traceExporterConsole:
+			if exporter == "" {
+				exporter = name
+			} else if name != exporter {
+				ignored = append(ignored, name)
+			}
+		default:
+			ignored = append(ignored, name)
+		}
+	}
+	return exporter, ignored
+}
+
+// setupTracing installs the exporter chosen with --trace-exporter and returns
+// the function that shuts it down. Spans are exported as they end instead of
+// in batches, so the finished ones aren't lost when pindar exits on an error.
+func setupTracing(ctx context.Context, exporter string) (func(context.Context) error, error) {
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case "", traceExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case traceExporterOTLP:
+		// The endpoint and headers come from the OTEL_EXPORTER_OTLP_* variables
+		spanExporter, err = otlptracehttp.New(ctx)
+	case traceExporterConsole:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr), stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf(tr("unknown trace exporter %q, use %s, %s or %s"), exporter, traceExporterOTLP, traceExporterConsole, traceExporterNone)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults
+	res, err := resource.New(ctx,
+		resource.WithAttributes(attribute.String("service.name", "pindar")),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// traceParentContext returns a context continuing the trace of the
+// TRACEPARENT and TRACESTATE environment variables, so the spans of a pindar
+// run started by a traced backend job show up in the job's trace
+func traceParentContext() context.Context {
+	carrier := propagation.MapCarrier{"traceparent": os.Getenv("TRACEPARENT"), "tracestate": os.Getenv("TRACESTATE")}
+	return propagation.TraceContext{}.Extract(context.Background(), carrier)
+}
+
+// endSpan marks the span as failed if there is an error, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// sentBody adds an event to a request's span once its body is sent, which
+// separates the upload from the time the API takes to answer
+type sentBody struct {
+	io.ReadCloser
+	span trace.Span
+	size int64
+	sent bool
+}
+
+func (b *sentBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if err == io.EOF && !b.sent {
+		b.sent = true
+		b.span.SetAttributes(attribute.Int64("http.request.body.size", b.size))
+		b.span.AddEvent("request sent")
+	}
+	return n, err
+}
+
+// traceMiddleware wraps every API request in a span, which ends when the
+// response headers arrive
+func traceMiddleware(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	ctx, span := tracer.Start(req.Context(), req.Method+" "+req.URL.Path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("url.path", req.URL.Path),
+		))
+	req = req.WithContext(ctx)
+	if req.Body != nil {
+		req.Body = &sentBody{ReadCloser: req.Body, span: span}
+	}
+
+	res, err := next(req)
+	if res != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+		if err == nil && res.StatusCode >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, res.Status)
+		}
+	}
+	endSpan(span, err)
+	return res, err
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go/option"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetupTracing(t *testing.T) {
+	shutdown, err := setupTracing(context.Background(), traceExporterNone)
+	if err != nil || shutdown(context.Background()) != nil {
+		t.Errorf("Expected tracing to be off without an error, got %v", err)
+	}
+	if _, err := setupTracing(context.Background(), "jaeger"); err == nil {
+		t.Error("Expected an error for an unknown exporter")
+	}
+}
+
+func TestEnvTraceExporter(t *testing.T) {
+	tests := []struct {
+		value    string
+		exporter string
+		ignored  []string
+	}{
+		{"", "", nil},
+		{"otlp", "otlp", nil},
+		{"none", "none", nil},
+		{"otlp,console", "otlp", []string{"console"}},
+		{" zipkin , console", "console", []string{"zipkin"}},
+		{"jaeger", "", []string{"jaeger"}},
+	}
+	for _, test := range tests {
+		exporter, ignored := envTraceExporter(test.value)
+		if exporter != test.exporter || !slices.Equal(ignored, test.ignored) {
+			t.Errorf("envTraceExporter(%q) = %q, %v, expected %q, %v", test.value, exporter, ignored, test.exporter, test.ignored)
+		}
+	}
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Setenv("TRACEPARENT", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	req, _ := http.NewRequestWithContext(traceParentContext(), http.MethodPost, "https://api.openai.com/v1/audio/transcriptions", strings.NewReader("audio"))
+	var next option.MiddlewareNext = func(req *http.Request) (*http.Response, error) {
+		io.ReadAll(req.Body)
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}, nil
+	}
+	if _, err := traceMiddleware(req, next); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "POST /v1/audio/transcriptions" {
+		t.Errorf("Unexpected span name %q", span.Name())
+	}
+	if span.Parent().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the span to continue the trace of TRACEPARENT, got %s", span.Parent().TraceID())
+	}
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	if attributes["http.request.body.size"].AsInt64() != 5 || attributes["http.response.status_code"].AsInt64() != 429 {
+		t.Errorf("Unexpected attributes %v", span.Attributes())
+	}
+	if len(span.Events()) != 1 || span.Events()[0].Name != "request sent" {
+		t.Errorf("Expected a request sent event, got %v", span.Events())
+	}
+	if span.Status().Description != "429 Too Many Requests" {
+		t.Errorf("Expected the span to fail with the status, got %v", span.Status())
+	}
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/openai/openai-go"
+)
+
+// Transcript is a transcription result including segment timing when available
+type Transcript struct {
+	Task     string    `json:"task,omitempty"`
+	Language string    `json:"language,omitempty"`
+	Duration float64   `json:"duration,omitempty"`
+	Text     string    `json:"text"`
+	Segments []Segment `json:"segments,omitempty"`
+	// Words are only present when word timestamps were requested
+	Words []Word `json:"words,omitempty"`
+	// Logprobs are only present when requested from the gpt-4o models
+	Logprobs []TokenLogprob `json:"logprobs,omitempty"`
+	// Provider and Model are set by pindar for the JSON result
+	Provider string `json:"-"`
+	Model    string `json:"-"`
+	// Extensions are the fields of the provider's response pindar doesn't know
+	Extensions map[string]json.RawMessage `json:"-"`
+}
+
+// TokenLogprob is the log probability of a single transcribed token
+type TokenLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+}
+
+// Segment is a timed piece of a transcript as returned by the verbose_json format
+type Segment struct {
+	ID               int     `json:"id"`
+	Start            float64 `json:"start"`
+	End              float64 `json:"end"`
+	Text             string  `json:"text"`
+	Tokens           []int   `json:"tokens,omitempty"`
+	Temperature      float64 `json:"temperature"`
+	AvgLogprob       float64 `json:"avg_logprob"`
+	CompressionRatio float64 `json:"compression_ratio"`
+	NoSpeechProb     float64 `json:"no_speech_prob"`
+	// Sentiment and Topics are only set by --tag-segments
+	Sentiment string   `json:"sentiment,omitempty"`
+	Topics    []string `json:"topics,omitempty"`
+	// Speaker is only set by --split-call, --tracks and --turns
+	Speaker string `json:"speaker,omitempty"`
+	// Hallucination is why --hallucinations took the segment for made up
+	Hallucination string `json:"hallucination,omitempty"`
+	// Confidence is only set in the JSON result, see newResult
+	Confidence float64 `json:"confidence,omitempty"`
+	// Extensions are the fields of the provider's segment pindar doesn't know
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+// Word is a single word with its timing, as returned with word timestamps
+type Word struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// MergeOptions control how consecutive segments are merged into larger units
+type MergeOptions struct {
+	// MaxPause merges segments separated by at most this many seconds (0 disables)
+	MaxPause float64
+	// Sentences only ends a merged unit at sentence boundaries
+	Sentences bool
+	// MaxDuration caps the length of a merged unit in seconds (0 means unlimited)
+	MaxDuration float64
+}
+
+// enabled reports whether any merging was requested
+func (o MergeOptions) enabled() bool {
+	return o.MaxPause > 0 || o.Sentences
+}
+
+// modelSupportsTimestamps reports whether a model can return verbose_json with segments
+func modelSupportsTimestamps(model string) bool {
+	return !strings.HasPrefix(model, "gpt-4o")
+}
+
+// needsSegments reports whether an output format is rendered from segments
+func needsSegments(format string) bool {
+	switch format {
+	case "verbose_json", "csv", "srt", "vtt", "ttml", "scc", "audacity-labels":
+		return true
+	}
+	return needsWords(format)
+}
+
+// needsWords reports whether an output format is rendered from word timestamps
+func needsWords(format string) bool {
+	return format == "ass" || format == "lrc" || format == "html"
+}
+
+// setSegmentText replaces the text of a segment after a pass rewrote it. The
+// segment's token IDs decode to what was said before, like the names
+// --anonymize replaced, so they are dropped when the text changes.
+func setSegmentText(segment *Segment, text string) {
+	if text != segment.Text {
+		segment.Text, segment.Tokens = text, nil
+	}
+}
+
+// syncWordsWithText makes the word timestamps and token log probabilities
+// follow the text after a pass rewrote the segments. Words are rebuilt from
+// the segment text with their timing kept; tokens keep their probabilities but
+// lose their text, which can't be mapped to the rewritten one. Otherwise
+// verbose_json and --post-hook would still carry what was said before, like
+// the names --anonymize replaced.
+func syncWordsWithText(transcript *Transcript) {
+	if len(transcript.Words) > 0 {
+		var words []Word
+		for i, line := range segmentWords(transcript.Segments, transcript.Words) {
+			words = append(words, alignWords(transcript.Segments[i].Text, line)...)
+		}
+		transcript.Words = words
+	}
+	for i := range transcript.Logprobs {
+		transcript.Logprobs[i].Token = ""
+	}
+}
+
+// renderTranscript produces the output for the requested format. page is only
+// used by the html format.
+func renderTranscript(transcript *Transcript, format string, merge MergeOptions, page htmlPage) (string, error) {
+	switch format {
+	case "verbose_json":
+		data, err := json.MarshalIndent(newResult(transcript, mergeSegments(transcript.Segments, merge)), "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal transcription: %w", err)
+		}
+		return string(data), nil
+	case "csv":
+		return renderCSV(mergeSegments(transcript.Segments, merge))
+	case "srt", "vtt":
+		segments := mergeSegments(transcript.Segments, merge)
+		if isRTL(transcript.Language) {
+			segments = withDirectionMarks(segments)
+		}
+		if format == "srt" {
+			return renderSRT(segments), nil
+		}
+		return renderVTT(segments), nil
+	case "ttml":
+		return renderTTML(mergeSegments(transcript.Segments, merge), transcript.Language), nil
+	case "scc":
+		return renderSCC(mergeSegments(transcript.Segments, merge)), nil
+	case "audacity-labels":
+		return renderAudacityLabels(mergeSegments(transcript.Segments, merge)), nil
+	case "ass":
+		return renderASS(mergeSegments(transcript.Segments, merge), transcript.Words), nil
+	case "lrc":
+		return renderLRC(mergeSegments(transcript.Segments, merge), transcript.Words), nil
+	case "html":
+		return renderHTML(mergeSegments(transcript.Segments, merge), transcript.Words, transcript.Language, page)
+	default:
+		return transcript.Text, nil
+	}
+}
+
+// transcriptFromResponse converts an API response into a Transcript, keeping
+// the segments present in verbose_json responses
+func transcriptFromResponse(response *openai.Transcription) (*Transcript, error) {
+	transcript := &Transcript{Text: response.Text}
+
+	raw := response.RawJSON()
+	if raw == "" {
+		return transcript, nil
+	}
+	if err := json.Unmarshal([]byte(raw), transcript); err != nil {
+		return nil, fmt.Errorf("failed to parse transcription response: %w", err)
+	}
+
+	// Keep what the provider reports beyond the known fields, like its usage
+	var extra struct {
+		Segments []map[string]json.RawMessage `json:"segments"`
+	}
+	if err := json.Unmarshal([]byte(raw), &transcript.Extensions); err != nil {
+		return nil, fmt.Errorf("failed to parse transcription response: %w", err)
+	}
+	if err := json.Unmarshal([]byte(raw), &extra); err != nil {
+		return nil, fmt.Errorf("failed to parse transcription response: %w", err)
+	}
+	transcript.Extensions = unknownFields(transcript.Extensions, Transcript{})
+	for i := range transcript.Segments {
+		if i < len(extra.Segments) {
+			transcript.Segments[i].Extensions = unknownFields(extra.Segments[i], Segment{})
+		}
+	}
+	return transcript, nil
+}
+
+// unknownFields removes the fields of known's JSON form from fields, returning nil if none are left
+func unknownFields(fields map[string]json.RawMessage, known any) map[string]json.RawMessage {
+	t := reflect.TypeOf(known)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		delete(fields, name)
+	}
+	delete(fields, "extensions")
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// endsSentence reports whether text ends with sentence-final punctuation
+func endsSentence(text string) bool {
+	text = strings.TrimRight(strings.TrimSpace(text), `"'”’)»`)
+	return strings.HasSuffix(text, ".") || strings.HasSuffix(text, "!") ||
+		strings.HasSuffix(text, "?") || strings.HasSuffix(text, "…") ||
+		strings.HasSuffix(text, "。") || strings.HasSuffix(text, "？") || strings.HasSuffix(text, "！")
+}
+
+// mergeSegments joins consecutive segments into larger semantic units. Units end
+// at pauses longer than MaxPause, at sentence boundaries when Sentences is set,
+// before they would exceed MaxDuration, and when the speaker changes.
+func mergeSegments(segments []Segment, opts MergeOptions) []Segment {
+	if !opts.enabled() || len(segments) == 0 {
+		return segments
+	}
+
+	var merged []Segment
+	var group []Segment
+	flush := func() {
+		if len(group) > 0 {
+			unit := combineSegments(group)
+			unit.ID = len(merged)
+			merged = append(merged, unit)
+			group = nil
+		}
+	}
+
+	for _, segment := range segments {
+		if len(group) > 0 {
+			last := group[len(group)-1]
+			split := false
+			if opts.MaxPause > 0 && segment.Start-last.End > opts.MaxPause {
+				split = true
+			}
+			if opts.Sentences && endsSentence(last.Text) {
+				split = true
+			}
+			if opts.MaxDuration > 0 && segment.End-group[0].Start > opts.MaxDuration {
+				split = true
+			}
+			if segment.Speaker != last.Speaker {
+				split = true
+			}
+			if split {
+				flush()
+			}
+		}
+		group = append(group, segment)
+	}
+	flush()
+
+	return merged
+}
+
+// combineSegments collapses several segments into one, weighting the
+// per-segment metrics by segment duration
+func combineSegments(group []Segment) Segment {
+	combined := Segment{
+		Start:   group[0].Start,
+		End:     group[len(group)-1].End,
+		Speaker: group[0].Speaker,
+	}
+
+	var texts []string
+	var totalDuration float64
+	for _, s := range group {
+		texts = append(texts, strings.TrimSpace(s.Text))
+		for _, topic := range s.Topics {
+			if !slices.Contains(combined.Topics, topic) {
+				combined.Topics = append(combined.Topics, topic)
+			}
+		}
+		combined.Tokens = append(combined.Tokens, s.Tokens...)
+		if s.Temperature > combined.Temperature {
+			combined.Temperature = s.Temperature
+		}
+
+		duration := s.End - s.Start
+		totalDuration += duration
+		combined.AvgLogprob += s.AvgLogprob * duration
+		combined.CompressionRatio += s.CompressionRatio * duration
+		combined.NoSpeechProb += s.NoSpeechProb * duration
+	}
+	combined.Text = " " + strings.Join(texts, " ")
+	combined.Sentiment = combineSentiments(group)
+
+	if totalDuration > 0 {
+		combined.AvgLogprob /= totalDuration
+		combined.CompressionRatio /= totalDuration
+		combined.NoSpeechProb /= totalDuration
+	} else {
+		n := float64(len(group))
+		combined.AvgLogprob, combined.CompressionRatio, combined.NoSpeechProb = 0, 0, 0
+		for _, s := range group {
+			combined.AvgLogprob += s.AvgLogprob / n
+			combined.CompressionRatio += s.CompressionRatio / n
+			combined.NoSpeechProb += s.NoSpeechProb / n
+		}
+	}
+
+	return combined
+}
+
+// combineSentiments returns the common sentiment of the segments, or "mixed"
+// when they disagree
+func combineSentiments(group []Segment) string {
+	sentiment := ""
+	for _, s := range group {
+		switch {
+		case s.Sentiment == "" || s.Sentiment == sentiment:
+		case sentiment == "":
+			sentiment = s.Sentiment
+		default:
+			return "mixed"
+		}
+	}
+	return sentiment
+}
+
+// renderCSV writes one row per segment with its timing, speaker, text and tags
+func renderCSV(segments []Segment) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write([]string{"start", "end", "speaker", "text", "sentiment", "topics"})
+	for _, s := range segments {
+		w.Write([]string{
+			strconv.FormatFloat(s.Start, 'f', 2, 64),
+			strconv.FormatFloat(s.End, 'f', 2, 64),
+			s.Speaker,
+			strings.TrimSpace(s.Text),
+			s.Sentiment,
+			strings.Join(s.Topics, ";"),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func testSegments() []Segment {
+	return []Segment{
+		{ID: 0, Start: 0.0, End: 2.0, Text: " Welcome to the show", AvgLogprob: -0.2},
+		{ID: 1, Start: 2.1, End: 4.0, Text: " about distributed systems.", AvgLogprob: -0.4},
+		{ID: 2, Start: 4.2, End: 6.0, Text: " Today we talk", AvgLogprob: -0.3},
+		{ID: 3, Start: 9.0, End: 11.0, Text: " about consensus.", AvgLogprob: -0.1},
+	}
+}
+
+func TestMergeSegmentsDisabled(t *testing.T) {
+	segments := testSegments()
+	result := mergeSegments(segments, MergeOptions{})
+	if len(result) != len(segments) {
+		t.Errorf("Expected segments to be unchanged, got %d segments", len(result))
+	}
+}
+
+func TestMergeSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     MergeOptions
+		expected []string
+	}{
+		{
+			name:     "Pause threshold",
+			opts:     MergeOptions{MaxPause: 1.0},
+			expected: []string{" Welcome to the show about distributed systems. Today we talk", " about consensus."},
+		},
+		{
+			name:     "Sentence boundaries",
+			opts:     MergeOptions{Sentences: true},
+			expected: []string{" Welcome to the show about distributed systems.", " Today we talk about consensus."},
+		},
+		{
+			name:     "Pause and sentence boundaries",
+			opts:     MergeOptions{MaxPause: 1.0, Sentences: true},
+			expected: []string{" Welcome to the show about distributed systems.", " Today we talk", " about consensus."},
+		},
+		{
+			name:     "Maximum duration",
+			opts:     MergeOptions{MaxPause: 5.0, MaxDuration: 5.0},
+			expected: []string{" Welcome to the show about distributed systems.", " Today we talk", " about consensus."},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := mergeSegments(testSegments(), tc.opts)
+			if len(result) != len(tc.expected) {
+				t.Fatalf("Expected %d segments, got %d: %+v", len(tc.expected), len(result), result)
+			}
+			for i, segment := range result {
+				if segment.ID != i {
+					t.Errorf("Expected segment ID %d, got %d", i, segment.ID)
+				}
+				if segment.Text != tc.expected[i] {
+					t.Errorf("Segment %d: expected %q, got %q", i, tc.expected[i], segment.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestCombineSegmentsWeightsMetrics(t *testing.T) {
+	combined := combineSegments([]Segment{
+		{Start: 0, End: 3, AvgLogprob: -0.1},
+		{Start: 3, End: 4, AvgLogprob: -0.5},
+	})
+
+	if combined.Start != 0 || combined.End != 4 {
+		t.Errorf("Unexpected timing: %v-%v", combined.Start, combined.End)
+	}
+	expected := (-0.1*3 + -0.5*1) / 4
+	if diff := combined.AvgLogprob - expected; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected avg_logprob %v, got %v", expected, combined.AvgLogprob)
+	}
+}
+
+func TestEndsSentence(t *testing.T) {
+	tests := map[string]bool{
+		" Hello world.":      true,
+		" Is it?":            true,
+		" He said \"stop!\"": true,
+		" and then":          false,
+		" 3,5":               false,
+	}
+
+	for text, expected := range tests {
+		if result := endsSentence(text); result != expected {
+			t.Errorf("endsSentence(%q) = %v, expected %v", text, result, expected)
+		}
+	}
+}
+
+func TestRenderVerboseJSON(t *testing.T) {
+	transcript := &Transcript{Language: "english", Duration: 11, Text: "Welcome", Segments: testSegments()}
+
+	output, err := renderTranscript(transcript, "verbose_json", MergeOptions{Sentences: true}, htmlPage{})
+	if err != nil {
+		t.Fatalf("renderTranscript() failed: %v", err)
+	}
+
+	var parsed Transcript
+	if err := json.Unmarshal([]byte(output), &parsed); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if len(parsed.Segments) != 2 {
+		t.Errorf("Expected 2 merged segments, got %d", len(parsed.Segments))
+	}
+	if len(transcript.Segments) != 4 {
+		t.Errorf("Rendering must not modify the original transcript")
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	transcript := &Transcript{Segments: []Segment{
+		{Start: 0, End: 2.5, Text: " Hello, I have a billing question.", Speaker: "Customer", Sentiment: "neutral", Topics: []string{"billing"}},
+		{Start: 2.5, End: 4, Text: ` He said "no".`},
+	}}
+
+	expected := "start,end,speaker,text,sentiment,topics\n" +
+		"0.00,2.50,Customer,\"Hello, I have a billing question.\",neutral,billing\n" +
+		"2.50,4.00,,\"He said \"\"no\"\".\",,\n"
+	result, err := renderTranscript(transcript, "csv", MergeOptions{}, htmlPage{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestCombineSegmentsTags(t *testing.T) {
+	tests := []struct {
+		name      string
+		group     []Segment
+		sentiment string
+		topics    []string
+	}{
+		{
+			name:      "Same sentiment",
+			group:     []Segment{{Sentiment: "negative", Topics: []string{"billing"}}, {Sentiment: "negative", Topics: []string{"billing", "refund"}}},
+			sentiment: "negative",
+			topics:    []string{"billing", "refund"},
+		},
+		{
+			name:      "Mixed sentiment",
+			group:     []Segment{{Sentiment: "negative"}, {}, {Sentiment: "positive"}},
+			sentiment: "mixed",
+		},
+		{
+			name:  "Untagged",
+			group: []Segment{{}, {}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			combined := combineSegments(tc.group)
+			if combined.Sentiment != tc.sentiment {
+				t.Errorf("Expected sentiment %q, got %q", tc.sentiment, combined.Sentiment)
+			}
+			if !slices.Equal(combined.Topics, tc.topics) {
+				t.Errorf("Expected topics %v, got %v", tc.topics, combined.Topics)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openai/openai-go"
+)
+
+// segmentTranslation is the translation of one numbered segment
+type segmentTranslation struct {
+	Segment int    `json:"segment"`
+	Text    string `json:"text"`
+}
+
+// translateSegments asks the analysis model to translate every segment into
+// the language with the given code. Segments the model skips stay empty.
+func translateSegments(ctx context.Context, client openai.Client, model, language string, segments []Segment) ([]string, error) {
+	instructions := `You translate transcripts. The user sends numbered transcript segments. Translate each segment ` +
+		`into ` + languageName(language) + ` and return JSON of the form {"translations": [{"segment": ..., "text": ...}]} ` +
+		`with one entry per segment. Translate the meaning faithfully and keep the register of the speaker; ` +
+		`don't merge, split or summarize segments.`
+
+	translations := make([]string, len(segments))
+	for start := 0; start < len(segments); start += analysisBatchSize {
+		end := min(start+analysisBatchSize, len(segments))
+
+		var answer struct {
+			Translations []segmentTranslation `json:"translations"`
+		}
+		if err := chatJSON(ctx, client, model, instructions, numberedSegments(segments[start:end]), &answer); err != nil {
+			return nil, fmt.Errorf("translation failed: %w", err)
+		}
+		for _, translation := range answer.Translations {
+			if translation.Segment >= 0 && translation.Segment < end-start {
+				translations[start+translation.Segment] = translation.Text
+			}
+		}
+	}
+	return translations, nil
+}
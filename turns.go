@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Settings of the speaker turn detection of --turns. The audio is measured
+// in short frames; speech is what is clearly louder than the quietest tenth
+// of the recording, the noise floor.
+const (
+	turnSampleRate   = 8000
+	turnFrameSeconds = 0.05
+	// turnSpeechAboveNoise is how many dB above the noise floor speech is
+	turnSpeechAboveNoise = 12.0
+	// turnSilenceFloor is the level in dBFS below which nothing counts as speech
+	turnSilenceFloor = -60.0
+	// turnMinSilence bridges shorter gaps, like those between words
+	turnMinSilence = 0.3
+	// turnMinSpeech drops shorter sounds, like clicks and breaths
+	turnMinSpeech = 0.2
+	// turnChannelMargin is how much louder a channel must be to tell who speaks
+	turnChannelMargin = 3.0
+	// turnLevelGap is how far apart the levels of two voices in a mono
+	// recording must be to tell them apart, e.g. a near and a far microphone
+	turnLevelGap = 4.0
+	// turnPause is the pause after which the other person is taken to speak
+	// when the levels don't tell the voices apart
+	turnPause = 0.7
+	// turnMinLength is the shortest turn; shorter ones are flicker between
+	// channels and are added to the turn before
+	turnMinLength = 0.25
+)
+
+// Frame labels besides the speaker index
+const (
+	frameSilent    = -1
+	frameUndecided = -2
+)
+
+// speechTurn is a stretch of the recording in which one person speaks.
+// Speaker is 0 or 1: the left or right channel of a stereo recording, or the
+// first and second voice heard in a mono one.
+type speechTurn struct {
+	Speaker int
+	Start   float64
+	End     float64
+}
+
+// turnSpeakerNames returns the labels of the two speakers of --turns: the
+// first two --speakers if given, otherwise Speaker 1 and Speaker 2
+func turnSpeakerNames(speakers []string) []string {
+	if len(speakers) >= 2 {
+		return speakers[:2]
+	}
+	return []string{tr("Speaker 1"), tr("Speaker 2")}
+}
+
+// detectSpeakerTurns decodes the audio with ffmpeg and finds the turns of a
+// two-party recording from its loudness alone, without sending it anywhere
+func detectSpeakerTurns(ctx context.Context, path string, track int) ([]speechTurn, error) {
+	channels := 1
+	if probe, err := probeAudio(path); err == nil {
+		audio := probe.streamsOfType("audio")
+		if index := max(track, 1) - 1; index < len(audio) && audio[index].Channels == 2 {
+			channels = 2
+		}
+	}
+
+	var output []string
+	if track > 0 {
+		output = append(output, "-map", fmt.Sprintf("0:a:%d", track-1))
+	}
+	output = append(output, "-vn", "-ac", strconv.Itoa(channels), "-ar", strconv.Itoa(turnSampleRate), "-f", "s16le", "-")
+	cmd, err := ffmpegCommand(nil, path, output...)
+	if err != nil {
+		return nil, err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode audio: %w", err)
+	}
+
+	_, span := tracer.Start(ctx, "turns", trace.WithAttributes(attribute.String("pindar.file", filepath.Base(path))))
+	if err := cmd.Start(); err != nil {
+		endSpan(span, err)
+		return nil, fmt.Errorf("failed to decode audio: %w", err)
+	}
+	levels, readErr := measureLevels(stdout, channels)
+	err = errors.Join(readErr, cmd.Wait())
+	endSpan(span, err)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg decoding failed: %w\nOutput: %s", err, stderr.String())
+	}
+	return detectTurns(levels), nil
+}
+
+// measureLevels reads interleaved 16-bit PCM and returns the level of each
+// channel in every frame, in dBFS
+func measureLevels(r io.Reader, channels int) ([][]float64, error) {
+	frameSamples := int(turnSampleRate * turnFrameSeconds)
+	buf := make([]byte, frameSamples*channels*2)
+	reader := bufio.NewReader(r)
+
+	var levels [][]float64
+	for {
+		n, err := io.ReadFull(reader, buf)
+		if n >= channels*2 {
+			sums := make([]float64, channels)
+			samples := n / 2 / channels
+			for i := 0; i < samples*channels; i++ {
+				sample := float64(int16(binary.LittleEndian.Uint16(buf[i*2:]))) / 32768
+				sums[i%channels] += sample * sample
+			}
+			frame := make([]float64, channels)
+			for c, sum := range sums {
+				frame[c] = 10 * math.Log10(sum/float64(samples)+1e-10)
+			}
+			levels = append(levels, frame)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return levels, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audio: %w", err)
+		}
+	}
+}
+
+// detectTurns finds the speaker turns in the frame levels. In a stereo
+// recording with a person on each channel, the louder channel speaks. In
+// mono, or stereo with the same audio on both channels, stretches of speech
+// are told apart by their loudness if the two voices differ enough, and
+// otherwise the other person is taken to answer after a longer pause.
+func detectTurns(levels [][]float64) []speechTurn {
+	if len(levels) == 0 {
+		return nil
+	}
+	loudness := make([]float64, len(levels))
+	for i, frame := range levels {
+		loudness[i] = slices.Max(frame)
+	}
+	speech := speechFrames(loudness)
+
+	var labels []int
+	if len(levels[0]) == 2 {
+		labels = channelSpeakers(levels, speech)
+	}
+	if labels == nil {
+		labels = loudnessSpeakers(loudness, speech)
+	}
+	return framesToTurns(labels)
+}
+
+// frameCount converts seconds to a number of frames
+func frameCount(seconds float64) int {
+	return int(math.Round(seconds / turnFrameSeconds))
+}
+
+// speechFrames marks the frames with speech, bridging the short gaps between
+// words and dropping sounds too short to be speech
+func speechFrames(loudness []float64) []bool {
+	sorted := slices.Clone(loudness)
+	slices.Sort(sorted)
+	threshold := max(sorted[len(sorted)/10]+turnSpeechAboveNoise, turnSilenceFloor)
+
+	speech := make([]bool, len(loudness))
+	for i, level := range loudness {
+		speech[i] = level > threshold
+	}
+	for _, gap := range frameRuns(speech, false) {
+		if gap[0] > 0 && gap[1] < len(speech) && gap[1]-gap[0] < frameCount(turnMinSilence) {
+			for i := gap[0]; i < gap[1]; i++ {
+				speech[i] = true
+			}
+		}
+	}
+	for _, run := range frameRuns(speech, true) {
+		if run[1]-run[0] < frameCount(turnMinSpeech) {
+			for i := run[0]; i < run[1]; i++ {
+				speech[i] = false
+			}
+		}
+	}
+	return speech
+}
+
+// frameRuns returns the start and end frame of every run of frames with the
+// given value
+func frameRuns(frames []bool, value bool) [][2]int {
+	var runs [][2]int
+	for i := 0; i < len(frames); i++ {
+		if frames[i] != value {
+			continue
+		}
+		start := i
+		for i < len(frames) && frames[i] == value {
+			i++
+		}
+		runs = append(runs, [2]int{start, i})
+	}
+	return runs
+}
+
+// channelSpeakers labels every speech frame with the louder channel. Frames
+// where both are about as loud, like crosstalk, belong to the speaker before
+// them. It returns nil if the channels rarely differ, as in a mono recording
+// saved as stereo.
+func channelSpeakers(levels [][]float64, speech []bool) []int {
+	labels := make([]int, len(levels))
+	decided, total := 0, 0
+	for i, frame := range levels {
+		labels[i] = frameSilent
+		if !speech[i] {
+			continue
+		}
+		total++
+		switch difference := frame[0] - frame[1]; {
+		case difference >= turnChannelMargin:
+			labels[i] = 0
+		case difference <= -turnChannelMargin:
+			labels[i] = 1
+		default:
+			labels[i] = frameUndecided
+			continue
+		}
+		decided++
+	}
+	if decided*10 < total {
+		return nil
+	}
+
+	// The frames before the first decided one take its speaker
+	speaker := frameUndecided
+	for _, label := range labels {
+		if label >= 0 {
+			speaker = label
+			break
+		}
+	}
+	for i, label := range labels {
+		switch {
+		case label == frameUndecided:
+			labels[i] = speaker
+		case label >= 0:
+			speaker = label
+		}
+	}
+	return labels
+}
+
+// loudnessSpeakers labels the stretches of speech of a mono recording. If
+// they fall into a louder and a quieter group, those are the two voices;
+// stretches in between, or all of them if the voices are about as loud,
+// belong to the speaker before them unless the pause before them is long
+// enough for the other person to answer. The first voice heard is speaker 0.
+func loudnessSpeakers(loudness []float64, speech []bool) []int {
+	labels := make([]int, len(loudness))
+	for i := range labels {
+		labels[i] = frameSilent
+	}
+	runs := frameRuns(speech, true)
+	runLevels := make([]float64, len(runs))
+	for k, run := range runs {
+		for _, level := range loudness[run[0]:run[1]] {
+			runLevels[k] += level
+		}
+		runLevels[k] /= float64(run[1] - run[0])
+	}
+	quiet, loud := twoMeans(runLevels)
+	middle := (quiet + loud) / 2
+
+	previous, previousEnd := frameSilent, 0
+	for k, run := range runs {
+		speaker := frameUndecided
+		if loud-quiet >= turnLevelGap {
+			switch {
+			case runLevels[k] >= middle+turnLevelGap/4:
+				speaker = 1
+			case runLevels[k] <= middle-turnLevelGap/4:
+				speaker = 0
+			}
+		}
+		if speaker == frameUndecided {
+			switch {
+			case previous == frameSilent:
+				speaker = 0
+			case run[0]-previousEnd >= frameCount(turnPause):
+				speaker = 1 - previous
+			default:
+				speaker = previous
+			}
+		}
+		for i := run[0]; i < run[1]; i++ {
+			labels[i] = speaker
+		}
+		previous, previousEnd = speaker, run[1]
+	}
+
+	if len(runs) > 0 && labels[runs[0][0]] == 1 {
+		for i, label := range labels {
+			if label >= 0 {
+				labels[i] = 1 - label
+			}
+		}
+	}
+	return labels
+}
+
+// twoMeans splits values into a lower and a higher group and returns the
+// mean of each
+func twoMeans(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	low, high := slices.Min(values), slices.Max(values)
+	for range 20 {
+		var lowSum, highSum float64
+		var lowCount, highCount int
+		for _, v := range values {
+			if v-low < high-v {
+				lowSum, lowCount = lowSum+v, lowCount+1
+			} else {
+				highSum, highCount = highSum+v, highCount+1
+			}
+		}
+		if lowCount == 0 || highCount == 0 {
+			break
+		}
+		low, high = lowSum/float64(lowCount), highSum/float64(highCount)
+	}
+	return low, high
+}
+
+// framesToTurns joins the labeled frames into turns. A speaker's turn goes
+// on across pauses until the other one speaks, and turns too short to be
+// more than flicker are added to the turn before.
+func framesToTurns(labels []int) []speechTurn {
+	var turns []speechTurn
+	for i, label := range labels {
+		if label < 0 {
+			continue
+		}
+		start, end := float64(i)*turnFrameSeconds, float64(i+1)*turnFrameSeconds
+		if n := len(turns); n > 0 && turns[n-1].Speaker == label {
+			turns[n-1].End = end
+			continue
+		}
+		turns = append(turns, speechTurn{Speaker: label, Start: start, End: end})
+	}
+
+	var smoothed []speechTurn
+	for _, turn := range turns {
+		n := len(smoothed)
+		if n > 0 && (turn.Speaker == smoothed[n-1].Speaker || turn.End-turn.Start < turnMinLength) {
+			smoothed[n-1].End = turn.End
+			continue
+		}
+		smoothed = append(smoothed, turn)
+	}
+	return smoothed
+}
+
+// labelTurns labels each segment with the speaker whose turns overlap it
+// most. Segments in a pause keep the speaker before them. offset is where
+// the transcript starts in the recording, for chapters.
+func labelTurns(t *Transcript, turns []speechTurn, offset float64, names []string) {
+	speaker := 0
+	for i := range t.Segments {
+		segment := &t.Segments[i]
+		var overlap [2]float64
+		for _, turn := range turns {
+			if o := min(segment.End+offset, turn.End) - max(segment.Start+offset, turn.Start); o > 0 {
+				overlap[turn.Speaker] += o
+			}
+		}
+		switch {
+		case overlap[1] > overlap[0]:
+			speaker = 1
+		case overlap[0] > overlap[1]:
+			speaker = 0
+		}
+		segment.Speaker = names[speaker]
+	}
+	t.Text = segmentsText(t.Segments)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"slices"
+	"testing"
+)
+
+// pcmPart is a stretch of synthetic audio: a tone at the given amplitude on
+// each channel, or silence at 0
+type pcmPart struct {
+	seconds    float64
+	amplitudes []float64
+}
+
+// synthesizePCM renders parts as interleaved 16-bit PCM at the turn sample rate
+func synthesizePCM(parts []pcmPart) []byte {
+	var buf bytes.Buffer
+	n := 0
+	for _, part := range parts {
+		for range int(part.seconds * turnSampleRate) {
+			for _, amplitude := range part.amplitudes {
+				sample := amplitude * math.Sin(2*math.Pi*220*float64(n)/turnSampleRate)
+				binary.Write(&buf, binary.LittleEndian, int16(sample*32767))
+			}
+			n++
+		}
+	}
+	return buf.Bytes()
+}
+
+func turnSpeakers(turns []speechTurn) []int {
+	var speakers []int
+	for _, turn := range turns {
+		speakers = append(speakers, turn.Speaker)
+	}
+	return speakers
+}
+
+func TestDetectTurnsStereo(t *testing.T) {
+	pcm := synthesizePCM([]pcmPart{
+		{1, []float64{0, 0}},
+		{2, []float64{0.5, 0.05}},
+		{0.5, []float64{0, 0}},
+		{2, []float64{0.05, 0.5}},
+		{0.1, []float64{0.5, 0.05}}, // crosstalk too short to be a turn
+		{1, []float64{0.05, 0.5}},
+		{0.3, []float64{0, 0}},
+	})
+	levels, err := measureLevels(bytes.NewReader(pcm), 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	turns := detectTurns(levels)
+	if !slices.Equal(turnSpeakers(turns), []int{0, 1}) {
+		t.Fatalf("Expected the left then the right channel, got %+v", turns)
+	}
+	if math.Abs(turns[0].Start-1) > 0.1 || math.Abs(turns[1].Start-3.5) > 0.1 || math.Abs(turns[1].End-6.6) > 0.1 {
+		t.Errorf("Unexpected turn times %+v", turns)
+	}
+}
+
+func TestDetectTurnsMono(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []pcmPart
+		want  []int
+	}{
+		{
+			name: "voices of different loudness",
+			parts: []pcmPart{
+				{0.5, []float64{0}},
+				{1.5, []float64{0.1}},
+				{0.3, []float64{0}},
+				{2, []float64{0.6}},
+				{1, []float64{0}},
+				{1, []float64{0.6}},
+				{0.4, []float64{0}},
+				{1, []float64{0.1}},
+			},
+			want: []int{0, 1, 0},
+		},
+		{
+			name: "equally loud voices taking turns",
+			parts: []pcmPart{
+				{0.5, []float64{0}},
+				{1.5, []float64{0.3}},
+				{0.4, []float64{0}}, // a pause within a turn
+				{1, []float64{0.3}},
+				{1, []float64{0}},
+				{2, []float64{0.3}},
+				{1, []float64{0}},
+				{1, []float64{0.3}},
+			},
+			want: []int{0, 1, 0},
+		},
+	}
+	for _, test := range tests {
+		levels, err := measureLevels(bytes.NewReader(synthesizePCM(test.parts)), 1)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if got := turnSpeakers(detectTurns(levels)); !slices.Equal(got, test.want) {
+			t.Errorf("%s: expected speakers %v, got %v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestDetectTurnsMonoAsStereo(t *testing.T) {
+	pcm := synthesizePCM([]pcmPart{
+		{1.5, []float64{0.3, 0.3}},
+		{1, []float64{0, 0}},
+		{1.5, []float64{0.3, 0.3}},
+	})
+	levels, _ := measureLevels(bytes.NewReader(pcm), 2)
+	if got := turnSpeakers(detectTurns(levels)); !slices.Equal(got, []int{0, 1}) {
+		t.Errorf("Expected identical channels to be read as mono, got %v", got)
+	}
+}
+
+func TestLabelTurns(t *testing.T) {
+	transcript := &Transcript{Segments: []Segment{
+		{Start: 0, End: 2, Text: "How did it start?"},
+		{Start: 2.2, End: 4, Text: "With a phone call."},
+		{Start: 4.1, End: 4.3, Text: "Mhm."},
+		{Start: 4.5, End: 6, Text: "Then we met."},
+	}}
+	turns := []speechTurn{{Speaker: 0, Start: 10.1, End: 12}, {Speaker: 1, Start: 12.2, End: 16}}
+	labelTurns(transcript, turns, 10, []string{"Ada", "Grace"})
+
+	var speakers []string
+	for _, segment := range transcript.Segments {
+		speakers = append(speakers, segment.Speaker)
+	}
+	if expected := []string{"Ada", "Grace", "Grace", "Grace"}; !slices.Equal(speakers, expected) {
+		t.Errorf("Expected %v, got %v", expected, speakers)
+	}
+	if expected := "Ada: How did it start?\n\nGrace: With a phone call. Mhm. Then we met."; transcript.Text != expected {
+		t.Errorf("Unexpected text %q", transcript.Text)
+	}
+
+	roles := speakerRoles(transcript.Segments, "Ada")
+	if expected := []string{roleInterviewer, roleInterviewee, roleInterviewee, roleInterviewee}; !slices.Equal(roles, expected) {
+		t.Errorf("Expected %v, got %v", expected, roles)
+	}
+}
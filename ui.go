@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// accessibleOutput replaces decorative output with screen-reader-friendly text (--accessible)
+var accessibleOutput bool
+
+// ciOutput prints plain text like accessibleOutput and starts every line with
+// a timestamp, for build logs (--ci)
+var ciOutput bool
+
+// uiState tracks what has been printed: whether the next message starts a
+// line, which needs a timestamp in CI mode, and how many warnings there were
+var uiState struct {
+	sync.Mutex
+	midLine  bool
+	warnings int
+}
+
+// decorativeIcons are the icons that prefix CLI messages
+var decorativeIcons = []string{"❌", "⚠️", "💡", "✅", "💾", "📝"}
+
+// iconLabel returns the spoken label replacing an icon in accessible mode
+func iconLabel(icon string) string {
+	switch icon {
+	case "❌":
+		return tr("Error:")
+	case "⚠️":
+		return tr("Warning:")
+	case "💡":
+		return tr("Tip:")
+	}
+	return ""
+}
+
+// uiPrintf prints a formatted CLI message
+func uiPrintf(format string, args ...any) {
+	emit(fmt.Sprintf(format, args...))
+}
+
+// uiPrint prints a CLI message
+func uiPrint(message string) {
+	emit(message)
+}
+
+// uiPrintln prints a CLI message followed by a newline
+func uiPrintln(message string) {
+	emit(message + "\n")
+}
+
+// emit counts the warnings of a message and prints it in the output mode.
+// The progress reports print from their own goroutine, hence the lock.
+func emit(message string) {
+	uiState.Lock()
+	defer uiState.Unlock()
+	uiState.warnings += countWarnings(message)
+	message = decorate(message)
+	if ciOutput {
+		message, uiState.midLine = timestampLines(message, time.Now(), uiState.midLine)
+	}
+	fmt.Print(message)
+}
+
+// warningCount returns the number of warnings printed so far
+func warningCount() int {
+	uiState.Lock()
+	defer uiState.Unlock()
+	return uiState.warnings
+}
+
+// countWarnings counts the lines of a message starting with the warning icon
+func countWarnings(message string) int {
+	count := 0
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(strings.TrimLeft(line, " "), "⚠️") {
+			count++
+		}
+	}
+	return count
+}
+
+// timestampLines starts the lines of a message with the time in RFC 3339
+// format, except empty lines and a line the previous message left open.
+// It returns whether the message leaves a line open itself.
+func timestampLines(message string, now time.Time, midLine bool) (string, bool) {
+	stamp := now.Format(time.RFC3339) + " "
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(message, "\n") {
+		if line == "" {
+			continue
+		}
+		if !midLine && line != "\n" {
+			b.WriteString(stamp)
+		}
+		b.WriteString(line)
+		midLine = !strings.HasSuffix(line, "\n")
+	}
+	return b.String(), midLine
+}
+
+// printRule prints a horizontal separator line, which accessible and CI mode omit
+func printRule() {
+	if !accessibleOutput && !ciOutput {
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	}
+}
+
+// decorate adapts a message to the output mode. In accessible and CI mode
+// icons become labels, other emoji and box-drawing characters are removed,
+// bullets become dashes and trailing ellipses become full stops.
+func decorate(message string) string {
+	if !accessibleOutput && !ciOutput {
+		return message
+	}
+
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		lines[i] = accessibleLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// accessibleLine rewrites a single line for screen readers
+func accessibleLine(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	indent := line[:len(line)-len(trimmed)]
+
+	for _, icon := range decorativeIcons {
+		if !strings.HasPrefix(trimmed, icon) {
+			continue
+		}
+		trimmed = strings.TrimLeft(strings.TrimPrefix(trimmed, icon), " ️")
+		label := iconLabel(icon)
+		if label != "" && !startsWithWord(trimmed, strings.TrimSuffix(label, ":")) {
+			trimmed = label + " " + trimmed
+		}
+		break
+	}
+
+	trimmed = strings.Replace(trimmed, "• ", "- ", 1)
+	trimmed = strings.Map(func(r rune) rune {
+		if isDecorativeRune(r) {
+			return -1
+		}
+		return r
+	}, trimmed)
+	if strings.HasSuffix(trimmed, "...") {
+		trimmed = strings.TrimSuffix(trimmed, "...") + "."
+	}
+
+	return indent + trimmed
+}
+
+// startsWithWord reports whether text begins with word, ignoring case
+func startsWithWord(text, word string) bool {
+	return len(text) >= len(word) && strings.EqualFold(text[:len(word)], word)
+}
+
+// isDecorativeRune reports whether r is an emoji, pictograph or box-drawing character
+func isDecorativeRune(r rune) bool {
+	switch {
+	case r >= 0x2500 && r <= 0x257F: // Box drawing
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // Miscellaneous symbols and dingbats
+		return true
+	case r >= 0x1F300 && r <= 0x1FAFF: // Emoji and pictographs
+		return true
+	case r == 0xFE0F || r == 0x200D: // Emoji variation selector and joiner
+		return true
+	}
+	return unicode.Is(unicode.So, r) && r > 0x2000
+}
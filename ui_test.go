@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alexflint/go-arg"
+)
+
+func TestDecorateDefaultKeepsMessage(t *testing.T) {
+	message := "❌ Error writing output file: disk full\n"
+	if result := decorate(message); result != message {
+		t.Errorf("Expected message unchanged, got %q", result)
+	}
+}
+
+func TestDecorateAccessible(t *testing.T) {
+	accessibleOutput = true
+	defer func() { accessibleOutput = false }()
+
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{"❌ Error writing output file: disk full\n", "Error writing output file: disk full\n"},
+		{"❌ Audio file too long.\n", "Error: Audio file too long.\n"},
+		{"⚠️  Note: SRT/VTT formats require timestamps.\n", "Warning: Note: SRT/VTT formats require timestamps.\n"},
+		{"💡 Suggestions:\n", "Tip: Suggestions:\n"},
+		{"✅ Transcription completed successfully!", "Transcription completed successfully!"},
+		{"\n📝 Transcription:", "\nTranscription:"},
+		{"   • Split the audio into shorter segments\n", "   - Split the audio into shorter segments\n"},
+		{" Starting transcription...", " Starting transcription."},
+		{"  Pindar ━━ Audio", "  Pindar  Audio"},
+	}
+
+	for _, tc := range tests {
+		if result := decorate(tc.message); result != tc.expected {
+			t.Errorf("decorate(%q) = %q, expected %q", tc.message, result, tc.expected)
+		}
+	}
+}
+
+func TestDecorateCI(t *testing.T) {
+	ciOutput = true
+	defer func() { ciOutput = false }()
+
+	if result := decorate("⚠️  Could not read chapters\n"); result != "Warning: Could not read chapters\n" {
+		t.Errorf("Expected CI output without icons, got %q", result)
+	}
+}
+
+func TestTimestampLines(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+
+	result, midLine := timestampLines("\nTranscription Parameters:\n   File: a.mp3\n", now, false)
+	expected := "\n2024-05-01T09:30:00Z Transcription Parameters:\n2024-05-01T09:30:00Z    File: a.mp3\n"
+	if result != expected || midLine {
+		t.Errorf("Expected %q, got %q (mid-line: %v)", expected, result, midLine)
+	}
+
+	// A prompt leaves the line open, so its answer isn't stamped again
+	result, midLine = timestampLines("Passphrase: ", now, false)
+	if result != "2024-05-01T09:30:00Z Passphrase: " || !midLine {
+		t.Errorf("Expected a stamped open line, got %q (mid-line: %v)", result, midLine)
+	}
+	result, midLine = timestampLines("\n", now, midLine)
+	if result != "\n" || midLine {
+		t.Errorf("Expected the line to be closed without a stamp, got %q", result)
+	}
+}
+
+func TestCountWarnings(t *testing.T) {
+	message := "⚠️  Prompt is too long\n   ⚠️  Skipping a.txt\n❌ Error\nNo ⚠️ here\n"
+	if count := countWarnings(message); count != 2 {
+		t.Errorf("Expected 2 warnings, got %d", count)
+	}
+}
+
+func TestCIFromEnvironment(t *testing.T) {
+	t.Setenv("PINDAR_CI", "true")
+	var args Args
+	parser, err := arg.NewParser(arg.Config{}, &args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.Parse([]string{"talk.mp3"}); err != nil || !args.CI {
+		t.Errorf("Expected PINDAR_CI to turn on --ci, got %v (%v)", args.CI, err)
+	}
+}
+
+func TestUILanguageFromEnvironment(t *testing.T) {
+	t.Setenv("PINDAR_UI_LANG", "de")
+	var args Args
+	parser, err := arg.NewParser(arg.Config{}, &args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.Parse([]string{"talk.mp3"}); err != nil || args.UILang != "de" {
+		t.Errorf("Expected PINDAR_UI_LANG to set --ui-lang, got %q (%v)", args.UILang, err)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readURLList parses a --url-list file with one URL per line. Blank lines
+// and lines starting with # are skipped, so a list can be annotated.
+func readURLList(r io.Reader) ([]manifestJob, error) {
+	var jobs []manifestJob
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		input := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if input == "" || strings.HasPrefix(input, "#") {
+			continue
+		}
+		if !isURL(input) {
+			return nil, fmt.Errorf(tr("line %d: %q is not an http or https URL"), line, input)
+		}
+		jobs = append(jobs, manifestJob{Line: line, Input: input})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read URL list: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil, errors.New(tr("the URL list has no URLs"))
+	}
+	return jobs, nil
+}
+
+// runURLList downloads and transcribes every URL of a list, like the rows
+// of a manifest
+func runURLList(args Args, argv []string) {
+	f, err := os.Open(args.URLList)
+	if err != nil {
+		uiPrintf(tr("❌ Error reading URL list: %v\n"), err)
+		os.Exit(1)
+	}
+	jobs, err := readURLList(f)
+	f.Close()
+	if err != nil {
+		uiPrintf(tr("❌ Error reading URL list: %v\n"), err)
+		os.Exit(1)
+	}
+
+	runJobs(args, withoutFlag(argv, "--url-list"), jobs)
+	uiPrintf(tr("\n✅ Transcribed all %d URLs of the list\n"), len(jobs))
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadURLList(t *testing.T) {
+	list := "\ufeff# Season 2\nhttps://example.com/ep1.mp3\n\n  https://example.com/ep2.mp3  \r\n"
+	jobs, err := readURLList(strings.NewReader(list))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []manifestJob{
+		{Line: 2, Input: "https://example.com/ep1.mp3"},
+		{Line: 4, Input: "https://example.com/ep2.mp3"},
+	}
+	if !slices.Equal(jobs, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, jobs)
+	}
+}
+
+func TestReadURLListErrors(t *testing.T) {
+	tests := map[string]string{
+		"no URLs":  "# nothing yet\n\n",
+		"a path":   "https://example.com/ep1.mp3\nep2.mp3\n",
+		"ftp URLs": "ftp://example.com/ep1.mp3\n",
+	}
+	for name, list := range tests {
+		if _, err := readURLList(strings.NewReader(list)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
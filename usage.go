@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UsageArgs defines the arguments of the usage subcommand
+type UsageArgs struct {
+	Weeks   int  `arg:"--weeks" default:"8" help:"Number of weeks to show"`
+	Enable  bool `arg:"--enable" help:"Start recording usage statistics on this machine"`
+	Disable bool `arg:"--disable" help:"Stop recording usage statistics (the statistics recorded so far are kept)"`
+}
+
+// usageBarWidth is the width of the longest bar of the weekly chart
+const usageBarWidth = 30
+
+// UsageRecord is the statistics of one transcription, appended to the usage
+// log when usage_log is on. It holds no file names or text, and never leaves
+// the machine.
+type UsageRecord struct {
+	At           time.Time `json:"at"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	AudioSeconds float64   `json:"audio_seconds"`
+}
+
+// weekUsage is the audio transcribed in one week
+type weekUsage struct {
+	// Label is the ISO week, e.g. 2024-W18
+	Label   string
+	Minutes float64
+}
+
+// modelUsage is how much audio a model transcribed
+type modelUsage struct {
+	Model   string
+	Runs    int
+	Minutes float64
+}
+
+// usageLogPath returns the path of the usage log in the config directory
+func usageLogPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "usage.jsonl"), nil
+}
+
+// recordUsage appends a record to the usage log
+func recordUsage(record UsageRecord) error {
+	logPath, err := usageLogPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage: %w", err)
+	}
+
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open usage log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write usage log: %w", err)
+	}
+	return nil
+}
+
+// loadUsage reads the usage log
+func loadUsage(path string) ([]UsageRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []UsageRecord
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record UsageRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid record on line %d of %s: %w", line, path, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// weeklyMinutes sums the minutes of audio per ISO week for the given number
+// of weeks up to the one of now, oldest first. Weeks without transcriptions
+// are included with zero minutes.
+func weeklyMinutes(records []UsageRecord, now time.Time, weeks int) []weekUsage {
+	// Weeks start on Monday; time.Weekday counts from Sunday
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := today.AddDate(0, 0, -(int(today.Weekday())+6)%7-7*(weeks-1))
+
+	usage := make([]weekUsage, weeks)
+	for i := range usage {
+		year, week := start.AddDate(0, 0, 7*i).ISOWeek()
+		usage[i].Label = fmt.Sprintf("%d-W%02d", year, week)
+	}
+	for _, record := range records {
+		at := record.At.In(now.Location())
+		day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, now.Location())
+		if day.Before(start) || day.After(today) {
+			continue
+		}
+		// Rounding absorbs the hour a daylight saving change adds or removes
+		i := int(day.Sub(start).Hours()/24+0.5) / 7
+		usage[i].Minutes += record.AudioSeconds / 60
+	}
+	return usage
+}
+
+// modelsUsed sums the runs and minutes of audio per model, most minutes first
+func modelsUsed(records []UsageRecord) []modelUsage {
+	byModel := map[string]*modelUsage{}
+	var models []*modelUsage
+	for _, record := range records {
+		model, ok := byModel[record.Model]
+		if !ok {
+			model = &modelUsage{Model: record.Model}
+			byModel[record.Model] = model
+			models = append(models, model)
+		}
+		model.Runs++
+		model.Minutes += record.AudioSeconds / 60
+	}
+
+	usage := make([]modelUsage, len(models))
+	for i, model := range models {
+		usage[i] = *model
+	}
+	sort.SliceStable(usage, func(i, j int) bool { return usage[i].Minutes > usage[j].Minutes })
+	return usage
+}
+
+// usageBar draws value as a bar relative to the largest value
+func usageBar(value, largest float64, width int) string {
+	if largest <= 0 {
+		return ""
+	}
+	return strings.Repeat("█", int(value/largest*float64(width)+0.5))
+}
+
+// setUsageLog turns recording usage statistics on or off in the config file
+func setUsageLog(enabled bool) {
+	config, err := loadConfig()
+	if err != nil {
+		uiPrintf(tr(" Error loading config: %v\n"), err)
+		os.Exit(1)
+	}
+	config.UsageLog = enabled
+	if err := saveConfig(config); err != nil {
+		uiPrintf(tr("❌ Error saving config: %v\n"), err)
+		os.Exit(1)
+	}
+	if enabled {
+		uiPrintln(tr("✅ Usage statistics are recorded from now on. They stay on this machine."))
+	} else {
+		uiPrintln(tr("✅ Usage statistics are no longer recorded."))
+	}
+}
+
+// printUsageDashboard prints the weekly minutes, the models used and the
+// correction rate of the imported corrections
+func printUsageDashboard(records []UsageRecord, corrections []CorrectionRecord, weeks int) {
+	uiPrintln(tr("\n  Minutes transcribed per week:"))
+	usage := weeklyMinutes(records, time.Now(), weeks)
+	largest := 0.0
+	for _, week := range usage {
+		largest = max(largest, week.Minutes)
+	}
+	for _, week := range usage {
+		if accessibleOutput || ciOutput {
+			uiPrintf("  %s  %7.1f\n", week.Label, week.Minutes)
+		} else {
+			uiPrintf("  %s  %7.1f  %s\n", week.Label, week.Minutes, usageBar(week.Minutes, largest, usageBarWidth))
+		}
+	}
+
+	uiPrintln(tr("\n  Models:"))
+	total := 0.0
+	for _, record := range records {
+		total += record.AudioSeconds / 60
+	}
+	for _, model := range modelsUsed(records) {
+		share := 0.0
+		if total > 0 {
+			share = model.Minutes / total * 100
+		}
+		uiPrintf(tr("  %-24s %4.0f%%  %d runs, %.1f minutes\n"), model.Model, share, model.Runs, model.Minutes)
+	}
+
+	uiPrintln(tr("\n  Corrections:"))
+	if len(corrections) == 0 {
+		uiPrintln(tr("  none imported yet (pindar import-corrections --record)"))
+		return
+	}
+	words, edits := 0, 0
+	for _, record := range corrections {
+		words += record.Words
+		edits += record.Edits
+	}
+	uiPrintf(tr("  %.1f%% average correction rate over %d transcripts\n"), correctionRate(edits, words), len(corrections))
+}
+
+// runUsage shows the statistics of the transcriptions on this machine, which
+// are only recorded after opting in with --enable
+func runUsage(argv []string) {
+	var args UsageArgs
+	parser := parseSubcommand("usage", &args, argv)
+	switch {
+	case args.Enable && args.Disable:
+		parser.Fail(tr("pass either --enable or --disable, not both"))
+	case args.Enable || args.Disable:
+		setUsageLog(args.Enable)
+		return
+	case args.Weeks < 1:
+		parser.Fail(tr("--weeks must be at least 1"))
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		uiPrintf(tr(" Error loading config: %v\n"), err)
+		os.Exit(1)
+	}
+	logPath, err := usageLogPath()
+	var records []UsageRecord
+	if err == nil {
+		records, err = loadUsage(logPath)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		uiPrintf(tr("❌ Error reading usage log: %v\n"), err)
+		os.Exit(1)
+	}
+	if len(records) == 0 {
+		if config.UsageLog {
+			uiPrintln(tr("No transcriptions recorded yet."))
+		} else {
+			uiPrintln(tr("Usage statistics are off. Turn them on with: pindar usage --enable"))
+		}
+		return
+	}
+
+	var corrections []CorrectionRecord
+	if path, err := correctionsLogPath(); err == nil {
+		corrections, _ = loadCorrections(path)
+	}
+	printUsageDashboard(records, corrections, args.Weeks)
+	if !config.UsageLog {
+		uiPrintln(tr("\n⚠️  Usage statistics are off, the numbers end where they were turned off"))
+	}
+}
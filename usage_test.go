@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWeeklyMinutes(t *testing.T) {
+	// Wednesday of week 20 of 2024
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	records := []UsageRecord{
+		{At: time.Date(2024, 5, 13, 8, 0, 0, 0, time.UTC), AudioSeconds: 600}, // Monday, this week
+		{At: time.Date(2024, 5, 15, 9, 0, 0, 0, time.UTC), AudioSeconds: 300},
+		{At: time.Date(2024, 5, 12, 23, 0, 0, 0, time.UTC), AudioSeconds: 1200}, // Sunday, last week
+		{At: time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC), AudioSeconds: 6000},  // too long ago
+	}
+
+	usage := weeklyMinutes(records, now, 3)
+	expected := []weekUsage{{Label: "2024-W18"}, {Label: "2024-W19", Minutes: 20}, {Label: "2024-W20", Minutes: 15}}
+	if len(usage) != len(expected) {
+		t.Fatalf("Expected %d weeks, got %+v", len(expected), usage)
+	}
+	for i := range expected {
+		if usage[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], usage[i])
+		}
+	}
+}
+
+func TestModelsUsed(t *testing.T) {
+	records := []UsageRecord{
+		{Model: "whisper-1", AudioSeconds: 60},
+		{Model: "gpt-4o-transcribe", AudioSeconds: 600},
+		{Model: "whisper-1", AudioSeconds: 120},
+	}
+	models := modelsUsed(records)
+	if len(models) != 2 || models[0].Model != "gpt-4o-transcribe" || models[1].Runs != 2 || models[1].Minutes != 3 {
+		t.Errorf("Expected the models by minutes, got %+v", models)
+	}
+}
+
+func TestUsageBar(t *testing.T) {
+	if bar := usageBar(5, 10, 30); bar != "███████████████" {
+		t.Errorf("Expected half a bar, got %q", bar)
+	}
+	if bar := usageBar(0, 0, 30); bar != "" {
+		t.Errorf("Expected no bar without usage, got %q", bar)
+	}
+}
+
+func TestLoadUsage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	content := `{"at":"2024-05-13T08:00:00Z","provider":"openai","model":"whisper-1","audio_seconds":61.5}` + "\n\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	records, err := loadUsage(path)
+	if err != nil || len(records) != 1 || records[0].AudioSeconds != 61.5 {
+		t.Errorf("Expected one record, got %+v (%v)", records, err)
+	}
+
+	if err := os.WriteFile(path, []byte("not json\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadUsage(path); err == nil {
+		t.Error("Expected an error for an invalid record")
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// largeVideoBytes is the size from which a video is only transcribed after
+// confirmation, since uploading or converting it by accident takes long
+const largeVideoBytes = 1 << 30
+
+// videoExtensions are the containers taken as video when ffprobe can't tell
+var videoExtensions = map[string]bool{
+	"avi": true, "m2ts": true, "m4v": true, "mkv": true, "mov": true,
+	"mp4": true, "mpeg": true, "mts": true, "webm": true, "wmv": true,
+}
+
+// isVideo reports whether a file has a video stream, going by the extension
+// if ffprobe can't read it
+func isVideo(path string) bool {
+	if probe, err := probeAudio(path); err == nil {
+		return len(probe.streamsOfType("video")) > 0
+	}
+	return videoExtensions[getFileExtension(path)]
+}
+
+// largeVideoSize returns the size of a video of at least largeVideoBytes, or 0
+// for smaller files and audio
+func largeVideoSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() < largeVideoBytes || !isVideo(path) {
+		return 0
+	}
+	return info.Size()
+}
+
+// formatSize formats a file size in MB, or GB from 1 GB on
+func formatSize(bytes int64) string {
+	if bytes >= 1e9 {
+		return fmt.Sprintf("%.1f GB", float64(bytes)/1e9)
+	}
+	return fmt.Sprintf("%.1f MB", float64(bytes)/1e6)
+}
+
+// answerIsYes reports whether the answer to a yes/no question is yes, in
+// English or German
+func answerIsYes(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "j", "ja":
+		return true
+	}
+	return false
+}
+
+// confirmLargeVideo asks before transcribing a large video. --yes answers
+// for the user, and is required when there is no terminal to ask on.
+func confirmLargeVideo(path string, size int64, yes bool) error {
+	if yes {
+		return nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf(tr("%s is a %s video, pass --yes to transcribe it"), filepath.Base(path), formatSize(size))
+	}
+
+	uiPrintf(tr(" %s is a %s video. Extract its audio and transcribe it? [y/N] "), filepath.Base(path), formatSize(size))
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read answer: %w", err)
+	}
+	if !answerIsYes(answer) {
+		return errors.New(tr("transcription cancelled"))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		48_300_000:    "48.3 MB",
+		999_999_999:   "1000.0 MB",
+		3_200_000_000: "3.2 GB",
+	}
+	for bytes, expected := range tests {
+		if got := formatSize(bytes); got != expected {
+			t.Errorf("formatSize(%d) = %s, expected %s", bytes, got, expected)
+		}
+	}
+}
+
+func TestAnswerIsYes(t *testing.T) {
+	for _, answer := range []string{"y\n", "Yes", " j ", "JA\r\n"} {
+		if !answerIsYes(answer) {
+			t.Errorf("Expected %q to be yes", answer)
+		}
+	}
+	for _, answer := range []string{"", "\n", "n", "no", "yep"} {
+		if answerIsYes(answer) {
+			t.Errorf("Expected %q not to be yes", answer)
+		}
+	}
+}
+
+func TestLargeVideoSize(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "clip.mov")
+	if err := os.WriteFile(small, []byte("not really video"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if size := largeVideoSize(small); size != 0 {
+		t.Errorf("Expected a small video to pass, got %d", size)
+	}
+
+	// A sparse file is large without using the disk space
+	for name, expected := range map[string]int64{"film.mkv": largeVideoBytes, "concert.wav": 0} {
+		path := filepath.Join(dir, name)
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Truncate(largeVideoBytes); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		if size := largeVideoSize(path); size != expected {
+			t.Errorf("%s: expected %d, got %d", name, expected, size)
+		}
+	}
+}